@@ -310,3 +310,9 @@ func TestColorMul(t *testing.T) {
 
 	assert.Equal(t, Colour{100, 200, 0}, *c3)
 }
+
+func TestColourToFloatColourRoundTrip(t *testing.T) {
+	c := Colour{255, 128, 3}
+
+	assert.Equal(t, c, RayColorToCanvasColor(c.ToColour()))
+}
@@ -1,8 +1,7 @@
 package canvas
 
 import (
-	"math"
-	"rattata/rays"
+	"rattata/colour"
 )
 
 type ColourMode uint8
@@ -62,6 +61,9 @@ func (c1 *Colour) Mul(k uint8) *Colour {
 	return &c3
 }
 
+/*
+Deprecated: multiplying bytes saturates almost immediately, blend colours with colour.Colour.Hadamard before converting them
+*/
 func (c1 *Colour) Blend(c2 *Colour) *Colour {
 	c3 := NewColour()
 
@@ -72,9 +74,10 @@ func (c1 *Colour) Blend(c2 *Colour) *Colour {
 	return &c3
 }
 
-func RayColorToCanvasColor(input rays.Colour) Colour {
-	return Colour{
-		uint8(math.Min(255, max(0, input[0]*255))),
-		uint8(math.Min(255, max(0, input[1]*255))),
-		uint8(math.Min(255, max(0, input[2]*255)))}
+func RayColorToCanvasColor(input colour.Colour) Colour {
+	return Colour(input.ToRGB8())
+}
+
+func (c Colour) ToColour() colour.Colour {
+	return colour.FromRGB8(c[Red], c[Green], c[Blue])
 }
@@ -0,0 +1,68 @@
+package canvas
+
+import (
+	"fmt"
+	"math"
+)
+
+// ---------------------------------- Comparing canvases ----------------------------------
+
+/*
+How far a canvas is from the one it was expected to match, channels measured on the 0-255 scale
+*/
+type CanvasDiff struct {
+	// Largest difference of any channel of any pixel
+	MaxError uint8
+	// Pixels with at least one channel further off than the tolerance
+	Mismatched int
+	// Peak signal-to-noise ratio in dB over all channels, +Inf when the canvases are identical
+	PSNR float64
+	// The expected canvas dimmed to a quarter, with the mismatched pixels painted red
+	Diff Canvas
+}
+
+func (d CanvasDiff) String() string {
+	return fmt.Sprintf("%d mismatched pixels, max error %d, PSNR %.2f dB", d.Mismatched, d.MaxError, d.PSNR)
+}
+
+/*
+Compares actual against expected pixel by pixel, a channel may be off by up to tolerance before its pixel counts as mismatched.
+Both canvases must have the same size.
+*/
+func Compare(expected, actual Canvas, tolerance uint8) (CanvasDiff, error) {
+	if expected.Bounds().Size() != actual.Bounds().Size() {
+		return CanvasDiff{}, fmt.Errorf("cannot compare a %dx%d canvas against a %dx%d one",
+			actual.Bounds().Dx(), actual.Bounds().Dy(), expected.Bounds().Dx(), expected.Bounds().Dy())
+	}
+
+	res := CanvasDiff{Diff: CreateCanvas(uint32(expected.Bounds().Dx()), uint32(expected.Bounds().Dy()))}
+	squared_error := 0.0
+
+	for y := range expected {
+		for x := range expected[y] {
+			want, got := expected[y][x].Colour, actual[y][x].Colour
+			is_mismatch := false
+
+			for ch := range want {
+				diff := uint8(max(int(want[ch])-int(got[ch]), int(got[ch])-int(want[ch])))
+				res.MaxError = max(res.MaxError, diff)
+				squared_error += float64(diff) * float64(diff)
+				is_mismatch = is_mismatch || diff > tolerance
+			}
+
+			if is_mismatch {
+				res.Mismatched++
+				res.Diff[y][x].Colour = Colour{255, 0, 0}
+			} else {
+				res.Diff[y][x].Colour = Colour{want[Red] / 4, want[Green] / 4, want[Blue] / 4}
+			}
+		}
+	}
+
+	res.PSNR = math.Inf(1)
+	if channels := 3 * expected.Bounds().Dx() * expected.Bounds().Dy(); squared_error > 0 {
+		mse := squared_error / float64(channels)
+		res.PSNR = 10 * math.Log10(255*255/mse)
+	}
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package canvas
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareIdenticalCanvases(t *testing.T) {
+	c := CreateCanvas(3, 2)
+	c.WritePixel(1, 1, Colour{200, 100, 40})
+
+	diff, err := Compare(c, c, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, diff.Mismatched)
+	assert.Equal(t, uint8(0), diff.MaxError)
+	assert.True(t, math.IsInf(diff.PSNR, 1))
+	assert.Equal(t, Colour{50, 25, 10}, diff.Diff.ReadPixel(1, 1).Colour)
+}
+
+func TestCompareWithTolerance(t *testing.T) {
+	expected, actual := CreateCanvas(2, 2), CreateCanvas(2, 2)
+	expected.WritePixel(0, 0, Colour{100, 100, 100})
+	actual.WritePixel(0, 0, Colour{102, 99, 100})
+	expected.WritePixel(1, 1, Colour{0, 0, 250})
+	actual.WritePixel(1, 1, Colour{0, 0, 10})
+
+	diff, err := Compare(expected, actual, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, diff.Mismatched)
+	assert.Equal(t, uint8(240), diff.MaxError)
+	// 4 + 1 + 240^2 over 12 channels
+	assert.InDelta(t, 10*math.Log10(255*255/(57605.0/12)), diff.PSNR, 1e-9)
+	assert.Equal(t, Colour{25, 25, 25}, diff.Diff.ReadPixel(0, 0).Colour)
+	assert.Equal(t, Colour{255, 0, 0}, diff.Diff.ReadPixel(1, 1).Colour)
+	assert.Equal(t, "1 mismatched pixels, max error 240, PSNR 11.32 dB", diff.String())
+
+	diff, _ = Compare(expected, actual, 240)
+	assert.Equal(t, 0, diff.Mismatched)
+}
+
+func TestCompareDifferentSizes(t *testing.T) {
+	_, err := Compare(CreateCanvas(2, 2), CreateCanvas(3, 2), 0)
+	assert.EqualError(t, err, "cannot compare a 3x2 canvas against a 2x2 one")
+}
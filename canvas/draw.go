@@ -1,9 +1,9 @@
 package canvas
 
 import (
-	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 const PPM_LINE_LIM = 70
@@ -48,65 +48,14 @@ func (c Canvas) ReadPixel(colIdx, rowIdx uint32) Pixel {
 /*
 Notice how the first row of pixels comes first, then the second row, and so forth. Further, each row is terminated by a new line.
 In addition, no line in a PPM file should be more than 70 characters long. Most image programs tend to accept PPM images with lines longer than that, but it’s a good idea to add new lines as needed to keep the lines shorter. (Just be careful to put the new line where a space would have gone, so you don’t split a number in half!
+
+Builds the whole file in memory, prefer WritePPM for anything but small canvases.
 */
 func CanvasToPPMData(myCanvas Canvas) string {
-	ppmData := ""
-	ppmData = AppendWithLine(ppmData, "P3")
-	ppmData = AppendWithLine(ppmData, fmt.Sprintf("%d %d", myCanvas.GetWidth(), myCanvas.GetHeight()))
-	ppmData = AppendWithLine(ppmData, "255")
-
-	line_data := ""
-	cur_len := 0
-	w, h := myCanvas.GetWidth(), myCanvas.GetHeight()
-
-	for _rowIdx := 0; _rowIdx < h; _rowIdx++ {
-		for _colIdx := 0; _colIdx < w; _colIdx++ {
-			r, g, b := fmt.Sprint(myCanvas[_rowIdx][_colIdx].Colour.GetValue(Red)),
-				fmt.Sprint(myCanvas[_rowIdx][_colIdx].Colour.GetValue(Green)),
-				fmt.Sprint(myCanvas[_rowIdx][_colIdx].Colour.GetValue(Blue))
-
-			{
-				if cur_len+len(r)+1 >= PPM_LINE_LIM {
-					ppmData = AppendWithLine(ppmData, line_data)
-					line_data = ""
-					cur_len = 0
-				}
-
-				line_data = Append(line_data, r, " ")
-				cur_len += len(r) + 1
-			}
-
-			{
-				if cur_len+len(g)+1 >= PPM_LINE_LIM {
-					ppmData = AppendWithLine(ppmData, line_data)
-					line_data = ""
-					cur_len = 0
-				}
-
-				line_data = Append(line_data, g, " ")
-				cur_len += len(b) + 1
-			}
-
-			{
-				if cur_len+len(b)+1 >= PPM_LINE_LIM {
-					ppmData = AppendWithLine(ppmData, line_data)
-					line_data = ""
-					cur_len = 0
-				}
-
-				line_data = Append(line_data, b, " ")
-				cur_len += len(r) + 1
-			}
-		}
-	}
+	ppmData := strings.Builder{}
+	WritePPM(&ppmData, myCanvas, PPMOptions{Format: P3})
 
-	if cur_len > 0 {
-		ppmData = AppendWithLine(ppmData, line_data)
-		line_data = ""
-		cur_len = 0
-	}
-
-	return ppmData
+	return ppmData.String()
 }
 
 func AppendWithLine(src string, datum string) string {
@@ -117,6 +66,9 @@ func Append(src, datum, suffix string) string {
 	return src + datum + suffix
 }
 
+/*
+Deprecated: failures are only logged, use Canvas.Save instead
+*/
 func SaveToPath(fileName, ppmData string) {
 	file, err := os.Create(fileName + ".ppm")
 	if err != nil {
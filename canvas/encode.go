@@ -0,0 +1,120 @@
+package canvas
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ---------------------------------- Encoders ----------------------------------
+
+func EncodePNG(w io.Writer, c Canvas) error {
+	return png.Encode(w, c)
+}
+
+func EncodeP6(w io.Writer, c Canvas) error {
+	return WritePPM(w, c, PPMOptions{Format: P6})
+}
+
+const (
+	bmpFileHeaderSize = 14
+	bmpInfoHeaderSize = 40
+)
+
+/*
+Uncompressed 24 bit BMP. Rows are stored bottom up in BGR order, each padded to a multiple of 4 bytes
+*/
+func EncodeBMP(w io.Writer, c Canvas) error {
+	width, height := c.GetWidth(), c.GetHeight()
+	row_size := (3*width + 3) &^ 3
+	image_size := row_size * height
+
+	header := struct {
+		// file header
+		Signature  [2]byte
+		FileSize   uint32
+		Reserved   uint32
+		DataOffset uint32
+		// BITMAPINFOHEADER
+		InfoSize         uint32
+		Width            int32
+		Height           int32
+		Planes           uint16
+		BitsPerPixel     uint16
+		Compression      uint32
+		ImageSize        uint32
+		XPixelsPerMeter  int32
+		YPixelsPerMeter  int32
+		ColoursUsed      uint32
+		ColoursImportant uint32
+	}{
+		Signature:       [2]byte{'B', 'M'},
+		FileSize:        uint32(bmpFileHeaderSize + bmpInfoHeaderSize + image_size),
+		DataOffset:      bmpFileHeaderSize + bmpInfoHeaderSize,
+		InfoSize:        bmpInfoHeaderSize,
+		Width:           int32(width),
+		Height:          int32(height),
+		Planes:          1,
+		BitsPerPixel:    24,
+		ImageSize:       uint32(image_size),
+		XPixelsPerMeter: 2835, // 72 DPI
+		YPixelsPerMeter: 2835,
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	row := make([]byte, row_size)
+	for y := height - 1; y >= 0; y-- {
+		for x, pix := range c[y] {
+			row[3*x], row[3*x+1], row[3*x+2] = pix.Colour[Blue], pix.Colour[Green], pix.Colour[Red]
+		}
+		if _, err := bw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ---------------------------------- Saving ----------------------------------
+var encoders = map[string]func(io.Writer, Canvas) error{
+	".png": EncodePNG,
+	".ppm": EncodeP6,
+	".bmp": EncodeBMP,
+}
+
+// Whether Save knows the format of path
+func CanSave(path string) bool {
+	_, isKnown := encoders[strings.ToLower(filepath.Ext(path))]
+	return isKnown
+}
+
+/*
+Writes the canvas to path, picking the format from the file extension: .png, .ppm (binary P6) or .bmp
+*/
+func (c Canvas) Save(path string) error {
+	encode, isKnown := encoders[strings.ToLower(filepath.Ext(path))]
+	if !isKnown {
+		return fmt.Errorf("cannot save %q: unsupported image format %q", path, filepath.Ext(path))
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = encode(file, c); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot save %q: %w", path, err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,84 @@
+package canvas
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleCanvas() Canvas {
+	c := CreateCanvas(3, 2)
+	c.WritePixel(0, 0, Colour{255, 0, 0})
+	c.WritePixel(1, 0, Colour{0, 255, 0})
+	c.WritePixel(2, 1, Colour{1, 2, 3})
+	return c
+}
+
+func TestEncodePNGDecodesBack(t *testing.T) {
+	c := sampleCanvas()
+	buf := bytes.Buffer{}
+
+	assert.Nil(t, EncodePNG(&buf, c))
+
+	img, err := png.Decode(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, c, FromImage(img))
+}
+
+func TestEncodeP6(t *testing.T) {
+	buf := bytes.Buffer{}
+	assert.Nil(t, EncodeP6(&buf, sampleCanvas()))
+
+	expected := append([]byte("P6\n3 2\n255\n"),
+		255, 0, 0, 0, 255, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 1, 2, 3)
+	assert.Equal(t, expected, buf.Bytes())
+}
+
+func TestEncodeBMP(t *testing.T) {
+	buf := bytes.Buffer{}
+	assert.Nil(t, EncodeBMP(&buf, sampleCanvas()))
+
+	data := buf.Bytes()
+	// 3 pixels take 9 bytes, padded to 12 per row
+	assert.Equal(t, 14+40+2*12, len(data))
+	assert.Equal(t, []byte("BM"), data[0:2])
+	assert.Equal(t, []byte{byte(len(data)), 0, 0, 0}, data[2:6])
+	assert.Equal(t, []byte{54, 0, 0, 0}, data[10:14])
+	assert.Equal(t, []byte{3, 0, 0, 0, 2, 0, 0, 0}, data[18:26])
+	assert.Equal(t, []byte{24, 0}, data[28:30])
+
+	// bottom row first, BGR
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 3, 2, 1, 0, 0, 0}, data[54:66])
+	assert.Equal(t, []byte{0, 0, 255, 0, 255, 0, 0, 0, 0, 0, 0, 0}, data[66:78])
+}
+
+func TestSavePicksFormatFromExtension(t *testing.T) {
+	dir := t.TempDir()
+	c := sampleCanvas()
+
+	for ext, magic := range map[string]string{".png": "\x89PNG", ".ppm": "P6", ".PPM": "P6", ".bmp": "BM"} {
+		path := filepath.Join(dir, "out"+ext)
+		assert.Nil(t, c.Save(path))
+
+		data, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, magic, string(data[:len(magic)]), ext)
+	}
+}
+
+func TestSaveErrors(t *testing.T) {
+	dir := t.TempDir()
+	c := sampleCanvas()
+
+	assert.False(t, CanSave("out.gif"))
+	assert.True(t, CanSave("out.PNG"))
+	assert.ErrorContains(t, c.Save(filepath.Join(dir, "out.gif")), "unsupported image format")
+	assert.NoFileExists(t, filepath.Join(dir, "out.gif"))
+
+	assert.Error(t, c.Save(filepath.Join(dir, "missing", "out.png")))
+}
@@ -0,0 +1,151 @@
+package canvas
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"rattata/colour"
+)
+
+// ---------------------------------- HDR Canvas ----------------------------------
+
+/*
+Keeps the renderer's float colours as they are, values above 1 included,
+so that exposure and tone mapping can be picked after rendering. See ToneMap to turn it into a Canvas.
+*/
+type HDRCanvas [][]colour.Colour
+
+func CreateHDRCanvas(w, h uint32) HDRCanvas {
+	grids := make([][]colour.Colour, h)
+
+	for i := uint32(0); i < h; i++ {
+		grids[i] = make([]colour.Colour, w)
+	}
+
+	return HDRCanvas(grids)
+}
+
+func (c HDRCanvas) GetHeight() int {
+	return len(c)
+}
+
+func (c HDRCanvas) GetWidth() int {
+	return len(c[0])
+}
+
+func (c HDRCanvas) WritePixel(colIdx, rowIdx uint32, col colour.Colour) {
+	c[rowIdx][colIdx] = col
+}
+
+func (c HDRCanvas) ReadPixel(colIdx, rowIdx uint32) colour.Colour {
+	return c[rowIdx][colIdx]
+}
+
+// ---------------------------------- Tone Mapping ----------------------------------
+
+/*
+Squeezes a linear HDR colour into the displayable 0-1 range
+*/
+type ToneMapper func(colour.Colour) colour.Colour
+
+// Cuts every channel off at 1, which is what RayColorToCanvasColor has always done
+func ClampToneMap(c colour.Colour) colour.Colour {
+	return c.Clamp()
+}
+
+// x / (1 + x) per channel: never quite reaches white, but keeps detail in the highlights
+func ReinhardToneMap(c colour.Colour) colour.Colour {
+	return perChannel(c, func(x float64) float64 {
+		x = math.Max(0, x)
+		return x / (1 + x)
+	})
+}
+
+// Krzysztof Narkowicz's fit of the ACES filmic curve, a little more contrast than Reinhard with a soft shoulder
+func ACESToneMap(c colour.Colour) colour.Colour {
+	return perChannel(c, func(x float64) float64 {
+		x = math.Max(0, x)
+		return math.Min(1, (x*(2.51*x+0.03))/(x*(2.43*x+0.59)+0.14))
+	})
+}
+
+func perChannel(c colour.Colour, f func(float64) float64) colour.Colour {
+	return colour.New(f(c[colour.R]), f(c[colour.G]), f(c[colour.B]))
+}
+
+/*
+Exposure is in stops, every stop doubling the light before the tone mapper sees it.
+SRGB encodes the result with the sRGB transfer curve, which is what image viewers expect from 8 bit files.
+The zero value clamps and nothing else, giving the same output as RayColorToCanvasColor.
+*/
+type ToneMapOptions struct {
+	Operator ToneMapper // nil picks ClampToneMap
+	Exposure float64
+	SRGB     bool
+}
+
+func (c HDRCanvas) ToneMap(opts ToneMapOptions) Canvas {
+	operator := opts.Operator
+	if operator == nil {
+		operator = ClampToneMap
+	}
+	exposure := math.Exp2(opts.Exposure)
+
+	my_canvas := CreateCanvas(uint32(c.GetWidth()), uint32(c.GetHeight()))
+
+	for y, row := range c {
+		for x, col := range row {
+			if opts.Exposure != 0 {
+				col = col.Scale(exposure)
+			}
+			col = operator(col)
+			if opts.SRGB {
+				col = perChannel(col, SRGBEncode)
+			}
+			my_canvas.WritePixel(uint32(x), uint32(y), RayColorToCanvasColor(col))
+		}
+	}
+
+	return my_canvas
+}
+
+/*
+The sRGB transfer function, for linear values in 0-1
+*/
+func SRGBEncode(x float64) float64 {
+	if x <= 0.0031308 {
+		return 12.92 * x
+	}
+	return 1.055*math.Pow(x, 1/2.4) - 0.055
+}
+
+// ---------------------------------- PFM ----------------------------------
+
+/*
+Writes the raw colours as a colour PFM: a "PF" header, the size, a negative scale marking little endian data,
+then 3 float32 per pixel with the bottom row first.
+*/
+func WritePFM(w io.Writer, c HDRCanvas) error {
+	bw := bufio.NewWriter(w)
+
+	if _, err := fmt.Fprintf(bw, "PF\n%d %d\n-1.0\n", c.GetWidth(), c.GetHeight()); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, 12*c.GetWidth())
+	for y := c.GetHeight() - 1; y >= 0; y-- {
+		buf = buf[:0]
+		for _, col := range c[y] {
+			for _, v := range col {
+				buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v)))
+			}
+		}
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
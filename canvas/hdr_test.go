@@ -0,0 +1,89 @@
+package canvas
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"rattata/helpers"
+	"rattata/rays"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClampToneMapMatchesRayColorToCanvasColor(t *testing.T) {
+	h := CreateHDRCanvas(4, 1)
+	cols := []rays.Colour{{0, 0.5, 1}, {1.5, -0.2, 0.999}, {0.1, 0.2, 0.3}, {30, 0.004, 0.7}}
+	for x, col := range cols {
+		h.WritePixel(uint32(x), 0, col)
+	}
+
+	c := h.ToneMap(ToneMapOptions{})
+	for x, col := range cols {
+		assert.Equal(t, RayColorToCanvasColor(col), c.ReadPixel(uint32(x), 0).Colour)
+	}
+}
+
+func TestToneMapOperators(t *testing.T) {
+	helpers.ApproxEqual(t, 0.5, ReinhardToneMap(rays.Colour{1, 1, 1})[0], 1e-9)
+	helpers.ApproxEqual(t, 0.75, ReinhardToneMap(rays.Colour{3, 3, 3})[1], 1e-9)
+	assert.Equal(t, 0.0, ReinhardToneMap(rays.Colour{-1, 0, 0})[0])
+
+	assert.Equal(t, 0.0, ACESToneMap(rays.Colour{0, 0, 0})[0])
+	helpers.ApproxEqual(t, 0.80380, ACESToneMap(rays.Colour{1, 1, 1})[2], 1e-4)
+	assert.Equal(t, 1.0, ACESToneMap(rays.Colour{1000, 0, 0})[0])
+
+	// every operator is monotonic and stays within 0-1
+	for _, op := range []ToneMapper{ClampToneMap, ReinhardToneMap, ACESToneMap} {
+		prev := -1.0
+		for x := 0.0; x < 20; x += 0.25 {
+			v := op(rays.Colour{x, x, x})[0]
+			assert.GreaterOrEqual(t, v, prev)
+			assert.LessOrEqual(t, v, 1.0)
+			prev = v
+		}
+	}
+}
+
+func TestToneMapExposureAndSRGB(t *testing.T) {
+	h := CreateHDRCanvas(1, 1)
+	h.WritePixel(0, 0, rays.Colour{0.25, 0.5, 2})
+
+	brighter := h.ToneMap(ToneMapOptions{Exposure: 1})
+	assert.Equal(t, Colour{127, 255, 255}, brighter.ReadPixel(0, 0).Colour)
+
+	darker := h.ToneMap(ToneMapOptions{Exposure: -2, Operator: ReinhardToneMap})
+	assert.Equal(t, RayColorToCanvasColor(ReinhardToneMap(rays.Colour{0.0625, 0.125, 0.5})), darker.ReadPixel(0, 0).Colour)
+
+	// linear 0.5 is encoded as roughly 0.735 in sRGB
+	srgb := h.ToneMap(ToneMapOptions{SRGB: true})
+	assert.Equal(t, uint8(187), srgb.ReadPixel(0, 0).Colour[Green])
+}
+
+func TestSRGBEncode(t *testing.T) {
+	assert.Equal(t, 0.0, SRGBEncode(0))
+	helpers.ApproxEqual(t, 1.0, SRGBEncode(1), 1e-9)
+	helpers.ApproxEqual(t, 0.02584, SRGBEncode(0.002), 1e-5)
+	helpers.ApproxEqual(t, 0.21360, SRGBEncode(0.0375), 1e-4)
+}
+
+func TestWritePFM(t *testing.T) {
+	h := CreateHDRCanvas(2, 2)
+	h.WritePixel(0, 0, rays.Colour{1, 2, 3})
+	h.WritePixel(1, 1, rays.Colour{0.5, 100, -1})
+
+	buf := bytes.Buffer{}
+	assert.Nil(t, WritePFM(&buf, h))
+
+	header := "PF\n2 2\n-1.0\n"
+	data := buf.Bytes()
+	assert.Equal(t, header, string(data[:len(header)]))
+	assert.Equal(t, len(header)+2*2*3*4, len(data))
+
+	floats := make([]float32, 12)
+	assert.Nil(t, binary.Read(bytes.NewReader(data[len(header):]), binary.LittleEndian, floats))
+
+	// bottom row first
+	assert.Equal(t, []float32{0, 0, 0, 0.5, 100, -1, 1, 2, 3, 0, 0, 0}, floats)
+	assert.False(t, math.IsNaN(float64(floats[0])))
+}
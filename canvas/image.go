@@ -0,0 +1,48 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+)
+
+// ---------------------------------- image.Image ----------------------------------
+// Canvas is an image.Image, so anything from the standard image packages can consume it directly
+
+func (c Canvas) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (c Canvas) Bounds() image.Rectangle {
+	if c.GetHeight() == 0 {
+		return image.Rectangle{}
+	}
+	return image.Rect(0, 0, c.GetWidth(), c.GetHeight())
+}
+
+// Pixels are always opaque; points outside the canvas are transparent black, as image.Image asks for
+func (c Canvas) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(c.Bounds())) {
+		return color.RGBA{}
+	}
+
+	col := c[y][x].Colour
+	return color.RGBA{R: col[Red], G: col[Green], B: col[Blue], A: 255}
+}
+
+/*
+Copies any image into a new canvas, its top left corner landing on (0, 0).
+Canvases have no alpha, translucent pixels end up as if drawn over black.
+*/
+func FromImage(img image.Image) Canvas {
+	b := img.Bounds()
+	my_canvas := CreateCanvas(uint32(b.Dx()), uint32(b.Dy()))
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, b_, _ := img.At(x, y).RGBA()
+			my_canvas.WritePixel(uint32(x-b.Min.X), uint32(y-b.Min.Y), Colour{uint8(r >> 8), uint8(g >> 8), uint8(b_ >> 8)})
+		}
+	}
+
+	return my_canvas
+}
@@ -0,0 +1,43 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanvasIsAnImage(t *testing.T) {
+	c := CreateCanvas(3, 2)
+	c.WritePixel(2, 1, Colour{10, 20, 30})
+
+	var img image.Image = c
+
+	assert.Equal(t, image.Rect(0, 0, 3, 2), img.Bounds())
+	assert.Equal(t, color.RGBA{10, 20, 30, 255}, img.At(2, 1))
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, img.At(0, 0))
+	assert.Equal(t, color.RGBA{}, img.At(3, 1))
+	assert.Equal(t, color.RGBA{}, img.At(-1, 0))
+}
+
+func TestFromImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(5, 5, 8, 7))
+	img.Set(7, 6, color.NRGBA{200, 100, 50, 255})
+	img.Set(5, 5, color.NRGBA{200, 100, 50, 0})
+
+	c := FromImage(img)
+
+	assert.Equal(t, 3, c.GetWidth())
+	assert.Equal(t, 2, c.GetHeight())
+	assert.Equal(t, Colour{200, 100, 50}, c.ReadPixel(2, 1).Colour)
+	assert.Equal(t, Colour{0, 0, 0}, c.ReadPixel(0, 0).Colour)
+}
+
+func TestFromImageRoundTrip(t *testing.T) {
+	c := CreateCanvas(4, 3)
+	c.WritePixel(1, 2, Colour{1, 2, 3})
+	c.WritePixel(3, 0, Colour{255, 128, 0})
+
+	assert.Equal(t, c, FromImage(c))
+}
@@ -0,0 +1,261 @@
+package canvas
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ---------------------------------- PPM ----------------------------------
+type PPMFormat uint8
+
+const (
+	P3 PPMFormat = iota // plain text, one decimal value per channel
+	P6                  // binary, one byte per channel
+)
+
+type PPMOptions struct {
+	Format PPMFormat
+}
+
+/*
+Streams the canvas to w as a PPM image with a maxval of 255.
+
+P3 output starts every row of pixels on a new line and wraps lines at spaces so that none exceeds PPM_LINE_LIM characters.
+*/
+func WritePPM(w io.Writer, c Canvas, opts PPMOptions) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch opts.Format {
+	case P3:
+		err = writeP3(bw, c)
+	case P6:
+		err = writeP6(bw, c)
+	default:
+		err = fmt.Errorf("unknown PPM format %d", opts.Format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func writeP3(bw *bufio.Writer, c Canvas) error {
+	if _, err := fmt.Fprintf(bw, "P3\n%d %d\n255\n", c.GetWidth(), c.GetHeight()); err != nil {
+		return err
+	}
+
+	num_buf := make([]byte, 0, 3)
+	for _, row := range c {
+		line_len := 0
+
+		for _, pix := range row {
+			for _, v := range pix.Colour {
+				num_buf = strconv.AppendUint(num_buf[:0], uint64(v), 10)
+
+				if line_len > 0 && line_len+1+len(num_buf) > PPM_LINE_LIM {
+					bw.WriteByte('\n')
+					line_len = 0
+				}
+				if line_len > 0 {
+					bw.WriteByte(' ')
+					line_len++
+				}
+
+				bw.Write(num_buf)
+				line_len += len(num_buf)
+			}
+		}
+
+		// bufio.Writer errors are sticky, checking once per row is enough
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeP6(bw *bufio.Writer, c Canvas) error {
+	if _, err := fmt.Fprintf(bw, "P6\n%d %d\n255\n", c.GetWidth(), c.GetHeight()); err != nil {
+		return err
+	}
+
+	for _, row := range c {
+		for _, pix := range row {
+			if _, err := bw.Write(pix.Colour[:]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var ErrMalformedPPM = errors.New("malformed PPM")
+
+// Largest width*height ReadPPM accepts, a 16384x16384 image
+const PPM_PIXEL_LIM = 1 << 28
+
+/*
+Reads a P3 or P6 image. Comments and any amount of whitespace are accepted between header fields,
+as well as between the values of a P3 raster. Values are rescaled from the file's maxval to 0-255.
+Rows are only allocated once their data has been read, so a header promising a huge image costs nothing by itself.
+*/
+func ReadPPM(r io.Reader) (Canvas, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := readPPMToken(br)
+	if err != nil {
+		return nil, malformedPPM("reading magic number: %w", err)
+	}
+	if magic != "P3" && magic != "P6" {
+		return nil, malformedPPM("unsupported magic number %q, expected P3 or P6", magic)
+	}
+
+	width, err := readPPMHeaderValue(br, "width", 1, 1<<24)
+	if err != nil {
+		return nil, err
+	}
+	height, err := readPPMHeaderValue(br, "height", 1, 1<<24)
+	if err != nil {
+		return nil, err
+	}
+	maxval, err := readPPMHeaderValue(br, "maxval", 1, 65535)
+	if err != nil {
+		return nil, err
+	}
+	if width*height > PPM_PIXEL_LIM {
+		return nil, malformedPPM("%dx%d image exceeds the limit of %d pixels", width, height, PPM_PIXEL_LIM)
+	}
+
+	my_canvas := make(Canvas, 0, min(height, 1024))
+
+	next_value := func() (int, error) {
+		return readP3Value(br, maxval)
+	}
+	if magic == "P6" {
+		next_value = func() (int, error) {
+			return readP6Value(br, maxval)
+		}
+	}
+
+	for y := range height {
+		row := make([]Pixel, width)
+		for x := range width {
+			for i := range row[x].Colour {
+				v, err := next_value()
+				if err != nil {
+					return nil, malformedPPM("pixel (%d, %d) of %dx%d: %w", x, y, width, height, err)
+				}
+				row[x].Colour[i] = uint8((v*255 + maxval/2) / maxval)
+			}
+		}
+		my_canvas = append(my_canvas, row)
+	}
+
+	return my_canvas, nil
+}
+
+func malformedPPM(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrMalformedPPM}, args...)...)
+}
+
+func readPPMHeaderValue(br *bufio.Reader, name string, lowest, highest int) (int, error) {
+	token, err := readPPMToken(br)
+	if err != nil {
+		return 0, malformedPPM("reading %s: %w", name, err)
+	}
+
+	v, err := strconv.Atoi(token)
+	if err != nil || v < lowest || v > highest {
+		return 0, malformedPPM("%s must be a number between %d and %d, got %q", name, lowest, highest, token)
+	}
+	return v, nil
+}
+
+func readP3Value(br *bufio.Reader, maxval int) (int, error) {
+	token, err := readPPMToken(br)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.Atoi(token)
+	if err != nil || v < 0 || v > maxval {
+		return 0, fmt.Errorf("value must be a number between 0 and %d, got %q", maxval, token)
+	}
+	return v, nil
+}
+
+// Samples take one byte, or two big endian bytes when maxval does not fit in one
+func readP6Value(br *bufio.Reader, maxval int) (int, error) {
+	hi, err := br.ReadByte()
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	if maxval < 256 {
+		if int(hi) > maxval {
+			return 0, fmt.Errorf("value %d exceeds maxval %d", hi, maxval)
+		}
+		return int(hi), nil
+	}
+
+	lo, err := br.ReadByte()
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	v := int(hi)<<8 | int(lo)
+	if v > maxval {
+		return 0, fmt.Errorf("value %d exceeds maxval %d", v, maxval)
+	}
+	return v, nil
+}
+
+/*
+Skips whitespace and comments, then returns the next run of non whitespace characters.
+The single whitespace character ending the token is consumed, which is exactly what P6 expects between maxval and the raster.
+*/
+func readPPMToken(br *bufio.Reader) (string, error) {
+	token := make([]byte, 0, 8)
+
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if len(token) > 0 && err == io.EOF {
+				return string(token), nil
+			}
+			return "", unexpectedEOF(err)
+		}
+
+		switch {
+		case b == '#' && len(token) == 0:
+			if _, err := br.ReadBytes('\n'); err != nil {
+				return "", unexpectedEOF(err)
+			}
+		case b == '#':
+			br.UnreadByte()
+			return string(token), nil
+		case isPPMWhitespace(b):
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, b)
+		}
+	}
+}
+
+func isPPMWhitespace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f'
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
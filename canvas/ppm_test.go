@@ -0,0 +1,175 @@
+package canvas
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"rattata/rays"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the canvas from the PPM pixel data example of the book
+func smallPPMCanvas() Canvas {
+	c := CreateCanvas(5, 3)
+	c.WritePixel(0, 0, RayColorToCanvasColor(rays.Colour{1.5, 0, 0}))
+	c.WritePixel(2, 1, RayColorToCanvasColor(rays.Colour{0, 0.5, 0}))
+	c.WritePixel(4, 2, RayColorToCanvasColor(rays.Colour{-0.5, 0, 1}))
+	return c
+}
+
+func assertMatchesReference(t *testing.T, reference string, c Canvas, opts PPMOptions) {
+	expected, err := os.ReadFile("testdata/" + reference)
+	assert.Nil(t, err)
+
+	buf := bytes.Buffer{}
+	assert.Nil(t, WritePPM(&buf, c, opts))
+	assert.Equal(t, expected, buf.Bytes())
+}
+
+func TestWritePPMP3Reference(t *testing.T) {
+	assertMatchesReference(t, "small_p3.ppm", smallPPMCanvas(), PPMOptions{Format: P3})
+}
+
+func TestWritePPMP6Reference(t *testing.T) {
+	assertMatchesReference(t, "small_p6.ppm", smallPPMCanvas(), PPMOptions{Format: P6})
+}
+
+func TestWritePPMSplitsLongLines(t *testing.T) {
+	c := CreateCanvas(10, 2)
+	for y := range 2 {
+		for x := range 10 {
+			c.WritePixel(uint32(x), uint32(y), RayColorToCanvasColor(rays.Colour{1, 0.8, 0.6}))
+		}
+	}
+
+	assertMatchesReference(t, "long_rows_p3.ppm", c, PPMOptions{Format: P3})
+}
+
+func TestWritePPMLineLimitHoldsForAnyData(t *testing.T) {
+	c := CreateCanvas(37, 5)
+	rnd := rand.New(rand.NewSource(42))
+	for y := range 5 {
+		for x := range 37 {
+			c.WritePixel(uint32(x), uint32(y), Colour{uint8(rnd.Intn(256)), uint8(rnd.Intn(256)), uint8(rnd.Intn(256))})
+		}
+	}
+
+	buf := bytes.Buffer{}
+	assert.Nil(t, WritePPM(&buf, c, PPMOptions{}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	values := 0
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), PPM_LINE_LIM)
+		assert.False(t, strings.HasSuffix(line, " "))
+		values += len(strings.Fields(line))
+	}
+	assert.Equal(t, 4+37*5*3, values) // header fields included
+}
+
+func TestWritePPMUnknownFormat(t *testing.T) {
+	assert.Error(t, WritePPM(&bytes.Buffer{}, smallPPMCanvas(), PPMOptions{Format: 9}))
+}
+
+func TestCanvasToPPMDataMatchesWritePPM(t *testing.T) {
+	expected, _ := os.ReadFile("testdata/small_p3.ppm")
+	assert.Equal(t, string(expected), CanvasToPPMData(smallPPMCanvas()))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func TestWritePPMReportsWriteErrors(t *testing.T) {
+	big := CreateCanvas(100, 100)
+
+	assert.ErrorIs(t, WritePPM(failingWriter{}, big, PPMOptions{Format: P3}), os.ErrClosed)
+	assert.ErrorIs(t, WritePPM(failingWriter{}, big, PPMOptions{Format: P6}), os.ErrClosed)
+}
+
+func TestReadPPMReferenceFiles(t *testing.T) {
+	for _, reference := range []string{"small_p3.ppm", "small_p6.ppm"} {
+		file, err := os.Open("testdata/" + reference)
+		assert.Nil(t, err)
+
+		c, err := ReadPPM(file)
+		file.Close()
+
+		assert.Nil(t, err, reference)
+		assert.Equal(t, smallPPMCanvas(), c, reference)
+	}
+}
+
+func TestReadPPMRoundTrip(t *testing.T) {
+	c := CreateCanvas(31, 4)
+	rnd := rand.New(rand.NewSource(7))
+	for y := range 4 {
+		for x := range 31 {
+			c.WritePixel(uint32(x), uint32(y), Colour{uint8(rnd.Intn(256)), uint8(rnd.Intn(256)), uint8(rnd.Intn(256))})
+		}
+	}
+
+	for _, format := range []PPMFormat{P3, P6} {
+		buf := bytes.Buffer{}
+		assert.Nil(t, WritePPM(&buf, c, PPMOptions{Format: format}))
+
+		read, err := ReadPPM(&buf)
+		assert.Nil(t, err)
+		assert.Equal(t, c, read)
+	}
+}
+
+func TestReadPPMCommentsAndWhitespace(t *testing.T) {
+	data := "P3 # plain\n# made by hand\n\t2   1\r\n#maxval next\n255\n1 2\n3\t\t4 5 # trailing comment\n6"
+
+	c, err := ReadPPM(strings.NewReader(data))
+	assert.Nil(t, err)
+	assert.Equal(t, Colour{1, 2, 3}, c.ReadPixel(0, 0).Colour)
+	assert.Equal(t, Colour{4, 5, 6}, c.ReadPixel(1, 0).Colour)
+}
+
+func TestReadPPMRescalesMaxval(t *testing.T) {
+	c, err := ReadPPM(strings.NewReader("P3\n2 1\n15\n0 15 7  8 1 14\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, Colour{0, 255, 119}, c.ReadPixel(0, 0).Colour)
+	assert.Equal(t, Colour{136, 17, 238}, c.ReadPixel(1, 0).Colour)
+
+	// two bytes per sample once maxval needs them
+	sixteen_bit := append([]byte("P6 1 1 # comment\n65535\n"), 0xff, 0xff, 0x80, 0x00, 0x00, 0x00)
+	c, err = ReadPPM(bytes.NewReader(sixteen_bit))
+	assert.Nil(t, err)
+	assert.Equal(t, Colour{255, 128, 0}, c.ReadPixel(0, 0).Colour)
+}
+
+func TestReadPPMErrors(t *testing.T) {
+	cases := map[string]string{
+		"":                             "reading magic number",
+		"P5\n1 1\n255\n":               "unsupported magic number \"P5\"",
+		"P3\n":                         "reading width",
+		"P3\nx 1\n255\n":               "width must be a number",
+		"P3\n0 1\n255\n":               "width must be a number between 1",
+		"P3\n1 1\n70000\n":             "maxval must be a number between 1 and 65535",
+		"P3\n2 1\n255\n1 2 3 4":        "pixel (1, 0) of 2x1",
+		"P3\n1 1\n255\n1 256 3\n":      "got \"256\"",
+		"P3\n1 1\n255\n1 2 blue\n":     "got \"blue\"",
+		"P6\n2 1\n255\n\x01\x02\x03":   "pixel (1, 0) of 2x1",
+		"P6\n1 1\n15\n\x01\x20\x03":    "value 32 exceeds maxval 15",
+		"P6\n16777216 16777216\n255\n": "16777216x16777216 image exceeds the limit of 268435456 pixels",
+		"P6\n16384 16384\n255\n\x01":   "pixel (0, 0) of 16384x16384",
+	}
+
+	for data, expected := range cases {
+		_, err := ReadPPM(strings.NewReader(data))
+		assert.ErrorIs(t, err, ErrMalformedPPM, data)
+		assert.ErrorContains(t, err, expected, data)
+	}
+
+	_, err := ReadPPM(strings.NewReader("P6\n2 1\n255\n\x01\x02\x03"))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// ---------------------------------- Exit codes ----------------------------------
+const (
+	ExitOK          = 0
+	ExitFailure     = 1   // rendering or writing the image went wrong
+	ExitUsage       = 2   // unknown commands, flags or arguments, or flag values out of range
+	ExitBadScene    = 3   // the scene could not be found or loaded
+	ExitInterrupted = 130 // the render was cancelled, e.g. with ctrl-c
+)
+
+// An error that knows which exit code it ends the program with
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e exitError) Unwrap() error {
+	return e.err
+}
+
+func withExitCode(code int, err error) error {
+	return exitError{code: code, err: err}
+}
+
+func usageError(format string, args ...any) error {
+	return withExitCode(ExitUsage, fmt.Errorf(format, args...))
+}
+
+// ---------------------------------- Commands ----------------------------------
+
+/*
+Runs the rattata command line with args, not including the program name, and returns the exit code.
+Cancelling ctx stops a running render.
+*/
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	root := newRootCommand()
+	root.SetArgs(args)
+	root.SetOut(stdout)
+	root.SetErr(stderr)
+
+	err := root.ExecuteContext(ctx)
+	if err == nil {
+		return ExitOK
+	}
+
+	fmt.Fprintf(stderr, "rattata: %v\n", err)
+
+	// whatever cobra itself rejects is a usage problem
+	code := ExitUsage
+	var exit_err exitError
+	if errors.As(err, &exit_err) {
+		code = exit_err.code
+	}
+
+	if code == ExitUsage {
+		fmt.Fprintln(stderr, "Run 'rattata --help' for usage.")
+	}
+	return code
+}
+
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "rattata",
+		Short: "A ray tracer",
+		Long:  "rattata renders scenes described in YAML files, or the built-in scenes listed by 'rattata scenes'.",
+
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(ExitUsage, err)
+	})
+
+	root.AddCommand(newRenderCommand(), newScenesCommand())
+	return root
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"os"
+	"path/filepath"
+	"rattata/rays"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func run(args ...string) (int, string, string) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	code := Run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestScenesListsBuiltins(t *testing.T) {
+	code, stdout, _ := run("scenes")
+
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "hexagon")
+	assert.Contains(t, stdout, "sphere-silhouette")
+}
+
+func TestRenderBuiltinScene(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "silhouette.png")
+
+	code, _, stderr := run("render", "sphere-silhouette", "-o", out, "--width", "20", "--workers", "2")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stderr, "wrote "+out+" (20x20)")
+
+	file, err := os.Open(out)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, img.Bounds().Dx())
+	assert.Equal(t, 20, img.Bounds().Dy())
+
+	// the centre of the image is the red sphere
+	r, g, b, _ := img.At(10, 10).RGBA()
+	assert.Equal(t, [3]uint32{0xffff, 0, 0}, [3]uint32{r, g, b})
+}
+
+func TestRenderSceneFileKeepsAspectRatio(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "world.bmp")
+
+	code, _, stderr := run("render", "../scene/testdata/default_world.yaml", "-o", out, "--height", "6", "--samples", "2", "--depth", "1", "-q")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.Empty(t, stderr)
+	assert.FileExists(t, out)
+
+	// the limit only applies while rendering
+	assert.Equal(t, uint(3), rays.REC_LIMIT)
+}
+
+func TestRenderDepthZeroStillShadesSurfaces(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "world.png")
+
+	code, _, stderr := run("render", "../scene/testdata/default_world.yaml", "-o", out, "--depth", "0")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.Contains(t, stderr, "max depth 0")
+
+	file, err := os.Open(out)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	assert.Nil(t, err)
+	r, g, b, _ := img.At(5, 5).RGBA()
+	assert.NotEqual(t, [3]uint32{0, 0, 0}, [3]uint32{r, g, b})
+}
+
+func TestRenderNamesOutputAfterScene(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+
+	code, _, stderr := run("render", "default-world", "--width", "4", "-q")
+	assert.Equal(t, ExitOK, code, stderr)
+	assert.FileExists(t, "default-world.png")
+}
+
+func TestRenderExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	bad_scene := filepath.Join(dir, "bad.yaml")
+	assert.Nil(t, os.WriteFile(bad_scene, []byte("camera:\n  width: 10\n"), 0644))
+
+	for _, tc := range []struct {
+		args     []string
+		code     int
+		contains string
+	}{
+		{[]string{"render"}, ExitUsage, "render takes exactly one scene, got 0"},
+		{[]string{"render", "hexagon", "--bogus"}, ExitUsage, "unknown flag: --bogus"},
+		{[]string{"render", "hexagon", "--samples", "0"}, ExitUsage, "--samples must be at least 1"},
+		{[]string{"render", "hexagon", "--width", "many"}, ExitUsage, "invalid argument"},
+		{[]string{"render", "hexagon", "-o", filepath.Join(dir, "out.gif")}, ExitUsage, `unsupported image format ".gif"`},
+		{[]string{"render", "no-such-scene"}, ExitBadScene, `no scene file or built-in scene called "no-such-scene"`},
+		{[]string{"render", bad_scene}, ExitBadScene, `line 2: camera is missing "height"`},
+		{[]string{"render", "sphere-silhouette", "--width", "2", "-o", filepath.Join(dir, "missing", "out.png")}, ExitFailure, "no such file or directory"},
+		{[]string{"frobnicate"}, ExitUsage, `unknown command "frobnicate"`},
+	} {
+		code, _, stderr := run(tc.args...)
+		assert.Equal(t, tc.code, code, tc.args)
+		assert.Contains(t, stderr, tc.contains, tc.args)
+	}
+}
+
+func TestRenderStopsWhenCancelled(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hexagon.png")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	stderr := bytes.Buffer{}
+	code := Run(ctx, []string{"render", "hexagon", "-o", out, "-q"}, &bytes.Buffer{}, &stderr)
+
+	assert.Equal(t, ExitInterrupted, code)
+	assert.Contains(t, stderr.String(), "nothing was written")
+	assert.NoFileExists(t, out)
+}
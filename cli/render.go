@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"rattata/canvas"
+	"rattata/observe"
+	"rattata/playground"
+	"rattata/rays"
+	"rattata/scene"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ---------------------------------- render ----------------------------------
+type renderFlags struct {
+	output  string
+	width   uint32
+	height  uint32
+	samples int
+	workers int
+	depth   uint
+	quiet   bool
+}
+
+func newRenderCommand() *cobra.Command {
+	flags := renderFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "render <scene.yaml | built-in scene>",
+		Short: "Render a scene file or a built-in scene to an image",
+		Long: `Renders a scene to an image. The scene is either a YAML scene file or the name of a built-in scene,
+see 'rattata scenes'. The image format follows the extension of the output: .png, .ppm, .bmp, or .pfm
+for the colours as they are, before any clamping.
+
+Size and sampling flags that are left out keep what the scene sets.`,
+		Example: `  rattata render scene.yaml -o scene.png
+  rattata render hexagon --width 800 --samples 3`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return usageError("render takes exactly one scene, got %d", len(args))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRender(cmd, args[0], flags)
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "image to write (default <scene name>.png)")
+	cmd.Flags().Uint32Var(&flags.width, "width", 0, "image width in pixels, keeps the aspect ratio when --height is left out")
+	cmd.Flags().Uint32Var(&flags.height, "height", 0, "image height in pixels, keeps the aspect ratio when --width is left out")
+	cmd.Flags().IntVar(&flags.samples, "samples", 0, "rays per pixel along each side, i.e. samples x samples rays per pixel")
+	cmd.Flags().IntVar(&flags.workers, "workers", 0, "number of goroutines rendering (default the number of CPUs)")
+	cmd.Flags().UintVar(&flags.depth, "depth", rays.REC_LIMIT-1, "how many times rays may bounce through reflection and refraction, 0 turns both off")
+	cmd.Flags().BoolVarP(&flags.quiet, "quiet", "q", false, "do not report progress and statistics")
+
+	return cmd
+}
+
+func runRender(cmd *cobra.Command, scene_ref string, flags renderFlags) error {
+	if cmd.Flags().Changed("width") && flags.width == 0 || cmd.Flags().Changed("height") && flags.height == 0 {
+		return usageError("--width and --height must be at least 1")
+	}
+	if cmd.Flags().Changed("samples") && flags.samples < 1 {
+		return usageError("--samples must be at least 1, got %d", flags.samples)
+	}
+	if flags.workers < 0 {
+		return usageError("--workers cannot be negative, got %d", flags.workers)
+	}
+
+	s, name, err := loadScene(scene_ref, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+
+	output := flags.output
+	if output == "" {
+		output = name + ".png"
+	}
+	is_hdr := strings.EqualFold(filepath.Ext(output), ".pfm")
+	if !is_hdr && !canvas.CanSave(output) {
+		return usageError("cannot write %q: unsupported image format %q, use .png, .ppm, .bmp or .pfm", output, filepath.Ext(output))
+	}
+
+	applyOverrides(&s.Camera, flags)
+
+	// the camera ray's own hit counts towards the limit as well
+	opts := observe.RenderOptions{Workers: flags.workers, RecursionLimit: flags.depth + 1}
+	if !flags.quiet {
+		opts.Progress = func(p observe.RenderProgress) {
+			fmt.Fprintf(cmd.ErrOrStderr(), "\rrendering %s: %3.0f%% (eta %s)  ", name, 100*p.Fraction(), p.ETA.Round(time.Second))
+		}
+	}
+
+	hdr_canvas, stats, err := observe.RenderHDRContext(cmd.Context(), s.Camera, s.World, opts)
+	if !flags.quiet {
+		fmt.Fprintln(cmd.ErrOrStderr())
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return withExitCode(ExitInterrupted, fmt.Errorf("render of %s stopped, nothing was written: %w", name, err))
+		}
+		return withExitCode(ExitFailure, err)
+	}
+
+	if is_hdr {
+		err = savePFM(output, hdr_canvas)
+	} else {
+		err = hdr_canvas.ToneMap(canvas.ToneMapOptions{}).Save(output)
+	}
+	if err != nil {
+		return withExitCode(ExitFailure, err)
+	}
+
+	if !flags.quiet {
+		fmt.Fprintf(cmd.ErrOrStderr(), "wrote %s (%dx%d) in %s: %d rays, %d intersection tests, max depth %d\n",
+			output, s.Camera.Hsize, s.Camera.Vsize, stats.WallTime.Round(time.Millisecond), stats.Rays.Total(), stats.TotalIntersectionTests(), stats.MaxDepth)
+	}
+	return nil
+}
+
+/*
+Reads the scene file at scene_ref or, when there is no such file, builds the built-in scene of that name.
+Also returns the name of the scene, used to name the output by default.
+*/
+func loadScene(scene_ref string, stderr io.Writer) (scene.Scene, string, error) {
+	name := strings.TrimSuffix(filepath.Base(scene_ref), filepath.Ext(scene_ref))
+
+	if _, err := os.Stat(scene_ref); err == nil {
+		s, err := scene.Load(scene_ref)
+		if err != nil {
+			return scene.Scene{}, "", withExitCode(ExitBadScene, fmt.Errorf("cannot load %s:\n%w", scene_ref, err))
+		}
+
+		for _, warning := range s.Warnings {
+			fmt.Fprintf(stderr, "warning: %s: %v\n", scene_ref, warning)
+		}
+		return s, name, nil
+	}
+
+	if builtin, isKnown := playground.Lookup(scene_ref); isKnown {
+		return builtin.Build(), builtin.Name, nil
+	}
+
+	return scene.Scene{}, "", withExitCode(ExitBadScene, fmt.Errorf("no scene file or built-in scene called %q, see 'rattata scenes'", scene_ref))
+}
+
+func applyOverrides(cam *observe.Camera, flags renderFlags) {
+	width, height := flags.width, flags.height
+	switch {
+	case width > 0 && height == 0:
+		height = max(1, uint32(float64(width)*float64(cam.Vsize)/float64(cam.Hsize)+0.5))
+	case height > 0 && width == 0:
+		width = max(1, uint32(float64(height)*float64(cam.Hsize)/float64(cam.Vsize)+0.5))
+	}
+	if width > 0 {
+		cam.Resize(width, height)
+	}
+
+	if flags.samples > 0 {
+		sampling := cam.Sampling
+		switch {
+		case flags.samples == 1:
+			sampling.Mode = observe.SingleSample
+		case sampling.Mode == observe.SingleSample:
+			// scenes shooting a single ray per pixel get jittered sampling, which beats a regular grid
+			sampling.Mode = observe.JitteredSampling
+		}
+		sampling.Samples = flags.samples
+		cam.SetSampling(sampling)
+	}
+}
+
+func savePFM(path string, c canvas.HDRCanvas) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = canvas.WritePFM(file, c); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot save %q: %w", path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"fmt"
+	"rattata/playground"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// ---------------------------------- scenes ----------------------------------
+func newScenesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scenes",
+		Short: "List the built-in scenes",
+		Long:  "Lists the scenes that come with rattata. Render one by passing its name to 'rattata render'.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return usageError("scenes takes no arguments, got %d", len(args))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, s := range playground.Scenes() {
+				fmt.Fprintf(w, "%s\t%s\n", s.Name, s.Description)
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -0,0 +1,180 @@
+package colour
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+Linear RGB colour with float channels. Values are not bound to 0-1:
+light adds up past 1 while rendering and only gets clamped when turned into 8 bit, see ToRGB8.
+*/
+type Colour [3]float64
+
+const (
+	R = iota
+	G
+	B
+)
+
+func New(r, g, b float64) Colour {
+	return Colour{r, g, b}
+}
+
+func Black() Colour {
+	return Colour{0, 0, 0}
+}
+
+func White() Colour {
+	return Colour{1, 1, 1}
+}
+
+// ---------------------------------- Arithmetic ----------------------------------
+
+func (c Colour) Add(o Colour) Colour {
+	return Colour{c[R] + o[R], c[G] + o[G], c[B] + o[B]}
+}
+
+func (c Colour) Sub(o Colour) Colour {
+	return Colour{c[R] - o[R], c[G] - o[G], c[B] - o[B]}
+}
+
+func (c Colour) Scale(k float64) Colour {
+	return Colour{c[R] * k, c[G] * k, c[B] * k}
+}
+
+// Channel by channel product, i.e. light of colour o falling on a surface of colour c
+func (c Colour) Hadamard(o Colour) Colour {
+	return Colour{c[R] * o[R], c[G] * o[G], c[B] * o[B]}
+}
+
+// c at t = 0, o at t = 1
+func (c Colour) Lerp(o Colour, t float64) Colour {
+	return c.Add(o.Sub(c).Scale(t))
+}
+
+// Relative luminance with the Rec. 709 weights, for linear colours
+func (c Colour) Luminance() float64 {
+	return 0.2126*c[R] + 0.7152*c[G] + 0.0722*c[B]
+}
+
+// Every channel bound to 0-1
+func (c Colour) Clamp() Colour {
+	return Colour{clamp01(c[R]), clamp01(c[G]), clamp01(c[B])}
+}
+
+func clamp01(x float64) float64 {
+	return math.Min(1, math.Max(0, x))
+}
+
+// ---------------------------------- Conversions ----------------------------------
+
+/*
+Clamps and scales every channel to 0-255, truncating the fractional part
+*/
+func (c Colour) ToRGB8() [3]uint8 {
+	return [3]uint8{
+		uint8(math.Min(255, max(0, c[R]*255))),
+		uint8(math.Min(255, max(0, c[G]*255))),
+		uint8(math.Min(255, max(0, c[B]*255)))}
+}
+
+func FromRGB8(r, g, b uint8) Colour {
+	return Colour{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+}
+
+// "#rrggbb", going through ToRGB8
+func (c Colour) Hex() string {
+	rgb := c.ToRGB8()
+	return fmt.Sprintf("#%02x%02x%02x", rgb[R], rgb[G], rgb[B])
+}
+
+var ErrInvalidHex = errors.New("invalid hex colour")
+
+/*
+Parses "#rrggbb" or "#rgb", the leading # being optional
+*/
+func ParseHex(s string) (Colour, error) {
+	digits := strings.TrimPrefix(s, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	if len(digits) != 6 {
+		return Colour{}, fmt.Errorf("%w %q: expected 3 or 6 hex digits", ErrInvalidHex, s)
+	}
+
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return Colour{}, fmt.Errorf("%w %q", ErrInvalidHex, s)
+	}
+
+	return FromRGB8(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+}
+
+/*
+Hue in degrees within [0, 360), saturation and value within 0-1. The colour is clamped first.
+Greys have no hue and report 0.
+*/
+func (c Colour) HSV() (h, s, v float64) {
+	c = c.Clamp()
+
+	hi := math.Max(c[R], math.Max(c[G], c[B]))
+	lo := math.Min(c[R], math.Min(c[G], c[B]))
+	chroma := hi - lo
+
+	v = hi
+	if hi > 0 {
+		s = chroma / hi
+	}
+	if chroma == 0 {
+		return 0, s, v
+	}
+
+	switch hi {
+	case c[R]:
+		h = math.Mod((c[G]-c[B])/chroma, 6)
+	case c[G]:
+		h = (c[B]-c[R])/chroma + 2
+	default:
+		h = (c[R]-c[G])/chroma + 4
+	}
+
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, v
+}
+
+// Inverse of HSV; hues outside [0, 360) wrap around
+func FromHSV(h, s, v float64) Colour {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+
+	chroma := v * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - chroma
+
+	var c Colour
+	switch {
+	case h < 60:
+		c = Colour{chroma, x, 0}
+	case h < 120:
+		c = Colour{x, chroma, 0}
+	case h < 180:
+		c = Colour{0, chroma, x}
+	case h < 240:
+		c = Colour{0, x, chroma}
+	case h < 300:
+		c = Colour{x, 0, chroma}
+	default:
+		c = Colour{chroma, 0, x}
+	}
+
+	return Colour{c[R] + m, c[G] + m, c[B] + m}
+}
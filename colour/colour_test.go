@@ -0,0 +1,101 @@
+package colour
+
+import (
+	"rattata/helpers"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertColourApprox(t *testing.T, expected, actual Colour) {
+	for i := range expected {
+		helpers.ApproxEqual(t, expected[i], actual[i], 1e-9)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	c1 := New(0.9, 0.6, 0.75)
+	c2 := New(0.7, 0.1, 0.25)
+
+	assertColourApprox(t, New(1.6, 0.7, 1.0), c1.Add(c2))
+	assertColourApprox(t, New(0.2, 0.5, 0.5), c1.Sub(c2))
+	assertColourApprox(t, New(0.4, 0.6, 0.8), New(0.2, 0.3, 0.4).Scale(2))
+	assertColourApprox(t, New(0.9, 0.2, 0.04), New(1, 0.2, 0.4).Hadamard(New(0.9, 1, 0.1)))
+}
+
+func TestScaleDoesNotClamp(t *testing.T) {
+	assert.Equal(t, New(300, 0, -2), New(150, 0, -1).Scale(2))
+}
+
+func TestLerp(t *testing.T) {
+	a, b := New(0, 0.5, 1), New(1, 0.5, 0)
+
+	assert.Equal(t, a, a.Lerp(b, 0))
+	assert.Equal(t, b, a.Lerp(b, 1))
+	assertColourApprox(t, New(0.25, 0.5, 0.75), a.Lerp(b, 0.25))
+}
+
+func TestLuminance(t *testing.T) {
+	helpers.ApproxEqual(t, 1, White().Luminance(), 1e-9)
+	assert.Equal(t, 0.0, Black().Luminance())
+	assert.Greater(t, New(0, 1, 0).Luminance(), New(1, 0, 0).Luminance())
+	assert.Greater(t, New(1, 0, 0).Luminance(), New(0, 0, 1).Luminance())
+}
+
+func TestClamp(t *testing.T) {
+	assert.Equal(t, New(1, 0, 0.5), New(1.5, -0.2, 0.5).Clamp())
+}
+
+func TestRGB8(t *testing.T) {
+	assert.Equal(t, [3]uint8{255, 0, 127}, New(1.5, -0.5, 0.5).ToRGB8())
+	assert.Equal(t, [3]uint8{255, 204, 153}, New(1, 0.8, 0.6).ToRGB8())
+
+	for _, rgb := range [][3]uint8{{0, 0, 0}, {255, 128, 1}, {17, 34, 51}} {
+		assert.Equal(t, rgb, FromRGB8(rgb[0], rgb[1], rgb[2]).ToRGB8())
+	}
+}
+
+func TestHex(t *testing.T) {
+	assert.Equal(t, "#ff0080", FromRGB8(255, 0, 128).Hex())
+	assert.Equal(t, "#000000", New(-1, 0, 0).Hex())
+
+	c, err := ParseHex("#ff0080")
+	assert.Nil(t, err)
+	assert.Equal(t, FromRGB8(255, 0, 128), c)
+
+	c, err = ParseHex("1aF")
+	assert.Nil(t, err)
+	assert.Equal(t, FromRGB8(0x11, 0xaa, 0xff), c)
+
+	for _, bad := range []string{"", "#ff00", "#gg0000", "#ff00000"} {
+		_, err = ParseHex(bad)
+		assert.ErrorIs(t, err, ErrInvalidHex, bad)
+	}
+}
+
+func TestHSV(t *testing.T) {
+	cases := []struct {
+		c       Colour
+		h, s, v float64
+	}{
+		{New(1, 0, 0), 0, 1, 1},
+		{New(0, 1, 0), 120, 1, 1},
+		{New(0, 0, 1), 240, 1, 1},
+		{New(1, 0, 1), 300, 1, 1},
+		{New(0.5, 0.5, 0.5), 0, 0, 0.5},
+		{New(1, 0.5, 0), 30, 1, 1},
+		{Black(), 0, 0, 0},
+	}
+
+	for _, tc := range cases {
+		h, s, v := tc.c.HSV()
+		helpers.ApproxEqual(t, tc.h, h, 1e-9)
+		helpers.ApproxEqual(t, tc.s, s, 1e-9)
+		helpers.ApproxEqual(t, tc.v, v, 1e-9)
+
+		assertColourApprox(t, tc.c, FromHSV(h, s, v))
+	}
+
+	assertColourApprox(t, New(1, 0, 0), FromHSV(360, 1, 1))
+	assertColourApprox(t, New(1, 0, 1), FromHSV(-60, 1, 1))
+}
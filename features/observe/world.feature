@@ -0,0 +1,100 @@
+Feature: World
+    Scenario: The default world
+        Given w ← default_world()
+        Then w has 1 light
+            And w has 2 objects
+
+    Scenario: Intersect a world with a ray
+        Given w ← default_world()
+            And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+        When xs ← intersect_world(w, r)
+        Then xs.count = 4
+            And xs[0].t = 4
+            And xs[1].t = 4.5
+            And xs[2].t = 5.5
+            And xs[3].t = 6
+
+    Scenario: Precomputing the state of an intersection
+        Given r ← ray(point(0, 0, -5), vector(0, 0, 1))
+            And shape ← sphere()
+            And i ← intersection(4, shape)
+        When comps ← prepare_computations(i, r)
+        Then comps.t = 4
+            And comps.point = point(0, 0, -1)
+            And comps.eyev = vector(0, 0, -1)
+            And comps.normalv = vector(0, 0, -1)
+            And comps.inside = false
+
+    Scenario: The hit, when an intersection occurs on the inside
+        Given r ← ray(point(0, 0, 0), vector(0, 0, 1))
+            And shape ← sphere()
+            And i ← intersection(1, shape)
+        When comps ← prepare_computations(i, r)
+        Then comps.point = point(0, 0, 1)
+            And comps.eyev = vector(0, 0, -1)
+            And comps.inside = true
+            And comps.normalv = vector(0, 0, -1)
+
+    Scenario: The hit should offset the point
+        Given r ← ray(point(0, 0, -5), vector(0, 0, 1))
+            And shape ← sphere()
+            And set_transform(shape, translation(0, 0, 1))
+            And i ← intersection(5, shape)
+        When comps ← prepare_computations(i, r)
+        Then comps.over_point is above comps.point
+            And comps.under_point is below comps.point
+
+    Scenario: The color when a ray misses
+        Given w ← default_world()
+            And r ← ray(point(0, 0, -5), vector(0, 1, 0))
+        When c ← color_at(w, r)
+        Then c = color(0, 0, 0)
+
+    Scenario: The color when a ray hits
+        Given w ← default_world()
+            And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+        When c ← color_at(w, r)
+        Then c = color(0.38066, 0.47583, 0.2855)
+
+    Scenario: The color with an intersection behind the ray
+        Given w ← default_world()
+            And w.objects[1].material.ambient ← 1
+            And r ← ray(point(0, 0, 0.75), vector(0, 0, -1))
+        When c ← color_at(w, r)
+        Then c = color(1, 1, 1)
+
+    Scenario: Every light adds to the color
+        Given w ← default_world()
+            And the light of w is added to w again
+            And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+        When c ← color_at(w, r)
+        Then w has 2 lights
+            And c = color(0.76132, 0.95166, 0.571)
+
+    Scenario: A world without lights is lit by ambient light only
+        Given w ← default_world()
+            And w has its lights removed
+            And r ← ray(point(0, 0, -5), vector(0, 0, 1))
+        When c ← color_at(w, r)
+        Then c = color(0.08, 0.1, 0.06)
+
+    Scenario Outline: Shadows are cast by objects between a point and the light
+        Given w ← default_world()
+        Then is_shadowed(w, <point>) is <in_shadow>
+
+        Examples:
+            | case                           | point                  | in_shadow |
+            | nothing is collinear           | point(0, 10, 0)        | false     |
+            | an object is in between        | point(10, -10, 10)     | true      |
+            | the object is behind the light | point(-20, 20, -20)    | false     |
+            | the object is behind the point | point(-2, 2, -2)       | false     |
+
+    Scenario: The color of a reflective material
+        Given w ← default_world()
+            And shape ← plane()
+            And shape.material.reflective ← 0.5
+            And set_transform(shape, translation(0, -1, 0))
+            And shape is added to w
+            And r ← ray(point(0, 0, -3), vector(0, -√2/2, √2/2))
+        When c ← color_at(w, r)
+        Then c = color(0.87677, 0.92436, 0.82918)
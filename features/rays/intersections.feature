@@ -0,0 +1,87 @@
+Feature: Intersections
+    Scenario: An intersection encapsulates t and object
+        Given s ← sphere()
+        When i ← intersection(3.5, s)
+        Then i.t = 3.5
+            And i.object = s
+
+    Scenario Outline: The hit is the lowest nonnegative intersection
+        Given s ← sphere()
+            And xs ← intersections(<ts>) of s
+        Then the hit has t = <hit>
+
+        Examples:
+            | ts          | hit |
+            | 1, 2        | 1   |
+            | -1, 1       | 1   |
+            | 5, 7, -3, 2 | 2   |
+
+    Scenario: The hit, when all intersections have negative t
+        Given s ← sphere()
+            And xs ← intersections(-2, -1) of s
+        Then there is no hit
+
+    Scenario Outline: A ray intersects a sphere
+        Given r ← ray(<origin>, vector(0, 0, 1))
+            And s ← sphere()
+        When xs ← intersect(s, r)
+        Then xs.count = 2
+            And xs[0].t = <t0>
+            And xs[1].t = <t1>
+
+        Examples:
+            | origin            | t0 | t1 |
+            | point(0, 0, -5)   | 4  | 6  |
+            | point(0, 1, -5)   | 5  | 5  |
+            | point(0, 0, 0)    | -1 | 1  |
+            | point(0, 0, 5)    | -6 | -4 |
+
+    Scenario: A ray misses a sphere
+        Given r ← ray(point(0, 2, -5), vector(0, 0, 1))
+            And s ← sphere()
+        When xs ← intersect(s, r)
+        Then xs.count = 0
+
+    Scenario: Intersecting a scaled sphere with a ray
+        Given r ← ray(point(0, 0, -5), vector(0, 0, 1))
+            And s ← sphere()
+        When set_transform(s, scaling(2, 2, 2))
+            And xs ← intersect(s, r)
+        Then xs.count = 2
+            And xs[0].t = 3
+            And xs[1].t = 7
+
+    Scenario: Intersecting a translated sphere with a ray
+        Given r ← ray(point(0, 0, -5), vector(0, 0, 1))
+            And s ← sphere()
+        When set_transform(s, translation(5, 0, 0))
+            And xs ← intersect(s, r)
+        Then xs.count = 0
+
+    Scenario: Intersecting a ray with a nonempty group
+        Given g ← group()
+            And s1 ← sphere()
+            And s2 ← sphere()
+            And set_transform(s2, translation(0, 0, -3))
+            And s3 ← sphere()
+            And set_transform(s3, translation(5, 0, 0))
+            And add_child(g, s1)
+            And add_child(g, s2)
+            And add_child(g, s3)
+        When r ← ray(point(0, 0, -5), vector(0, 0, 1))
+            And xs ← intersect(g, r)
+        Then xs.count = 4
+            And xs[0].object = s2
+            And xs[1].object = s2
+            And xs[2].object = s1
+            And xs[3].object = s1
+
+    Scenario: Intersecting a transformed group
+        Given g ← group()
+            And set_transform(g, scaling(2, 2, 2))
+            And s ← sphere()
+            And set_transform(s, translation(5, 0, 0))
+            And add_child(g, s)
+        When r ← ray(point(10, 0, -10), vector(0, 0, 1))
+            And xs ← intersect(g, r)
+        Then xs.count = 2
@@ -0,0 +1,75 @@
+Feature: Patterns
+    Background:
+        Given black ← color(0, 0, 0)
+            And white ← color(1, 1, 1)
+
+    Scenario Outline: A stripe pattern alternates in x only
+        Given pattern ← stripe_pattern(white, black)
+        Then pattern_at(pattern, <point>) = <colour>
+
+        Examples:
+            | point               | colour |
+            | point(0, 0, 0)      | white  |
+            | point(0, 1, 0)      | white  |
+            | point(0, 0, 2)      | white  |
+            | point(0.9, 0, 0)    | white  |
+            | point(1, 0, 0)      | black  |
+            | point(-0.1, 0, 0)   | black  |
+            | point(-1, 0, 0)     | black  |
+            | point(-1.1, 0, 0)   | white  |
+
+    Scenario: Stripes with an object transformation
+        Given s ← sphere()
+            And set_transform(s, scaling(2, 2, 2))
+            And pattern ← stripe_pattern(white, black)
+        Then pattern_at_shape(pattern, s, point(1.5, 0, 0)) = white
+
+    Scenario: Stripes with a pattern transformation
+        Given s ← sphere()
+            And pattern ← stripe_pattern(white, black)
+            And set_pattern_transform(pattern, scaling(2, 2, 2))
+        Then pattern_at_shape(pattern, s, point(1.5, 0, 0)) = white
+            And pattern_at_shape(pattern, s, point(2.5, 0, 0)) = black
+
+    Scenario: Stripes with both an object and a pattern transformation
+        Given s ← sphere()
+            And set_transform(s, scaling(2, 2, 2))
+            And pattern ← stripe_pattern(white, black)
+            And set_pattern_transform(pattern, translation(0.5, 0, 0))
+        Then pattern_at_shape(pattern, s, point(2.5, 0, 0)) = white
+
+    Scenario: A gradient linearly interpolates between colors
+        Given pattern ← gradient_pattern(white, black)
+        Then pattern_at(pattern, point(0, 0, 0)) = white
+            And pattern_at(pattern, point(0.25, 0, 0)) = color(0.75, 0.75, 0.75)
+            And pattern_at(pattern, point(0.5, 0, 0)) = color(0.5, 0.5, 0.5)
+            And pattern_at(pattern, point(0.75, 0, 0)) = color(0.25, 0.25, 0.25)
+
+    Scenario: A ring should extend in both x and z
+        Given pattern ← ring_pattern(white, black)
+        Then pattern_at(pattern, point(0, 0, 0)) = white
+            And pattern_at(pattern, point(1, 0, 0)) = black
+            And pattern_at(pattern, point(0, 0, 1)) = black
+            And pattern_at(pattern, point(0.708, 0, 0.708)) = black
+
+    Scenario Outline: Checkers repeat in every dimension
+        Given pattern ← checkers_pattern(white, black)
+        Then pattern_at(pattern, <point>) = <colour>
+
+        Examples:
+            | point                 | colour |
+            | point(0, 0, 0)        | white  |
+            | point(0.99, 0, 0)     | white  |
+            | point(1.01, 0, 0)     | black  |
+            | point(0, 0.99, 0)     | white  |
+            | point(0, 1.01, 0)     | black  |
+            | point(0, 0, 0.99)     | white  |
+            | point(0, 0, 1.01)     | black  |
+            | point(1.01, 0, 1.01)  | white  |
+
+    Scenario: A UV checker maps onto the unit sphere
+        Given pattern ← uv_checkers_pattern(white, black, 1, 1)
+        Then pattern_at(pattern, point(0, 0, 0)) = white
+            And pattern_at(pattern, point(0, 1, 0)) = black
+            And pattern_at(pattern, point(0, -1, 0)) = white
+            And pattern_at(pattern, point(-0.707, -0.707, 0)) = black
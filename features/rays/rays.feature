@@ -0,0 +1,56 @@
+Feature: Rays
+    Scenario: Creating and querying a ray
+        Given origin ← point(1, 2, 3)
+            And direction ← vector(4, 5, 6)
+        When r ← ray(origin, direction)
+        Then r.origin = point(1, 2, 3)
+            And r.direction = vector(4, 5, 6)
+
+    Scenario: Computing a point from a distance
+        Given r ← ray(point(2, 3, 4), vector(1, 0, 0))
+        Then position(r, 0) = point(2, 3, 4)
+            And position(r, 1) = point(3, 3, 4)
+            And position(r, -1) = point(1, 3, 4)
+            And position(r, 2.5) = point(4.5, 3, 4)
+
+    Scenario: Translating a ray
+        Given r ← ray(point(1, 2, 3), vector(0, 1, 0))
+        When r2 ← transform(r, translation(3, 4, 5))
+        Then r2.origin = point(4, 6, 8)
+            And r2.direction = vector(0, 1, 0)
+
+    Scenario: Scaling a ray
+        Given r ← ray(point(1, 2, 3), vector(0, 1, 0))
+        When r2 ← transform(r, scaling(2, 3, 4))
+        Then r2.origin = point(2, 6, 12)
+            And r2.direction = vector(0, 3, 0)
+
+    Scenario: Reflecting a vector approaching at 45°
+        Given v ← vector(1, -1, 0)
+            And n ← vector(0, 1, 0)
+        When reflected ← reflect(v, n)
+        Then reflected = vector(1, 1, 0)
+
+    Scenario: Reflecting a vector off a slanted surface
+        Given v ← vector(0, -1, 0)
+            And n ← vector(√2/2, √2/2, 0)
+        When reflected ← reflect(v, n)
+        Then reflected = vector(1, 0, 0)
+
+    Scenario Outline: Lighting a sphere at the origin seen along the normal
+        Given s ← sphere()
+            And position ← point(0, 0, 0)
+            And eyev ← <eyev>
+            And normalv ← vector(0, 0, -1)
+            And light ← point_light(<light>, color(1, 1, 1))
+        When result ← lighting(s, light, position, eyev, normalv, <shadow>)
+        Then result = <result>
+
+        Examples:
+            | eyev                      | light             | shadow | result                          |
+            | vector(0, 0, -1)          | point(0, 0, -10)  | 0      | color(1.9, 1.9, 1.9)            |
+            | vector(0, √2/2, -√2/2)    | point(0, 0, -10)  | 0      | color(1, 1, 1)                  |
+            | vector(0, 0, -1)          | point(0, 10, -10) | 0      | color(0.7364, 0.7364, 0.7364)   |
+            | vector(0, -√2/2, -√2/2)   | point(0, 10, -10) | 0      | color(1.6364, 1.6364, 1.6364)   |
+            | vector(0, 0, -1)          | point(0, 0, 10)   | 0      | color(0.1, 0.1, 0.1)            |
+            | vector(0, 0, -1)          | point(0, 0, -10)  | 1      | color(0.1, 0.1, 0.1)            |
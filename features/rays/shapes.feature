@@ -0,0 +1,223 @@
+Feature: Shapes
+    Scenario: A sphere's default transformation
+        Given s ← sphere()
+        Then s.transform = identity_matrix
+
+    Scenario: Changing a sphere's transformation
+        Given s ← sphere()
+        When set_transform(s, translation(2, 3, 4))
+        Then s.transform = translation(2, 3, 4)
+
+    Scenario: A sphere has a default material
+        Given s ← sphere()
+        Then s.material.ambient = 0.1
+            And s.material.diffuse = 0.9
+            And s.material.specular = 0.9
+            And s.material.shininess = 200
+
+    Scenario Outline: The normal on a sphere
+        Given s ← sphere()
+        When n ← normal_at(s, <point>)
+        Then n = <normal>
+
+        Examples:
+            | point                      | normal                      |
+            | point(1, 0, 0)             | vector(1, 0, 0)             |
+            | point(0, 1, 0)             | vector(0, 1, 0)             |
+            | point(0, 0, 1)             | vector(0, 0, 1)             |
+            | point(√3/3, √3/3, √3/3)    | vector(√3/3, √3/3, √3/3)    |
+
+    Scenario: Computing the normal on a translated sphere
+        Given s ← sphere()
+            And set_transform(s, translation(0, 1, 0))
+        When n ← normal_at(s, point(0, 1.70711, -0.70711))
+        Then n = vector(0, 0.70711, -0.70711)
+
+    Scenario: The normal of a plane is constant everywhere
+        Given p ← plane()
+        Then normal_at(p, point(0, 0, 0)) = vector(0, 1, 0)
+            And normal_at(p, point(10, 0, -10)) = vector(0, 1, 0)
+            And normal_at(p, point(-5, 0, 150)) = vector(0, 1, 0)
+
+    Scenario Outline: A ray parallel to or coplanar with a plane misses it
+        Given p ← plane()
+            And r ← ray(<origin>, vector(0, 0, 1))
+        When xs ← intersect(p, r)
+        Then xs.count = 0
+
+        Examples:
+            | origin           |
+            | point(0, 10, 0)  |
+            | point(0, 0, 0)   |
+
+    Scenario Outline: A ray intersecting a plane from above and below
+        Given p ← plane()
+            And r ← ray(<origin>, <direction>)
+        When xs ← intersect(p, r)
+        Then xs.count = 1
+            And xs[0].t = 1
+            And xs[0].object = p
+
+        Examples:
+            | origin            | direction         |
+            | point(0, 1, 0)    | vector(0, -1, 0)  |
+            | point(0, -1, 0)   | vector(0, 1, 0)   |
+
+    Scenario Outline: A ray intersects a cube
+        Given c ← cube()
+            And r ← ray(<origin>, <direction>)
+        When xs ← intersect(c, r)
+        Then xs.count = 2
+            And xs[0].t = <t1>
+            And xs[1].t = <t2>
+
+        Examples:
+            | case   | origin               | direction         | t1 | t2 |
+            | +x     | point(5, 0.5, 0)     | vector(-1, 0, 0)  | 4  | 6  |
+            | -x     | point(-5, 0.5, 0)    | vector(1, 0, 0)   | 4  | 6  |
+            | +y     | point(0.5, 5, 0)     | vector(0, -1, 0)  | 4  | 6  |
+            | -y     | point(0.5, -5, 0)    | vector(0, 1, 0)   | 4  | 6  |
+            | +z     | point(0.5, 0, 5)     | vector(0, 0, -1)  | 4  | 6  |
+            | -z     | point(0.5, 0, -5)    | vector(0, 0, 1)   | 4  | 6  |
+            | inside | point(0, 0.5, 0)     | vector(0, 0, 1)   | -1 | 1  |
+
+    Scenario Outline: A ray misses a cube
+        Given c ← cube()
+            And r ← ray(<origin>, <direction>)
+        When xs ← intersect(c, r)
+        Then xs.count = 0
+
+        Examples:
+            | origin            | direction                        |
+            | point(-2, 0, 0)   | vector(0.2673, 0.5345, 0.8018)   |
+            | point(0, -2, 0)   | vector(0.8018, 0.2673, 0.5345)   |
+            | point(0, 0, -2)   | vector(0.5345, 0.8018, 0.2673)   |
+            | point(2, 0, 2)    | vector(0, 0, -1)                 |
+            | point(0, 2, 2)    | vector(0, -1, 0)                 |
+            | point(2, 2, 0)    | vector(-1, 0, 0)                 |
+
+    Scenario Outline: The normal on the surface of a cube
+        Given c ← cube()
+        When n ← normal_at(c, <point>)
+        Then n = <normal>
+
+        Examples:
+            | point                 | normal            |
+            | point(1, 0.5, -0.8)   | vector(1, 0, 0)   |
+            | point(-1, -0.2, 0.9)  | vector(-1, 0, 0)  |
+            | point(-0.4, 1, -0.1)  | vector(0, 1, 0)   |
+            | point(0.3, -1, -0.7)  | vector(0, -1, 0)  |
+            | point(-0.6, 0.3, 1)   | vector(0, 0, 1)   |
+            | point(0.4, 0.4, -1)   | vector(0, 0, -1)  |
+            | point(1, 1, 1)        | vector(1, 0, 0)   |
+            | point(-1, -1, -1)     | vector(-1, 0, 0)  |
+
+    Scenario Outline: A ray misses a cylinder
+        Given cyl ← cylinder()
+            And r ← ray(<origin>, <direction>)
+        When xs ← intersect(cyl, r)
+        Then xs.count = 0
+
+        Examples:
+            | origin            | direction         |
+            | point(1, 0, 0)    | vector(0, 1, 0)   |
+            | point(0, 0, 0)    | vector(0, 1, 0)   |
+            | point(0, 0, -5)   | vector(1, 1, 1)   |
+
+    Scenario Outline: A ray strikes a cylinder
+        Given cyl ← cylinder()
+            And r ← ray(<origin>, <direction>)
+        When xs ← intersect(cyl, r)
+        Then xs.count = 2
+            And xs[0].t = <t0>
+            And xs[1].t = <t1>
+
+        Examples:
+            | origin            | direction           | t0       | t1 |
+            | point(1, 0, -5)   | vector(0, 0, 1)     | 5        | 5  |
+            | point(0, 0, -5)   | vector(0, 0, 1)     | 4        | 6  |
+            | point(0.5, 0, -5) | vector(0.1, 1, 1)   | 4.80198  | 5  |
+
+    Scenario Outline: Normal vector on a cylinder
+        Given cyl ← cylinder()
+        When n ← normal_at(cyl, <point>)
+        Then n = <normal>
+
+        Examples:
+            | point             | normal            |
+            | point(1, 0, 0)    | vector(1, 0, 0)   |
+            | point(0, 5, -1)   | vector(0, 0, -1)  |
+            | point(0, -2, 1)   | vector(0, 0, 1)   |
+            | point(-1, 1, 0)   | vector(-1, 0, 0)  |
+
+    Scenario Outline: Intersecting a constrained cylinder
+        Given cyl ← cylinder()
+            And cyl.minimum ← 1
+            And cyl.maximum ← 2
+            And r ← ray(<origin>, <direction>)
+        When xs ← intersect(cyl, r)
+        Then xs.count = <count>
+
+        Examples:
+            | origin            | direction           | count |
+            | point(0, 1.5, 0)  | vector(0.1, 1, 0)   | 0     |
+            | point(0, 3, -5)   | vector(0, 0, 1)     | 0     |
+            | point(0, 0, -5)   | vector(0, 0, 1)     | 0     |
+            | point(0, 2, -5)   | vector(0, 0, 1)     | 0     |
+            | point(0, 1, -5)   | vector(0, 0, 1)     | 0     |
+            | point(0, 1.5, -2) | vector(0, 0, 1)     | 2     |
+
+    Scenario Outline: Intersecting the caps of a closed cylinder
+        Given cyl ← cylinder()
+            And cyl.minimum ← 1
+            And cyl.maximum ← 2
+            And cyl.closed ← true
+            And r ← ray(<origin>, <direction>)
+        When xs ← intersect(cyl, r)
+        Then xs.count = <count>
+
+        Examples:
+            | origin            | direction           | count |
+            | point(0, 3, 0)    | vector(0, -1, 0)    | 2     |
+            | point(0, 3, -2)   | vector(0, -1, 2)    | 2     |
+            | point(0, 4, -2)   | vector(0, -1, 1)    | 2     |
+            | point(0, 0, -2)   | vector(0, 1, 2)     | 2     |
+            | point(0, -1, -2)  | vector(0, 1, 1)     | 2     |
+
+    Scenario: Creating a new group
+        Given g ← group()
+        Then g.transform = identity_matrix
+            And g is empty
+
+    Scenario: Adding a child to a group
+        Given g ← group()
+            And s ← sphere()
+        When add_child(g, s)
+        Then g is not empty
+            And s.parent = g
+
+    Scenario: Constructing a triangle
+        Given t ← triangle(point(0, 1, 0), point(-1, 0, 0), point(1, 0, 0))
+        Then t.e1 = vector(-1, -1, 0)
+            And t.e2 = vector(1, -1, 0)
+            And t.normal = vector(0, 0, -1)
+
+    Scenario Outline: A ray misses a triangle
+        Given t ← triangle(point(0, 1, 0), point(-1, 0, 0), point(1, 0, 0))
+            And r ← ray(<origin>, <direction>)
+        When xs ← intersect(t, r)
+        Then xs.count = 0
+
+        Examples:
+            | case               | origin            | direction         |
+            | parallel           | point(0, -1, -2)  | vector(0, 1, 0)   |
+            | past the p1-p3 edge | point(1, 1, -2)  | vector(0, 0, 1)   |
+            | past the p1-p2 edge | point(-1, 1, -2) | vector(0, 0, 1)   |
+            | past the p2-p3 edge | point(0, -1, -2) | vector(0, 0, 1)   |
+
+    Scenario: A ray strikes a triangle
+        Given t ← triangle(point(0, 1, 0), point(-1, 0, 0), point(1, 0, 0))
+            And r ← ray(point(0, 0.5, -2), vector(0, 0, 1))
+        When xs ← intersect(t, r)
+        Then xs.count = 1
+            And xs[0].t = 2
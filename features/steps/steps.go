@@ -0,0 +1,363 @@
+/*
+Step definitions and argument parsing shared by the godog feature suites.
+
+Only the features_test.go files import this package; nothing in it is part of the renderer.
+*/
+package steps
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/rays"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cucumber/godog"
+)
+
+// ---------------------------------- Expressions ----------------------------------
+
+// Patterns for the arguments of steps, each one capturing a single argument
+const (
+	NUMBER = `(-?(?:√?\d+(?:\.\d+)?|π)(?:/\d+)?)`
+	TUPLE  = `(\w+|(?:point|vector)\([^)]*\))`
+	COLOUR = `(\w+|color\([^)]*\))`
+	MATRIX = `(.+)`
+)
+
+const EPSILON = 0.0001
+
+var call = regexp.MustCompile(`^(\w+)\((.*)\)$`)
+
+/*
+Parses numbers the way the feature files write them, e.g. 2.5, -√2/2 or π/4
+*/
+func ParseNumber(expr string) (float64, error) {
+	expr = strings.TrimSpace(expr)
+	sign := 1.0
+	if strings.HasPrefix(expr, "-") {
+		sign, expr = -1, expr[1:]
+	}
+
+	numerator, denominator, isFraction := strings.Cut(expr, "/")
+	div := 1.0
+	if isFraction {
+		d, err := strconv.ParseFloat(denominator, 64)
+		if err != nil {
+			return 0, err
+		}
+		div = d
+	}
+
+	var val float64
+	switch {
+	case numerator == "π":
+		val = math.Pi
+	case strings.HasPrefix(numerator, "√"):
+		root, err := strconv.ParseFloat(strings.TrimPrefix(numerator, "√"), 64)
+		if err != nil {
+			return 0, err
+		}
+		val = math.Sqrt(root)
+	default:
+		v, err := strconv.ParseFloat(numerator, 64)
+		if err != nil {
+			return 0, err
+		}
+		val = v
+	}
+
+	return sign * val / div, nil
+}
+
+// Splits a call such as point(1, 2, 3) into its name and its raw arguments
+func splitCall(expr string) (string, []string, error) {
+	match := call.FindStringSubmatch(strings.TrimSpace(expr))
+	if match == nil {
+		return "", nil, fmt.Errorf("%q is not a call", expr)
+	}
+
+	args := make([]string, 0)
+	if strings.TrimSpace(match[2]) == "" {
+		return match[1], args, nil
+	}
+	for _, arg := range strings.Split(match[2], ",") {
+		args = append(args, strings.TrimSpace(arg))
+	}
+	return match[1], args, nil
+}
+
+// Same as splitCall for calls taking numbers only
+func parseCall(expr string) (string, []float64, error) {
+	name, raw_args, err := splitCall(expr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := make([]float64, len(raw_args))
+	for i, arg := range raw_args {
+		if args[i], err = ParseNumber(arg); err != nil {
+			return "", nil, fmt.Errorf("bad argument in %q: %w", expr, err)
+		}
+	}
+	return name, args, nil
+}
+
+func ApproxEqual(expected, actual []float64) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if math.Abs(expected[i]-actual[i]) > EPSILON {
+			return fmt.Errorf("expected %v, got %v", expected, actual)
+		}
+	}
+	return nil
+}
+
+func ApproxEqualTuple(expected, actual coordinates.Coordinate) error {
+	return ApproxEqual(expected[:], actual[:])
+}
+
+func ApproxEqualColour(expected, actual rays.Colour) error {
+	return ApproxEqual(expected[:], actual[:])
+}
+
+func ApproxEqualNumber(expected, actual float64) error {
+	return ApproxEqual([]float64{expected}, []float64{actual})
+}
+
+// ---------------------------------- Scenario state ----------------------------------
+
+/*
+The tuples, rays and colours a scenario has named so far.
+Each suite embeds it into its own state and hands it to WithState, so that the steps in here can find it.
+*/
+type State struct {
+	Tuples  map[string]coordinates.Coordinate
+	Rays    map[string]rays.Ray
+	Colours map[string]rays.Colour
+}
+
+type stateKey struct{}
+
+func NewState() *State {
+	return &State{
+		Tuples:  make(map[string]coordinates.Coordinate),
+		Rays:    make(map[string]rays.Ray),
+		Colours: make(map[string]rays.Colour),
+	}
+}
+
+func WithState(ctx context.Context, st *State) context.Context {
+	return context.WithValue(ctx, stateKey{}, st)
+}
+
+func StateOf(ctx context.Context) *State {
+	return ctx.Value(stateKey{}).(*State)
+}
+
+/*
+Looks up a named tuple, or parses point(x, y, z) and vector(x, y, z)
+*/
+func (st *State) Tuple(expr string) (coordinates.Coordinate, error) {
+	if tup, isKnown := st.Tuples[expr]; isKnown {
+		return tup, nil
+	}
+
+	name, args, err := parseCall(expr)
+	if err != nil {
+		return coordinates.Coordinate{}, fmt.Errorf("no tuple called %q: %w", expr, err)
+	}
+	if len(args) != 3 {
+		return coordinates.Coordinate{}, fmt.Errorf("%s takes 3 arguments, got %d", name, len(args))
+	}
+
+	switch name {
+	case "point":
+		return coordinates.CreatePoint(args[0], args[1], args[2]), nil
+	case "vector":
+		return coordinates.CreateVector(args[0], args[1], args[2]), nil
+	}
+	return coordinates.Coordinate{}, fmt.Errorf("no tuple called %q", expr)
+}
+
+/*
+Looks up a named colour, or parses color(r, g, b)
+*/
+func (st *State) Colour(expr string) (rays.Colour, error) {
+	if col, isKnown := st.Colours[expr]; isKnown {
+		return col, nil
+	}
+
+	name, args, err := parseCall(expr)
+	if err != nil || name != "color" || len(args) != 3 {
+		return rays.Colour{}, fmt.Errorf("no colour called %q", expr)
+	}
+	return rays.Colour{args[0], args[1], args[2]}, nil
+}
+
+func (st *State) Ray(name string) (rays.Ray, error) {
+	r, isKnown := st.Rays[name]
+	if !isKnown {
+		return rays.Ray{}, fmt.Errorf("no ray called %q", name)
+	}
+	return r, nil
+}
+
+/*
+Parses a product of transformations such as rotation_y(π/4) * translation(0, -2, 5).
+rotation_y turns the way the book's does, which is GivensRotationMatrix3DLeftHanded here
+*/
+func (st *State) Matrix(expr string) (matrices.Matrix, error) {
+	res := matrices.NewIdentityMatrix(4)
+
+	for _, factor := range strings.Split(expr, "*") {
+		factor = strings.TrimSpace(factor)
+		if factor == "identity_matrix" {
+			continue
+		}
+
+		var mt matrices.Matrix
+		if strings.HasPrefix(factor, "view_transform(") {
+			_, args, err := splitCall(factor)
+			if err != nil {
+				return nil, err
+			}
+			if len(args) != 3 {
+				return nil, fmt.Errorf("view_transform takes 3 arguments, got %d", len(args))
+			}
+			var tuples [3]coordinates.Coordinate
+			for i, arg := range args {
+				if tuples[i], err = st.Tuple(arg); err != nil {
+					return nil, err
+				}
+			}
+			mt = matrices.View_Transform(tuples[0], tuples[1], tuples[2])
+		} else {
+			name, args, err := parseCall(factor)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case name == "translation" && len(args) == 3:
+				mt = matrices.TranslationMatrix(args[0], args[1], args[2])
+			case name == "scaling" && len(args) == 3:
+				mt = matrices.ScalingMatrix(args[0], args[1], args[2])
+			case name == "rotation_y" && len(args) == 1:
+				mt = matrices.GivensRotationMatrix3DLeftHanded(coordinates.Y, args[0])
+			default:
+				return nil, fmt.Errorf("unknown transformation %q", factor)
+			}
+		}
+
+		res, _ = res.Multiply(mt)
+	}
+	return res, nil
+}
+
+// ---------------------------------- Tuples, rays and colours ----------------------------------
+
+func givenTuple(ctx context.Context, name, expr string) error {
+	st := StateOf(ctx)
+	tup, err := st.Tuple(expr)
+	if err != nil {
+		return err
+	}
+	st.Tuples[name] = tup
+	return nil
+}
+
+func givenRay(ctx context.Context, name, origin_expr, direction_expr string) error {
+	st := StateOf(ctx)
+	origin, err := st.Tuple(origin_expr)
+	if err != nil {
+		return err
+	}
+	direction, err := st.Tuple(direction_expr)
+	if err != nil {
+		return err
+	}
+
+	st.Rays[name] = rays.NewRay(origin, direction)
+	return nil
+}
+
+func checkRayOrigin(ctx context.Context, ray_name, expr string) error {
+	st := StateOf(ctx)
+	r, err := st.Ray(ray_name)
+	if err != nil {
+		return err
+	}
+	expected, err := st.Tuple(expr)
+	if err != nil {
+		return err
+	}
+	return ApproxEqualTuple(expected, r.Origin)
+}
+
+func checkRayDirection(ctx context.Context, ray_name, expr string) error {
+	st := StateOf(ctx)
+	r, err := st.Ray(ray_name)
+	if err != nil {
+		return err
+	}
+	expected, err := st.Tuple(expr)
+	if err != nil {
+		return err
+	}
+	return ApproxEqualTuple(expected, r.Direction)
+}
+
+func checkTuple(ctx context.Context, name, expr string) error {
+	st := StateOf(ctx)
+	actual, isKnown := st.Tuples[name]
+	if !isKnown {
+		return fmt.Errorf("no tuple called %q", name)
+	}
+	expected, err := st.Tuple(expr)
+	if err != nil {
+		return err
+	}
+	return ApproxEqualTuple(expected, actual)
+}
+
+func givenColour(ctx context.Context, name, expr string) error {
+	st := StateOf(ctx)
+	col, err := st.Colour(expr)
+	if err != nil {
+		return err
+	}
+	st.Colours[name] = col
+	return nil
+}
+
+func checkColour(ctx context.Context, name, expr string) error {
+	st := StateOf(ctx)
+	actual, isKnown := st.Colours[name]
+	if !isKnown {
+		return fmt.Errorf("no colour called %q", name)
+	}
+	expected, err := st.Colour(expr)
+	if err != nil {
+		return err
+	}
+	return ApproxEqualColour(expected, actual)
+}
+
+// Registers the steps every suite shares; a suite calls it from its own InitializeScenario
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	ctx.Step(`^(\w+) ← `+TUPLE+`$`, givenTuple)
+	ctx.Step(`^(\w+) ← ray\(`+TUPLE+`, `+TUPLE+`\)$`, givenRay)
+	ctx.Step(`^(\w+)\.origin = `+TUPLE+`$`, checkRayOrigin)
+	ctx.Step(`^(\w+)\.direction = `+TUPLE+`$`, checkRayDirection)
+	ctx.Step(`^(\w+) = `+TUPLE+`$`, checkTuple)
+
+	ctx.Step(`^(\w+) ← `+COLOUR+`$`, givenColour)
+	ctx.Step(`^(\w+) = `+COLOUR+`$`, checkColour)
+}
@@ -1,15 +1,16 @@
 package main
 
-import "rattata/playground"
+import (
+	"context"
+	"os"
+	"os/signal"
+	"rattata/cli"
+)
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
 
-	// playground.ProcessRaySphereIntersection()
-
-	// playground.ProcessPhongReflection()
-
-	// playground.CreateGoofyWorld()
-
-	playground.AHexagon()
-
+	os.Exit(code)
 }
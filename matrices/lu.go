@@ -0,0 +1,152 @@
+package matrices
+
+import (
+	"errors"
+	"math"
+)
+
+/*
+Relative tolerance for singularity: a pivot counts as zero once it is this small next to the largest entry of its column
+in the original matrix. Scaling a column scales its pivot alike, so scalings such as ScalingMatrix(1e6, 1e6, 1e-5)
+stay invertible however tiny or huge their entries are.
+*/
+const SINGULARITY_TOLERANCE = 1e-10
+
+var ErrSingularMatrix = errors.New("matrix is singular")
+
+/*
+LU decomposition of a square matrix with partial pivoting, i.e. P*A = L*U.
+
+L (unit diagonal, not stored) and U are packed into a single matrix,
+pivots[i] is the row of A that ended up at row i, scales[j] the largest magnitude in column j of A.
+
+The decomposition itself only fails on a pivot that is exactly zero, the tolerance is applied by Solve.
+*/
+type LU struct {
+	lu     Matrix
+	pivots []int
+	scales []float64
+	sign   float64
+}
+
+func (m Matrix) LUDecompose() (LU, error) {
+	if m.Row() != m.Column() {
+		return LU{}, errors.New("LU decomposition needs a square matrix")
+	}
+
+	n := m.Row()
+	lu := NewMatrix(n, n)
+	pivots := make([]int, n)
+	for i := range n {
+		copy(lu[i], m[i])
+		pivots[i] = i
+	}
+	sign := 1.0
+
+	scales := make([]float64, n)
+	for i := range n {
+		for j := range n {
+			scales[j] = max(scales[j], math.Abs(m[i][j]))
+		}
+	}
+
+	for k := range n {
+		// pick the largest remaining entry in the column to keep the elimination stable
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i][k]) > math.Abs(lu[p][k]) {
+				p = i
+			}
+		}
+
+		if lu[p][k] == 0 {
+			return LU{}, ErrSingularMatrix
+		}
+
+		if p != k {
+			lu[p], lu[k] = lu[k], lu[p]
+			pivots[p], pivots[k] = pivots[k], pivots[p]
+			sign = -sign
+		}
+
+		for i := k + 1; i < n; i++ {
+			lu[i][k] /= lu[k][k]
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= lu[i][k] * lu[k][j]
+			}
+		}
+	}
+
+	return LU{lu: lu, pivots: pivots, scales: scales, sign: sign}, nil
+}
+
+/*
+Whether some pivot is within SINGULARITY_TOLERANCE of zero, relative to its column, or not finite.
+Such a matrix may be invertible on paper but its inverse would be mostly rounding errors.
+*/
+func (d LU) IsNearlySingular() bool {
+	for k := range d.lu {
+		if !(math.Abs(d.lu[k][k]) > SINGULARITY_TOLERANCE*d.scales[k]) || math.IsInf(d.scales[k], 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d LU) Determinant() float64 {
+	det := d.sign
+	for i := range d.lu {
+		det *= d.lu[i][i]
+	}
+	return det
+}
+
+/*
+Solves A*X = b for X, where b may hold several right hand sides as columns.
+Returns ErrSingularMatrix when A is nearly singular, see IsNearlySingular.
+*/
+func (d LU) Solve(b Matrix) (Matrix, error) {
+	n := len(d.lu)
+	if b.Row() != n {
+		return nil, errors.New("right hand side does not match the matrix size")
+	}
+	if d.IsNearlySingular() {
+		return nil, ErrSingularMatrix
+	}
+
+	cols := b.Column()
+	x := NewMatrix(n, cols)
+
+	for c := range cols {
+		// forward substitution through L, with the rows of b permuted like A's
+		for i := range n {
+			v := b[d.pivots[i]][c]
+			for j := range i {
+				v -= d.lu[i][j] * x[j][c]
+			}
+			x[i][c] = v
+		}
+
+		// back substitution through U
+		for i := n - 1; i >= 0; i-- {
+			v := x[i][c]
+			for j := i + 1; j < n; j++ {
+				v -= d.lu[i][j] * x[j][c]
+			}
+			x[i][c] = v / d.lu[i][i]
+		}
+	}
+
+	return x, nil
+}
+
+/*
+Solves m*X = b for X. Returns ErrSingularMatrix when m has no inverse.
+*/
+func (m Matrix) Solve(b Matrix) (Matrix, error) {
+	d, err := m.LUDecompose()
+	if err != nil {
+		return nil, err
+	}
+	return d.Solve(b)
+}
@@ -0,0 +1,181 @@
+package matrices
+
+import (
+	"errors"
+	"fmt"
+	"rattata/coordinates"
+)
+
+/*
+Mat4 is a fixed size 4x4 matrix held by value.
+
+Unlike Matrix it never touches the heap, which makes it the type to use on per ray paths
+(ray transforms, world/object conversions, normals). Matrix stays around for general NxN work.
+*/
+type Mat4 [4][4]float64
+
+func NewIdentityMat4() Mat4 {
+	return Mat4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+/*
+Copies a 4x4 Matrix into a Mat4. Matrices of any other size are rejected.
+*/
+func Mat4FromMatrix(m Matrix) (Mat4, error) {
+	var res Mat4
+
+	if m.Row() != 4 || m.Column() != 4 {
+		return res, errors.New("only 4x4 matrices can be converted to Mat4")
+	}
+
+	for i := range 4 {
+		for j := range 4 {
+			res[i][j] = m[i][j]
+		}
+	}
+	return res, nil
+}
+
+func (m Mat4) ToMatrix() Matrix {
+	_matrix := NewMatrix(4, 4)
+
+	for i := range 4 {
+		for j := range 4 {
+			_matrix[i][j] = m[i][j]
+		}
+	}
+	return _matrix
+}
+
+func (m Mat4) Multiply(m2 Mat4) Mat4 {
+	var res Mat4
+
+	for i := range 4 {
+		for j := range 4 {
+			res[i][j] = m[i][0]*m2[0][j] + m[i][1]*m2[1][j] + m[i][2]*m2[2][j] + m[i][3]*m2[3][j]
+		}
+	}
+	return res
+}
+
+/*
+Multiplies the coordinate as a column vector, W included
+*/
+func (m Mat4) MulCoordinate(c coordinates.Coordinate) coordinates.Coordinate {
+	var res coordinates.Coordinate
+
+	for i := range 4 {
+		res[i] = m[i][0]*c[0] + m[i][1]*c[1] + m[i][2]*c[2] + m[i][3]*c[3]
+	}
+	return res
+}
+
+// Transforms c as a point, translation included
+func (m Mat4) MulPoint(c coordinates.Coordinate) coordinates.Coordinate {
+	c[coordinates.W] = 1
+	return m.MulCoordinate(c)
+}
+
+// Transforms c as a direction, translation ignored. The result always has W = 0
+func (m Mat4) MulVector(c coordinates.Coordinate) coordinates.Coordinate {
+	c[coordinates.W] = 0
+	res := m.MulCoordinate(c)
+	res[coordinates.W] = 0
+	return res
+}
+
+func (m Mat4) T() Mat4 {
+	var res Mat4
+
+	for i := range 4 {
+		for j := range 4 {
+			res[i][j] = m[j][i]
+		}
+	}
+	return res
+}
+
+func (m Mat4) Determinant() float64 {
+	s0, s1, s2, s3, s4, s5, c0, c1, c2, c3, c4, c5 := m.subDeterminants()
+	return s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+}
+
+/*
+Closed form inverse built from the 2x2 sub determinants of the top and bottom row pairs,
+which needs far fewer operations than going through cofactors recursively.
+*/
+func (m Mat4) Inverse() (Mat4, error) {
+	s0, s1, s2, s3, s4, s5, c0, c1, c2, c3, c4, c5 := m.subDeterminants()
+
+	det := s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+	// singularity is judged by the LU decomposition, so that Matrix.Inverse and Mat4.Inverse always agree
+	if d, err := m.ToMatrix().LUDecompose(); det == 0 || err != nil || d.IsNearlySingular() {
+		return Mat4{}, ErrSingularMatrix
+	}
+	inv_det := 1 / det
+
+	return Mat4{
+		{
+			(m[1][1]*c5 - m[1][2]*c4 + m[1][3]*c3) * inv_det,
+			(-m[0][1]*c5 + m[0][2]*c4 - m[0][3]*c3) * inv_det,
+			(m[3][1]*s5 - m[3][2]*s4 + m[3][3]*s3) * inv_det,
+			(-m[2][1]*s5 + m[2][2]*s4 - m[2][3]*s3) * inv_det,
+		},
+		{
+			(-m[1][0]*c5 + m[1][2]*c2 - m[1][3]*c1) * inv_det,
+			(m[0][0]*c5 - m[0][2]*c2 + m[0][3]*c1) * inv_det,
+			(-m[3][0]*s5 + m[3][2]*s2 - m[3][3]*s1) * inv_det,
+			(m[2][0]*s5 - m[2][2]*s2 + m[2][3]*s1) * inv_det,
+		},
+		{
+			(m[1][0]*c4 - m[1][1]*c2 + m[1][3]*c0) * inv_det,
+			(-m[0][0]*c4 + m[0][1]*c2 - m[0][3]*c0) * inv_det,
+			(m[3][0]*s4 - m[3][1]*s2 + m[3][3]*s0) * inv_det,
+			(-m[2][0]*s4 + m[2][1]*s2 - m[2][3]*s0) * inv_det,
+		},
+		{
+			(-m[1][0]*c3 + m[1][1]*c1 - m[1][2]*c0) * inv_det,
+			(m[0][0]*c3 - m[0][1]*c1 + m[0][2]*c0) * inv_det,
+			(-m[3][0]*s3 + m[3][1]*s1 - m[3][2]*s0) * inv_det,
+			(m[2][0]*s3 - m[2][1]*s1 + m[2][2]*s0) * inv_det,
+		},
+	}, nil
+}
+
+/*
+Inverts a 4x4 transformation for the shapes, patterns and cameras that cannot work without one.
+Panics when m is not 4x4 or cannot be inverted, so callers taking user input should check with Inverse first.
+*/
+func MustInvertTransformation(m Matrix) Mat4 {
+	m4, err := Mat4FromMatrix(m)
+	if err == nil {
+		var inverse Mat4
+		if inverse, err = m4.Inverse(); err == nil {
+			return inverse
+		}
+	}
+	panic(fmt.Sprintf("transformation %v cannot be inverted: %v", m, err))
+}
+
+// s* come from rows 0 and 1, c* from rows 2 and 3
+func (m Mat4) subDeterminants() (s0, s1, s2, s3, s4, s5, c0, c1, c2, c3, c4, c5 float64) {
+	s0 = m[0][0]*m[1][1] - m[1][0]*m[0][1]
+	s1 = m[0][0]*m[1][2] - m[1][0]*m[0][2]
+	s2 = m[0][0]*m[1][3] - m[1][0]*m[0][3]
+	s3 = m[0][1]*m[1][2] - m[1][1]*m[0][2]
+	s4 = m[0][1]*m[1][3] - m[1][1]*m[0][3]
+	s5 = m[0][2]*m[1][3] - m[1][2]*m[0][3]
+
+	c5 = m[2][2]*m[3][3] - m[3][2]*m[2][3]
+	c4 = m[2][1]*m[3][3] - m[3][1]*m[2][3]
+	c3 = m[2][1]*m[3][2] - m[3][1]*m[2][2]
+	c2 = m[2][0]*m[3][3] - m[3][0]*m[2][3]
+	c1 = m[2][0]*m[3][2] - m[3][0]*m[2][2]
+	c0 = m[2][0]*m[3][1] - m[3][0]*m[2][1]
+	return
+}
@@ -0,0 +1,146 @@
+package matrices
+
+import (
+	"math"
+	"rattata/coordinates"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMat4Conversion(t *testing.T) {
+	Matrix_a := Matrix{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 8, 7, 6}, {5, 4, 3, 2}}
+	m4, err := Mat4FromMatrix(Matrix_a)
+	assert.Nil(t, err)
+	assert.True(t, Matrix_a.IsEqual(m4.ToMatrix()))
+
+	_, err = Mat4FromMatrix(NewIdentityMatrix(3))
+	assert.NotNil(t, err)
+}
+
+func TestMat4Mult(t *testing.T) {
+	Matrix_a := Mat4{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 8, 7, 6}, {5, 4, 3, 2}}
+	Matrix_b := Mat4{{-2, 1, 2, 3}, {3, 2, 1, -1}, {4, 3, 6, 5}, {1, 2, 7, 8}}
+	Matrix_req_res := Mat4{{20, 22, 50, 48}, {44, 54, 114, 108}, {40, 58, 110, 102}, {16, 26, 46, 42}}
+
+	assert.Equal(t, Matrix_req_res, Matrix_a.Multiply(Matrix_b))
+	assert.Equal(t, Matrix_a, Matrix_a.Multiply(NewIdentityMat4()))
+	assert.Equal(t, Matrix_a, Matrix_a.T().T())
+}
+
+func TestMat4PointAndVector(t *testing.T) {
+	m4, _ := Mat4FromMatrix(PerformOrderedChainingOps(ScalingMatrix(2, 3, 4), TranslationMatrix(1, -1, 5)))
+
+	assert.Equal(t, coordinates.CreatePoint(3, 2, 17), m4.MulPoint(coordinates.CreatePoint(1, 1, 3)))
+	assert.Equal(t, coordinates.CreateVector(2, 3, 12), m4.MulVector(coordinates.CreateVector(1, 1, 3)))
+
+	// agrees with the slice based path
+	p := coordinates.CreatePoint(-4, 0.5, 9)
+	assert.Equal(t, MatrixToCoordinate(PerformOrderedChainingOps(CoordinateToMatrix(p), m4.ToMatrix())), m4.MulCoordinate(p))
+}
+
+func TestMat4Inverse(t *testing.T) {
+	Matrix_a := Matrix{{-5, 2, 6, -8}, {1, -5, 1, 8}, {7, 7, -6, -7}, {1, -3, 7, 4}}
+	m4, _ := Mat4FromMatrix(Matrix_a)
+	assert.Equal(t, float64(532), m4.Determinant())
+
+	m4_inv, err := m4.Inverse()
+	assert.Nil(t, err)
+	Matrix_a_inv, _ := Matrix_a.Inverse()
+	testApproxEqualMatrix(t, Matrix_a_inv, m4_inv.ToMatrix(), 0.000001)
+	testApproxEqualMatrix(t, NewIdentityMatrix(4), m4.Multiply(m4_inv).ToMatrix(), 0.000001)
+
+	rotation, _ := Mat4FromMatrix(GivensRotationMatrix3D(coordinates.Z, math.Pi/3))
+	rotation_inv, _ := rotation.Inverse()
+	testApproxEqualMatrix(t, rotation.T().ToMatrix(), rotation_inv.ToMatrix(), 0.000001)
+
+	_, err = Mat4{{1, 2, 3, 4}, {2, 4, 6, 8}, {0, 0, 1, 0}, {0, 0, 0, 1}}.Inverse()
+	assert.NotNil(t, err)
+}
+
+func TestMat4InverseOfTinyScale(t *testing.T) {
+	// determinants around 1e-12 are fine as long as the entries are that small too
+	tiny := PerformOrderedChainingOps(ScalingMatrix(1e-4, 1e-4, 1e-4), TranslationMatrix(10, -3, 2))
+	m4, _ := Mat4FromMatrix(tiny)
+	m4_inv, err := m4.Inverse()
+	assert.Nil(t, err)
+
+	tiny_inv, err := tiny.Inverse()
+	assert.Nil(t, err)
+	testApproxEqualMatrix(t, tiny_inv, m4_inv.ToMatrix(), 0.000001)
+	testApproxEqualMatrix(t, NewIdentityMatrix(4), m4.Multiply(m4_inv).ToMatrix(), 0.000001)
+
+	m4, _ = Mat4FromMatrix(ScalingMatrix(4e-4, 1, 1))
+	_, err = m4.Inverse()
+	assert.Nil(t, err)
+
+	_, err = Mat4{{1e-4, 2e-4, 0, 0}, {2e-4, 4e-4, 0, 0}, {0, 0, 1e-4, 0}, {0, 0, 0, 1}}.Inverse()
+	assert.ErrorIs(t, err, ErrSingularMatrix)
+	_, err = Mat4{{math.Inf(1), 0, 0, 0}, {0, 1, 0, 0}, {0, 0, 1, 0}, {0, 0, 0, 1}}.Inverse()
+	assert.ErrorIs(t, err, ErrSingularMatrix)
+}
+
+func TestMatrixAndMat4AgreeOnAnisotropicScalings(t *testing.T) {
+	for _, mt := range []Matrix{
+		ScalingMatrix(1e6, 1e6, 1e-5),
+		ScalingMatrix(1, 1e-11, 1),
+		PerformOrderedChainingOps(ScalingMatrix(1e-6, 3, 1e5), TranslationMatrix(4, -2, 7)),
+	} {
+		m4, _ := Mat4FromMatrix(mt)
+		m4_inv, err := m4.Inverse()
+		assert.Nil(t, err, "%v", mt)
+		mt_inv, err := mt.Inverse()
+		assert.Nil(t, err, "%v", mt)
+
+		product, _ := mt.Multiply(m4_inv.ToMatrix())
+		testApproxEqualMatrix(t, NewIdentityMatrix(4), product, 0.000001)
+		product, _ = mt.Multiply(mt_inv)
+		testApproxEqualMatrix(t, NewIdentityMatrix(4), product, 0.000001)
+	}
+
+	// columns that only differ by rounding are rejected by both
+	nearly := Matrix{{1e6, 1e6 * (1 + 1e-13), 0, 0}, {1e-5, 1e-5, 0, 0}, {0, 0, 1, 0}, {0, 0, 0, 1}}
+	_, err := nearly.Inverse()
+	assert.ErrorIs(t, err, ErrSingularMatrix)
+	m4, _ := Mat4FromMatrix(nearly)
+	_, err = m4.Inverse()
+	assert.ErrorIs(t, err, ErrSingularMatrix)
+}
+
+func BenchmarkMatrixPointTransform(b *testing.B) {
+	mt := PerformOrderedChainingOps(ScalingMatrix(2, 3, 4), TranslationMatrix(1, -1, 5))
+	p := coordinates.CreatePoint(1, 2, 3)
+	b.ReportAllocs()
+
+	for range b.N {
+		p = MatrixToCoordinate(PerformOrderedChainingOps(CoordinateToMatrix(p), mt))
+	}
+}
+
+func BenchmarkMat4PointTransform(b *testing.B) {
+	m4, _ := Mat4FromMatrix(PerformOrderedChainingOps(ScalingMatrix(2, 3, 4), TranslationMatrix(1, -1, 5)))
+	p := coordinates.CreatePoint(1, 2, 3)
+	b.ReportAllocs()
+
+	for range b.N {
+		p = m4.MulPoint(p)
+	}
+}
+
+func BenchmarkMatrixInverse(b *testing.B) {
+	mt := PerformOrderedChainingOps(ScalingMatrix(2, 3, 4), GivensRotationMatrix3D(coordinates.Y, 1), TranslationMatrix(1, -1, 5))
+	b.ReportAllocs()
+
+	for range b.N {
+		mt.Inverse()
+	}
+}
+
+func BenchmarkMat4Inverse(b *testing.B) {
+	m4, _ := Mat4FromMatrix(PerformOrderedChainingOps(ScalingMatrix(2, 3, 4), GivensRotationMatrix3D(coordinates.Y, 1), TranslationMatrix(1, -1, 5)))
+	b.ReportAllocs()
+
+	for range b.N {
+		m4.Inverse()
+	}
+}
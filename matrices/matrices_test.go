@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"rattata/helpers"
 	"strconv"
 	"testing"
@@ -167,3 +168,79 @@ func testApproxEqualMatrix(t *testing.T, expected, acutal Matrix, diff_allowed f
 		}
 	}
 }
+
+func TestMatrixDeterminant4x4(t *testing.T) {
+	Matrix_a := Matrix{{-2, -8, 3, 5}, {-3, 1, 7, 3}, {1, 2, -9, 6}, {-6, 7, 7, -9}}
+	d, err := Matrix_a.Determinant()
+	assert.Nil(t, err)
+	helpers.ApproxEqual(t, -4071, d, 0.000001)
+
+	// cofactor expansion would never finish on this one
+	Matrix_b := NewIdentityMatrix(12).ScaleMul(2)
+	d, err = Matrix_b.Determinant()
+	assert.Nil(t, err)
+	helpers.ApproxEqual(t, 4096, d, 0.000001)
+}
+
+func TestMatrixDeterminantHasNoTolerance(t *testing.T) {
+	d, err := Matrix{{1, 0, 0}, {0, 1e-11, 0}, {0, 0, 1}}.Determinant()
+	assert.Nil(t, err)
+	assert.Equal(t, 1e-11, d)
+
+	d, err = ScalingMatrix(1e6, 1e6, 1e-5).Determinant()
+	assert.Nil(t, err)
+	helpers.ApproxEqual(t, 1e7, d, 1e-6)
+}
+
+func TestMatrixSingularInverse(t *testing.T) {
+	Matrix_a := Matrix{{-4, 2, -2, -3}, {9, 6, 2, 6}, {0, -5, 1, -5}, {0, 0, 0, 0}}
+	d, err := Matrix_a.Determinant()
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), d)
+	assert.False(t, IsMatrixInvertableBasedOnDeterminant(d))
+
+	_, err = Matrix_a.Inverse()
+	assert.ErrorIs(t, err, ErrSingularMatrix)
+
+	// almost singular still counts as singular
+	Matrix_b := Matrix{{1, 2, 3}, {2, 4, 6 + 1e-13}, {0, 1, 1}}
+	_, err = Matrix_b.Inverse()
+	assert.ErrorIs(t, err, ErrSingularMatrix)
+	assert.False(t, IsMatrixInvertableBasedOnDeterminant(math.NaN()))
+
+	// ... but small entries alone do not
+	Matrix_c := ScalingMatrix(1e-4, 1e-4, 1e-4)
+	Matrix_c_inv, err := Matrix_c.Inverse()
+	assert.Nil(t, err)
+	testApproxEqualMatrix(t, ScalingMatrix(1e4, 1e4, 1e4), Matrix_c_inv, 0.000001)
+	assert.True(t, IsMatrixInvertableBasedOnDeterminant(1e-13))
+}
+
+func TestMatrixInverse4x4(t *testing.T) {
+	Matrix_a := Matrix{{8, -5, 9, 2}, {7, 5, 6, 1}, {-6, 0, 9, 6}, {-3, 0, -9, -4}}
+	Matrix_a_inv, err := Matrix_a.Inverse()
+	assert.Nil(t, err)
+
+	expected := Matrix{
+		{-0.15385, -0.15385, -0.28205, -0.53846},
+		{-0.07692, 0.12308, 0.02564, 0.03077},
+		{0.35897, 0.35897, 0.43590, 0.92308},
+		{-0.69231, -0.69231, -0.76923, -1.92308},
+	}
+	testApproxEqualMatrix(t, expected, Matrix_a_inv, 0.00001)
+}
+
+func TestMatrixSolve(t *testing.T) {
+	Matrix_a := Matrix{{2, 1, -1}, {-3, -1, 2}, {-2, 1, 2}}
+	b := Matrix{{8, 1}, {-11, 0}, {-3, 0}}
+
+	x, err := Matrix_a.Solve(b)
+	assert.Nil(t, err)
+
+	res, _ := Matrix_a.Multiply(x)
+	testApproxEqualMatrix(t, b, res, 0.000001)
+	testApproxEqualMatrix(t, Matrix{{2}, {3}, {-1}}, Matrix{{x[0][0]}, {x[1][0]}, {x[2][0]}}, 0.000001)
+
+	_, err = Matrix_a.Solve(NewMatrix(2, 1))
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,9 @@
 package matrices
 
-import "errors"
+import (
+	"errors"
+	"math"
+)
 
 type Matrix [][]float64
 
@@ -128,33 +131,39 @@ func (m Matrix) SubMatrix(r_t, c_t int) Matrix {
 	return _matrix
 }
 
+/*
+Small matrices use their closed form, anything larger goes through an LU decomposition.
+A singular matrix has a determinant of 0.
+*/
 func (m Matrix) Determinant() (float64, error) {
 	if m.Column() != m.Row() {
 		return 0, errors.New("NA")
 	}
 
-	n := m.Row()
-
-	switch n {
+	switch m.Row() {
 	case 0:
 		return 1, nil
 	case 1:
 		return m[0][0], nil
 	case 2:
 		return m[0][0]*m[1][1] - m[0][1]*m[1][0], nil
+	case 3:
+		// expanded along the first row, which keeps integer minors exact
+		return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0]), nil
 	}
 
-	res := 0.0
-
-	for i := range n {
-		d, _ := m.SubMatrix(0, i).Determinant()
-		row_val := m[0][i] * d
-
-		row_val = fetchMatrixPositionalSign(0, i) * row_val
-		res += row_val
+	// no tolerance here, a tiny determinant is still the determinant; only an exactly zero pivot makes it 0
+	d, err := m.LUDecompose()
+	if errors.Is(err, ErrSingularMatrix) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
 	}
 
-	return res, nil
+	return d.Determinant(), nil
 }
 
 func (m Matrix) Minor(i, j int) float64 {
@@ -175,15 +184,15 @@ func fetchMatrixPositionalSign(row, col int) float64 {
 	return -1
 }
 
+/*
+Returns ErrSingularMatrix instead of a matrix full of Inf/NaN when m cannot be inverted
+*/
 func (m Matrix) Inverse() (Matrix, error) {
-
-	adj_mat, err := m.Adj()
-	if err != nil {
-		return nil, err
+	if m.Row() != m.Column() {
+		return nil, errors.New("pseudo inverse not supported currently")
 	}
 
-	det, _ := m.Determinant()
-	return adj_mat.ScaleMul(1 / det), nil
+	return m.Solve(NewIdentityMatrix(m.Row()))
 }
 
 func (m Matrix) Adj() (Matrix, error) {
@@ -202,8 +211,14 @@ func (m Matrix) Adj() (Matrix, error) {
 	return _matrix.T(), nil
 }
 
+/*
+Only rules out determinants that are exactly zero or not finite.
+
+Deprecated: whether a small determinant means "nearly singular" depends on the size of the entries,
+which the determinant alone does not know. Check the error of Inverse, or LU.IsNearlySingular, instead.
+*/
 func IsMatrixInvertableBasedOnDeterminant(val float64) bool {
-	return val != 0
+	return val != 0 && !math.IsInf(val, 0) && !math.IsNaN(val)
 }
 
 func NewMatrix(r, c int) Matrix {
@@ -2,11 +2,9 @@ package observe
 
 import (
 	"math"
-	"rattata/canvas"
 	"rattata/coordinates"
 	"rattata/matrices"
 	"rattata/rays"
-	"sync"
 )
 
 type Camera struct {
@@ -14,22 +12,40 @@ type Camera struct {
 	Vsize            uint32
 	FOV              float64
 	Transform_Matrix matrices.Matrix
+	inverse_matrix   matrices.Mat4
 	half_width       float64
 	half_height      float64
+	Sampling         Sampling
+
+	// Radius of the lens; 0 keeps the camera a pinhole with everything in focus
+	Aperture float64
+	// Distance along the view axis at which objects are in perfect focus
+	FocalDistance float64
+	// nil means PerspectiveProjection
+	Projection Projection
 }
 
 func CreateNewCamera(hsize, vsize uint32, view_size float64) Camera {
-	_c := Camera{Hsize: hsize, Vsize: vsize, FOV: view_size, Transform_Matrix: matrices.NewIdentityMatrix(4)}
+	_c := Camera{Hsize: hsize, Vsize: vsize, FOV: view_size, Transform_Matrix: matrices.NewIdentityMatrix(4), inverse_matrix: matrices.NewIdentityMat4(), FocalDistance: 1}
 	_c.GetPixelSize()
 
 	return _c
 }
 
 func (c *Camera) SetTransformationMatrix(m matrices.Matrix) Camera {
+	c.inverse_matrix = matrices.MustInvertTransformation(m)
 	c.Transform_Matrix = m
 	return *c
 }
 
+/*
+Changes the size of the image in pixels, the field of view stays the same
+*/
+func (c *Camera) Resize(hsize, vsize uint32) {
+	c.Hsize, c.Vsize = hsize, vsize
+	c.GetPixelSize()
+}
+
 func (c *Camera) GetPixelSize() float64 {
 	half_view := math.Tan(c.FOV / 2)
 	aspect_ratio := float64(c.Hsize) / float64(c.Vsize)
@@ -45,71 +61,66 @@ func (c *Camera) GetPixelSize() float64 {
 	return (c.half_width * 2) / float64(c.Hsize)
 }
 
-func (c *Camera) RayForPixel(px, py int) rays.Ray {
-	dist_per_pix_size := c.GetPixelSize()
-	transform_inv_matrix, _ := c.Transform_Matrix.Inverse()
+func (c *Camera) SetProjection(p Projection) {
+	c.Projection = p
+}
 
-	xoffset := (0.5 + float64(px)) * dist_per_pix_size
-	yoffset := (0.5 + float64(py)) * dist_per_pix_size
+func (c *Camera) projection() Projection {
+	if c.Projection == nil {
+		return PerspectiveProjection{}
+	}
+	return c.Projection
+}
 
-	world_x := c.half_width - xoffset
-	world_y := c.half_height - yoffset
+func (c *Camera) RayForPixel(px, py int) rays.Ray {
+	return c.RayForPixelOffset(px, py, 0.5, 0.5)
+}
 
-	pixel, _ := transform_inv_matrix.Multiply(matrices.CoordinateToMatrix(coordinates.CreatePoint(world_x, world_y, -1)))
-	origin, _ := transform_inv_matrix.Multiply(matrices.CoordinateToMatrix(coordinates.CreatePoint(0, 0, 0)))
+/*
+Ray through the point (ox, oy) of pixel (px, py), where both offsets run from 0 (left/top edge) to 1 (right/bottom edge)
+*/
+func (c *Camera) RayForPixelOffset(px, py int, ox, oy float64) rays.Ray {
+	transform_inv_matrix := c.inverse_matrix
 
-	pixel_point := matrices.MatrixToCoordinate(pixel)
-	origin_point := matrices.MatrixToCoordinate(origin)
+	origin, through := c.projection().CameraRay(c, ox+float64(px), oy+float64(py))
+
+	pixel_point := transform_inv_matrix.MulPoint(through)
+	origin_point := transform_inv_matrix.MulPoint(origin)
 	direction := *pixel_point.Sub(&origin_point).Norm()
 
 	return rays.NewRay(origin_point, direction)
 }
 
-func Render(cam Camera, world World) canvas.Canvas {
-	my_canvas := canvas.CreateCanvas(cam.Hsize, cam.Vsize)
-
-	for py := 0; py < my_canvas.GetHeight(); py++ {
-		for px := 0; px < my_canvas.GetWidth(); px++ {
-			_ray := cam.RayForPixel(px, py)
-			c := world.Color_At(_ray, rays.REC_LIMIT)
-			my_canvas.WritePixel(uint32(px), uint32(py), canvas.RayColorToCanvasColor(c))
-		}
-	}
-
-	return my_canvas
-}
-
-func RenderParaller(cam Camera, world World, parallel_count int) canvas.Canvas {
-	my_canvas := canvas.CreateCanvas(cam.Hsize, cam.Vsize)
+/*
+Thin lens model: rays start from the point (lu, lv) of the lens disk, both running from 0 to 1,
+and pass through the spot where the pinhole ray of the pixel meets the focal plane.
+The lens always uses the perspective projection.
+*/
+func (c *Camera) RayThroughLens(px, py int, ox, oy, lu, lv float64) rays.Ray {
+	dist_per_pix_size := c.GetPixelSize()
 
-	data_chan := make(chan [2]int, parallel_count)
+	world_x := c.half_width - (ox+float64(px))*dist_per_pix_size
+	world_y := c.half_height - (oy+float64(py))*dist_per_pix_size
 
-	wg := sync.WaitGroup{}
+	focal_point := coordinates.CreatePoint(world_x*c.FocalDistance, world_y*c.FocalDistance, -c.FocalDistance)
 
-	point_render_work := func(data_stream <-chan [2]int, _cam Camera, _world World, _canvas canvas.Canvas) {
-		wg.Add(1)
-		for data := range data_stream {
-			py, px := data[0], data[1]
-			_ray := _cam.RayForPixel(px, py)
-			c := _world.Color_At(_ray, rays.REC_LIMIT)
-			_canvas.WritePixel(uint32(px), uint32(py), canvas.RayColorToCanvasColor(c))
-		}
+	r, theta := c.Aperture*math.Sqrt(lu), 2*math.Pi*lv
+	lens_point := coordinates.CreatePoint(r*math.Cos(theta), r*math.Sin(theta), 0)
 
-		wg.Done()
-	}
+	focal_point = c.inverse_matrix.MulPoint(focal_point)
+	origin_point := c.inverse_matrix.MulPoint(lens_point)
+	direction := *focal_point.Sub(&origin_point).Norm()
 
-	for range parallel_count {
-		go point_render_work(data_chan, cam, world, my_canvas)
-	}
-
-	for y := 0; y < my_canvas.GetHeight(); y++ {
-		for x := 0; x < my_canvas.GetWidth(); x++ {
-			data_chan <- [2]int{y, x}
-		}
-	}
+	return rays.NewRay(origin_point, direction)
+}
 
-	close(data_chan)
-	wg.Wait()
+/*
+Moves the focal plane so that point is in perfect focus
+*/
+func (c *Camera) SetFocus(point coordinates.Coordinate) {
+	origin := c.inverse_matrix.MulPoint(coordinates.CreatePoint(0, 0, 0))
+	forward := c.inverse_matrix.MulVector(coordinates.CreateVector(0, 0, -1))
 
-	return my_canvas
+	to_point := point.Sub(&origin)
+	c.FocalDistance = to_point.DotP(forward.Norm())
 }
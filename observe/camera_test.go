@@ -6,6 +6,8 @@ import (
 	"rattata/helpers"
 	"rattata/matrices"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestUnitPerPixelH(t *testing.T) {
@@ -20,6 +22,16 @@ func TestUnitPerPixelV(t *testing.T) {
 	helpers.ApproxEqual(t, 0.01, _c.GetPixelSize(), 0.0001)
 }
 
+func TestResizeKeepsFieldOfView(t *testing.T) {
+	_c := CreateNewCamera(10, 10, math.Pi/2)
+	_c.Resize(200, 125)
+
+	assert.Equal(t, uint32(200), _c.Hsize)
+	helpers.ApproxEqual(t, 0.01, _c.GetPixelSize(), 0.0001)
+	expected := CreateNewCamera(200, 125, math.Pi/2)
+	helpers.TestApproxEqualCoordinate(t, expected.RayForPixel(0, 0).Direction, _c.RayForPixel(0, 0).Direction, 0.0001)
+}
+
 func TestRayThroughCenter(t *testing.T) {
 	_c := CreateNewCamera(201, 101, math.Pi/2)
 	r := _c.RayForPixel(100, 50)
@@ -52,3 +64,51 @@ func TestRayWhenCamTransform(t *testing.T) {
 	helpers.TestApproxEqualCoordinate(t, coordinates.CreatePoint(0, 2, -5), r.Origin, 0.0001)
 	helpers.TestApproxEqualCoordinate(t, coordinates.CreateVector(math.Sqrt(2)/2, 0, -math.Sqrt(2)/2), r.Direction, 0.0001)
 }
+
+func TestRayAfterCamTransformReplaced(t *testing.T) {
+
+	_c := CreateNewCamera(201, 101, math.Pi/2)
+	_c.SetTransformationMatrix(matrices.TranslationMatrix(4, 4, 4))
+	_c.SetTransformationMatrix(matrices.TranslationMatrix(0, -2, 5))
+
+	r := _c.RayForPixel(100, 50)
+
+	helpers.TestApproxEqualCoordinate(t, coordinates.CreatePoint(0, 2, -5), r.Origin, 0.0001)
+	helpers.TestApproxEqualCoordinate(t, coordinates.CreateVector(0, 0, -1), r.Direction, 0.0001)
+}
+
+func TestPinholeLensRay(t *testing.T) {
+	_c := CreateNewCamera(201, 101, math.Pi/2)
+	expected := _c.RayForPixelOffset(20, 30, 0.25, 0.75)
+	r := _c.RayThroughLens(20, 30, 0.25, 0.75, 0.6, 0.3)
+
+	helpers.TestApproxEqualCoordinate(t, expected.Origin, r.Origin, 0.0001)
+	helpers.TestApproxEqualCoordinate(t, expected.Direction, r.Direction, 0.0001)
+}
+
+func TestLensRaysMeetOnFocalPlane(t *testing.T) {
+	_c := CreateNewCamera(201, 101, math.Pi/2)
+	_c.Aperture, _c.FocalDistance = 0.5, 4
+	pinhole := _c.RayForPixel(20, 30)
+	focus := pinhole.PointAtTime(-4 / pinhole.Direction.Get(coordinates.Z))
+
+	for _, lens := range [][2]float64{{0, 0}, {0.5, 0.5}, {1, 0.25}, {0.3, 0.9}} {
+		r := _c.RayThroughLens(20, 30, 0.5, 0.5, lens[0], lens[1])
+		assert.True(t, r.Origin.Magnitude() <= 0.5+0.0001)
+
+		p := r.PointAtTime(-4 / r.Direction.Get(coordinates.Z))
+		helpers.TestApproxEqualCoordinate(t, *focus, *p, 0.0001)
+	}
+}
+
+func TestSetFocus(t *testing.T) {
+	_c := CreateNewCamera(201, 101, math.Pi/2)
+	_c.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(0, 0, -5), coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(0, 1, 0)))
+
+	_c.SetFocus(coordinates.CreatePoint(0, 0, 0))
+	helpers.ApproxEqual(t, 5, _c.FocalDistance, 0.0001)
+
+	// only the distance along the view axis counts
+	_c.SetFocus(coordinates.CreatePoint(3, -2, 1))
+	helpers.ApproxEqual(t, 6, _c.FocalDistance, 0.0001)
+}
@@ -0,0 +1,472 @@
+package observe
+
+import (
+	"context"
+	"fmt"
+	"rattata/canvas"
+	"rattata/coordinates"
+	"rattata/features/steps"
+	"rattata/matrices"
+	"rattata/rays"
+	"strconv"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+// ---------------------------------- Scenario state ----------------------------------
+
+// Everything a scenario has named so far; there is a single world, camera and image per scenario
+type featureState struct {
+	*steps.State
+	shapes       map[string]rays.Shape
+	world        World
+	camera       Camera
+	image        canvas.HDRCanvas
+	intersection rays.Intersection
+	xs           []rays.Intersection
+	comps        PreCompData
+}
+
+type featureStateKey struct{}
+
+func newFeatureState() *featureState {
+	return &featureState{
+		State:  steps.NewState(),
+		shapes: make(map[string]rays.Shape),
+	}
+}
+
+func stateOf(ctx context.Context) *featureState {
+	return ctx.Value(featureStateKey{}).(*featureState)
+}
+
+func (st *featureState) shape(name string) (rays.Shape, error) {
+	shp, isKnown := st.shapes[name]
+	if !isKnown {
+		return nil, fmt.Errorf("no shape called %q", name)
+	}
+	return shp, nil
+}
+
+// ---------------------------------- Shapes ----------------------------------
+
+func givenShape(ctx context.Context, name, kind string) error {
+	var shp rays.Shape
+	switch kind {
+	case "sphere":
+		sph := rays.NewCenteredSphere()
+		shp = &sph
+	case "plane":
+		pl := rays.NewPlane(coordinates.CreatePoint(0, 0, 0))
+		shp = &pl
+	default:
+		return fmt.Errorf("unknown shape %q", kind)
+	}
+
+	stateOf(ctx).shapes[name] = shp
+	return nil
+}
+
+func setTransform(ctx context.Context, name, matrix_expr string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(name)
+	if err != nil {
+		return err
+	}
+	mt, err := st.Matrix(matrix_expr)
+	if err != nil {
+		return err
+	}
+
+	transformable, isTransformable := shp.(interface{ SetTransformation(matrices.Matrix) })
+	if !isTransformable {
+		return fmt.Errorf("%s cannot be transformed", name)
+	}
+	transformable.SetTransformation(mt)
+	return nil
+}
+
+func setMaterial(mat *rays.Material, field string, val float64) error {
+	switch field {
+	case "ambient":
+		mat.Ambient = val
+	case "reflective":
+		mat.Reflective = val
+	default:
+		return fmt.Errorf("unknown material field %q", field)
+	}
+	return nil
+}
+
+// Sets one field of the material of shp, handing back the shape to store in place of shp
+func withMaterialField(shp rays.Shape, field string, val float64) (rays.Shape, error) {
+	switch s := shp.(type) {
+	case rays.Sphere:
+		// the default world holds its spheres by value
+		err := setMaterial(&s.Material, field, val)
+		return s, err
+	case *rays.Sphere:
+		return s, setMaterial(&s.Material, field, val)
+	case *rays.XZPlane:
+		return s, setMaterial(&s.Material, field, val)
+	}
+	return nil, fmt.Errorf("cannot change the material of a %s", rays.ShapeTypeName(shp))
+}
+
+func setMaterialField(ctx context.Context, name, field, expr string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(name)
+	if err != nil {
+		return err
+	}
+	val, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+
+	_, err = withMaterialField(shp, field, val)
+	return err
+}
+
+// ---------------------------------- World ----------------------------------
+
+func givenDefaultWorld(ctx context.Context) error {
+	stateOf(ctx).world = NewDefaultWorld()
+	return nil
+}
+
+func checkWorldLightCount(ctx context.Context, count int) error {
+	if lights := stateOf(ctx).world.Lights(); len(lights) != count {
+		return fmt.Errorf("expected %d lights, got %d", count, len(lights))
+	}
+	return nil
+}
+
+func checkWorldObjectCount(ctx context.Context, count int) error {
+	if objects := stateOf(ctx).world.ListObjects(); len(objects) != count {
+		return fmt.Errorf("expected %d objects, got %d", count, len(objects))
+	}
+	return nil
+}
+
+func setWorldObjectMaterialField(ctx context.Context, idx int, field, expr string) error {
+	st := stateOf(ctx)
+	if idx >= len(st.world.ListObjects()) {
+		return fmt.Errorf("the world has only %d objects", len(st.world.ListObjects()))
+	}
+	val, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+
+	var modify_err error
+	st.world.PerformObjectModifications(idx, func(obj rays.Shape) rays.Shape {
+		res, err := withMaterialField(obj, field, val)
+		if err != nil {
+			modify_err = err
+			return obj
+		}
+		return res
+	})
+	return modify_err
+}
+
+func addLightAgain(ctx context.Context) error {
+	st := stateOf(ctx)
+	light := st.world.LightSource()
+	if light == nil {
+		return fmt.Errorf("the world has no light")
+	}
+	st.world.AddLight(*light)
+	return nil
+}
+
+func removeLights(ctx context.Context) error {
+	stateOf(ctx).world.SetLightSource(nil)
+	return nil
+}
+
+func addShapeToWorld(ctx context.Context, name string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(name)
+	if err != nil {
+		return err
+	}
+	st.world.AddObject(shp)
+	return nil
+}
+
+func intersectWorld(ctx context.Context, ray_name string) error {
+	st := stateOf(ctx)
+	r, err := st.Ray(ray_name)
+	if err != nil {
+		return err
+	}
+	st.xs = st.world.IntersectWithRay(r)
+	return nil
+}
+
+func checkIntersectionCount(ctx context.Context, count int) error {
+	if xs := stateOf(ctx).xs; len(xs) != count {
+		return fmt.Errorf("expected %d intersections, got %d", count, len(xs))
+	}
+	return nil
+}
+
+func checkNthIntersectionT(ctx context.Context, idx int, expr string) error {
+	xs := stateOf(ctx).xs
+	if idx >= len(xs) {
+		return fmt.Errorf("there are only %d intersections", len(xs))
+	}
+	expected, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+	return steps.ApproxEqualNumber(expected, xs[idx].Tvalue)
+}
+
+func colourAt(ctx context.Context, name, ray_name string) error {
+	st := stateOf(ctx)
+	r, err := st.Ray(ray_name)
+	if err != nil {
+		return err
+	}
+	st.Colours[name] = st.world.Color_At(r, rays.REC_LIMIT)
+	return nil
+}
+
+func checkShadowed(ctx context.Context, point_expr, expected_expr string) error {
+	st := stateOf(ctx)
+	point, err := st.Tuple(point_expr)
+	if err != nil {
+		return err
+	}
+	expected, err := strconv.ParseBool(expected_expr)
+	if err != nil {
+		return err
+	}
+
+	light := st.world.LightSource()
+	if light == nil {
+		return fmt.Errorf("the world has no light")
+	}
+	if in_shadow := st.world.IsShadowed(point, *light) == 1; in_shadow != expected {
+		return fmt.Errorf("expected the point to be in shadow: %t, it is: %t", expected, in_shadow)
+	}
+	return nil
+}
+
+// ---------------------------------- Precomputations ----------------------------------
+
+func givenIntersection(ctx context.Context, t_expr, shape_name string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+	t, err := steps.ParseNumber(t_expr)
+	if err != nil {
+		return err
+	}
+
+	st.intersection = rays.NewIntersection(t, shp)
+	return nil
+}
+
+func prepareComputations(ctx context.Context, ray_name string) error {
+	st := stateOf(ctx)
+	r, err := st.Ray(ray_name)
+	if err != nil {
+		return err
+	}
+	st.comps = PreparePrecompData(st.intersection, r, []rays.Intersection{st.intersection})
+	return nil
+}
+
+func checkCompsT(ctx context.Context, expr string) error {
+	expected, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+	return steps.ApproxEqualNumber(expected, stateOf(ctx).comps.Tvalue)
+}
+
+func checkCompsTuple(ctx context.Context, field, expr string) error {
+	st := stateOf(ctx)
+	expected, err := st.Tuple(expr)
+	if err != nil {
+		return err
+	}
+
+	actual := map[string]coordinates.Coordinate{"point": st.comps.Point, "eyev": st.comps.EyeVector, "normalv": st.comps.NormalVector}[field]
+	return steps.ApproxEqualTuple(expected, actual)
+}
+
+func checkCompsInside(ctx context.Context, expr string) error {
+	expected, err := strconv.ParseBool(expr)
+	if err != nil {
+		return err
+	}
+	if inside := stateOf(ctx).comps.EyeInsideShape; inside != expected {
+		return fmt.Errorf("expected inside to be %t, got %t", expected, inside)
+	}
+	return nil
+}
+
+// The offset points sit just off the surface along the normal, which points to -z in these scenarios
+func checkCompsOffset(ctx context.Context, field, side string) error {
+	comps := stateOf(ctx).comps
+	offset := map[string]coordinates.Coordinate{"over_point": comps.OverPoint, "under_point": comps.UnderPoint}[field]
+	z, surface_z := offset.Get(coordinates.Z), comps.Point.Get(coordinates.Z)
+
+	if side == "above" && !(z < surface_z-rays.EPSILON/2) || side == "below" && !(z > surface_z+rays.EPSILON/2) {
+		return fmt.Errorf("%s is not %s the surface: z %v vs %v", field, side, z, surface_z)
+	}
+	return nil
+}
+
+// ---------------------------------- Camera ----------------------------------
+
+func givenCamera(ctx context.Context, hsize, vsize int, fov_expr string) error {
+	fov, err := steps.ParseNumber(fov_expr)
+	if err != nil {
+		return err
+	}
+	stateOf(ctx).camera = CreateNewCamera(uint32(hsize), uint32(vsize), fov)
+	return nil
+}
+
+func checkCameraSize(ctx context.Context, field string, expected int) error {
+	cam := stateOf(ctx).camera
+	actual := map[string]uint32{"hsize": cam.Hsize, "vsize": cam.Vsize}[field]
+	if actual != uint32(expected) {
+		return fmt.Errorf("expected %s %d, got %d", field, expected, actual)
+	}
+	return nil
+}
+
+func checkCameraNumber(ctx context.Context, field, expr string) error {
+	expected, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+
+	cam := &stateOf(ctx).camera
+	actual := map[string]float64{"field_of_view": cam.FOV, "pixel_size": cam.GetPixelSize()}[field]
+	return steps.ApproxEqualNumber(expected, actual)
+}
+
+func checkCameraTransform(ctx context.Context, expr string) error {
+	st := stateOf(ctx)
+	expected, err := st.Matrix(expr)
+	if err != nil {
+		return err
+	}
+	if !expected.IsEqual(st.camera.Transform_Matrix) {
+		return fmt.Errorf("expected transformation %v, got %v", expected, st.camera.Transform_Matrix)
+	}
+	return nil
+}
+
+func setCameraTransform(ctx context.Context, expr string) error {
+	st := stateOf(ctx)
+	mt, err := st.Matrix(expr)
+	if err != nil {
+		return err
+	}
+	st.camera.SetTransformationMatrix(mt)
+	return nil
+}
+
+func resizeCamera(ctx context.Context, hsize, vsize int) error {
+	stateOf(ctx).camera.Resize(uint32(hsize), uint32(vsize))
+	return nil
+}
+
+func rayForPixel(ctx context.Context, name string, px, py int) error {
+	st := stateOf(ctx)
+	st.Rays[name] = st.camera.RayForPixel(px, py)
+	return nil
+}
+
+func renderImage(ctx context.Context) error {
+	st := stateOf(ctx)
+	image, _, err := RenderHDRContext(ctx, st.camera, st.world, RenderOptions{})
+	st.image = image
+	return err
+}
+
+func checkPixel(ctx context.Context, px, py int, expr string) error {
+	st := stateOf(ctx)
+	if px >= st.image.GetWidth() || py >= st.image.GetHeight() {
+		return fmt.Errorf("(%d, %d) is outside the image", px, py)
+	}
+	expected, err := st.Colour(expr)
+	if err != nil {
+		return err
+	}
+
+	actual := st.image.ReadPixel(uint32(px), uint32(py))
+	return steps.ApproxEqualColour(expected, actual)
+}
+
+// ---------------------------------- Suite ----------------------------------
+
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		st := newFeatureState()
+		return context.WithValue(steps.WithState(ctx, st.State), featureStateKey{}, st), nil
+	})
+	steps.InitializeScenario(ctx)
+
+	ctx.Step(`^(\w+) ← (sphere|plane)\(\)$`, givenShape)
+	ctx.Step(`^set_transform\((\w+), `+steps.MATRIX+`\)$`, setTransform)
+	ctx.Step(`^(\w+)\.material\.(ambient|reflective) ← `+steps.NUMBER+`$`, setMaterialField)
+
+	ctx.Step(`^w ← default_world\(\)$`, givenDefaultWorld)
+	ctx.Step(`^w has (\d+) lights?$`, checkWorldLightCount)
+	ctx.Step(`^w has (\d+) objects?$`, checkWorldObjectCount)
+	ctx.Step(`^w\.objects\[(\d+)\]\.material\.(ambient|reflective) ← `+steps.NUMBER+`$`, setWorldObjectMaterialField)
+	ctx.Step(`^the light of w is added to w again$`, addLightAgain)
+	ctx.Step(`^w has its lights removed$`, removeLights)
+	ctx.Step(`^(\w+) is added to w$`, addShapeToWorld)
+	ctx.Step(`^xs ← intersect_world\(w, (\w+)\)$`, intersectWorld)
+	ctx.Step(`^xs\.count = (\d+)$`, checkIntersectionCount)
+	ctx.Step(`^xs\[(\d+)\]\.t = `+steps.NUMBER+`$`, checkNthIntersectionT)
+	ctx.Step(`^(\w+) ← color_at\(w, (\w+)\)$`, colourAt)
+	ctx.Step(`^is_shadowed\(w, `+steps.TUPLE+`\) is (true|false)$`, checkShadowed)
+
+	ctx.Step(`^i ← intersection\(`+steps.NUMBER+`, (\w+)\)$`, givenIntersection)
+	ctx.Step(`^comps ← prepare_computations\(i, (\w+)\)$`, prepareComputations)
+	ctx.Step(`^comps\.t = `+steps.NUMBER+`$`, checkCompsT)
+	ctx.Step(`^comps\.(point|eyev|normalv) = `+steps.TUPLE+`$`, checkCompsTuple)
+	ctx.Step(`^comps\.inside = (true|false)$`, checkCompsInside)
+	ctx.Step(`^comps\.(over_point|under_point) is (above|below) comps\.point$`, checkCompsOffset)
+
+	ctx.Step(`^c ← camera\((\d+), (\d+), `+steps.NUMBER+`\)$`, givenCamera)
+	ctx.Step(`^c\.(hsize|vsize) = (\d+)$`, checkCameraSize)
+	ctx.Step(`^c\.(field_of_view|pixel_size) = `+steps.NUMBER+`$`, checkCameraNumber)
+	ctx.Step(`^c\.transform = `+steps.MATRIX+`$`, checkCameraTransform)
+	ctx.Step(`^c\.transform ← `+steps.MATRIX+`$`, setCameraTransform)
+	ctx.Step(`^c is resized to (\d+) by (\d+)$`, resizeCamera)
+	ctx.Step(`^(\w+) ← ray_for_pixel\(c, (\d+), (\d+)\)$`, rayForPixel)
+	ctx.Step(`^image ← render\(c, w\)$`, renderImage)
+	ctx.Step(`^pixel_at\(image, (\d+), (\d+)\) = `+steps.COLOUR+`$`, checkPixel)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: InitializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"../features/observe"},
+			Strict:   true,
+			TestingT: t, // Testing instance that will run subtests.
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero status returned, failed to run feature tests")
+	}
+}
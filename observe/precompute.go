@@ -82,12 +82,23 @@ func obtainInboundOutboundRefractiveIndices(intersection rays.Intersection, xs [
 	return ri_inbound, ri_outbound
 }
 
-func (pre PreCompData) Shade_Hit(l rays.Light, w World, limit uint) rays.Colour {
+/*
+Sums the surface lighting over every light of the world; a world without lights only gets the ambient part
+*/
+func (pre PreCompData) Shade_Hit(w World, limit uint) rays.Colour {
 	if limit == 0 {
 		return rays.Colour{0, 0, 0}
 	}
 
-	lighting_value := rays.Lighting(pre.Object, l, pre.OverPoint, pre.EyeVector, pre.NormalVector, w.IsShadowed(pre.OverPoint))
+	lighting_value := rays.Colour{0, 0, 0}
+	for _, l := range w.Lights() {
+		lighting_value = lighting_value.Add(
+			rays.Lighting(pre.Object, l, pre.OverPoint, pre.EyeVector, pre.NormalVector, w.IsShadowed(pre.OverPoint, l)))
+	}
+	if len(w.Lights()) == 0 {
+		lighting_value = rays.AmbientLighting(pre.Object, pre.OverPoint)
+	}
+
 	reflected_value := pre.Reflected_Colour(w, limit)
 	refracted_value := pre.Refracted_Colour(w, limit)
 
@@ -95,11 +106,9 @@ func (pre PreCompData) Shade_Hit(l rays.Light, w World, limit uint) rays.Colour
 		reflectance := rays.SchlickReflectiveScore(pre.EyeVector, pre.NormalVector, pre.RI_Inbound, pre.RI_Outbound)
 		transmitive := 1 - reflectance
 
-		return rays.AddColour(rays.AddColour(rays.MulColour(refracted_value, transmitive),
-			rays.MulColour(reflected_value, reflectance)),
-			lighting_value)
+		return refracted_value.Scale(transmitive).Add(reflected_value.Scale(reflectance)).Add(lighting_value)
 	}
-	return rays.AddColour(rays.AddColour(refracted_value, reflected_value), lighting_value)
+	return refracted_value.Add(reflected_value).Add(lighting_value)
 }
 
 func (pre PreCompData) Reflected_Colour(w World, limit uint) rays.Colour {
@@ -108,9 +117,9 @@ func (pre PreCompData) Reflected_Colour(w World, limit uint) rays.Colour {
 	}
 
 	reflect_ray := rays.NewRay(pre.OverPoint, pre.ReflectiveVector)
-	color := w.Color_At(reflect_ray, limit-1)
+	color := w.traceRay(reflect_ray, limit-1, reflectionRay)
 
-	return rays.MulColour(color, pre.Object.GetMaterial().Reflective)
+	return color.Scale(pre.Object.GetMaterial().Reflective)
 }
 
 func (pre PreCompData) Refracted_Colour(w World, limit uint) rays.Colour {
@@ -125,7 +134,7 @@ func (pre PreCompData) Refracted_Colour(w World, limit uint) rays.Colour {
 	}
 
 	refract_ray := rays.NewRay(pre.UnderPoint, *direction)
-	color := rays.MulColour(w.Color_At(refract_ray, limit-1), pre.Object.GetMaterial().Transparency)
+	color := w.traceRay(refract_ray, limit-1, refractionRay).Scale(pre.Object.GetMaterial().Transparency)
 
 	return color
 }
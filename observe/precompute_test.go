@@ -82,3 +82,32 @@ func TestPrecompTotalInternalReflection(t *testing.T) {
 	c := pre.Refracted_Colour(w, 2)
 	assert.Equal(t, rays.Colour{0, 0, 0}, c)
 }
+
+func TestPrecompRefractiveIndicesThroughCSG(t *testing.T) {
+	A := rays.NewGlassSphere()
+	A.SetTransformation(matrices.ScalingMatrix(2, 2, 2))
+	A.Material.RefractiveIndex = 1.5
+
+	B := rays.NewGlassSphere()
+	B.Material.RefractiveIndex = 2.0
+
+	csg := rays.NewCSG(rays.CSGUnion, &A, &B)
+
+	w := NewEmptyWorld()
+	w.AddObject(csg)
+
+	r := rays.NewRay(coordinates.CreatePoint(0, 0, -4), coordinates.CreateVector(0, 0, 1))
+	xs := w.IntersectWithRay(r)
+	assert.Equal(t, 2, len(xs))
+
+	assert.Equal(t, A.Id(), xs[0].Obj.Id())
+	assert.Equal(t, A.Id(), xs[1].Obj.Id())
+
+	expected_n1 := []float64{1.0, 1.5}
+	expected_n2 := []float64{1.5, 1.0}
+	for i, intersection := range xs {
+		pre := PreparePrecompData(intersection, r, xs)
+		helpers.ApproxEqual(t, expected_n1[i], pre.RI_Inbound, 0.0001)
+		helpers.ApproxEqual(t, expected_n2[i], pre.RI_Outbound, 0.0001)
+	}
+}
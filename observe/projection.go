@@ -0,0 +1,93 @@
+package observe
+
+import (
+	"math"
+	"rattata/coordinates"
+)
+
+// ---------------------------------- Projection ----------------------------------
+
+/*
+Decides how the image plane maps onto rays.
+
+CameraRay gets the position on the image in pixels, (0, 0) being the top left corner of the image,
+and returns the ray origin plus one more point along the ray, both in camera space:
+the camera sits at the origin looking down -z with +y up.
+*/
+type Projection interface {
+	CameraRay(c *Camera, x, y float64) (origin, through coordinates.Coordinate)
+}
+
+/*
+The classic pinhole projection, spanning the camera's FOV across the wider side of the image
+*/
+type PerspectiveProjection struct{}
+
+func (p PerspectiveProjection) CameraRay(c *Camera, x, y float64) (coordinates.Coordinate, coordinates.Coordinate) {
+	dist_per_pix_size := c.GetPixelSize()
+
+	world_x := c.half_width - x*dist_per_pix_size
+	world_y := c.half_height - y*dist_per_pix_size
+
+	return coordinates.CreatePoint(0, 0, 0), coordinates.CreatePoint(world_x, world_y, -1)
+}
+
+/*
+Parallel rays over a ViewWidth wide window, sizes stay the same whatever the distance
+*/
+type OrthographicProjection struct {
+	ViewWidth float64
+}
+
+func NewOrthographicProjection(view_width float64) OrthographicProjection {
+	return OrthographicProjection{ViewWidth: view_width}
+}
+
+func (p OrthographicProjection) CameraRay(c *Camera, x, y float64) (coordinates.Coordinate, coordinates.Coordinate) {
+	dist_per_pix_size := p.ViewWidth / float64(c.Hsize)
+
+	world_x := p.ViewWidth/2 - x*dist_per_pix_size
+	world_y := dist_per_pix_size*float64(c.Vsize)/2 - y*dist_per_pix_size
+
+	return coordinates.CreatePoint(world_x, world_y, 0), coordinates.CreatePoint(world_x, world_y, -1)
+}
+
+/*
+Equidistant fisheye: the angle from the view axis grows linearly with the distance from the image centre,
+reaching FOV/2 at the edge of the circle fitting the shorter side of the image.
+Corners beyond that circle keep going, so the whole frame is filled.
+*/
+type FisheyeProjection struct {
+	FOV float64
+}
+
+func NewFisheyeProjection(fov float64) FisheyeProjection {
+	return FisheyeProjection{FOV: fov}
+}
+
+func (p FisheyeProjection) CameraRay(c *Camera, x, y float64) (coordinates.Coordinate, coordinates.Coordinate) {
+	radius := float64(min(c.Hsize, c.Vsize)) / 2
+
+	nx := (float64(c.Hsize)/2 - x) / radius
+	ny := (float64(c.Vsize)/2 - y) / radius
+
+	theta := math.Hypot(nx, ny) * p.FOV / 2
+	phi := math.Atan2(ny, nx)
+
+	through := coordinates.CreatePoint(math.Sin(theta)*math.Cos(phi), math.Sin(theta)*math.Sin(phi), -math.Cos(theta))
+	return coordinates.CreatePoint(0, 0, 0), through
+}
+
+/*
+360° x 180° panorama: the image width covers every longitude and the height every latitude,
+with the view direction in the middle of the image
+*/
+type EquirectangularProjection struct{}
+
+func (p EquirectangularProjection) CameraRay(c *Camera, x, y float64) (coordinates.Coordinate, coordinates.Coordinate) {
+	longitude := (x/float64(c.Hsize) - 0.5) * 2 * math.Pi
+	latitude := (0.5 - y/float64(c.Vsize)) * math.Pi
+
+	through := coordinates.CreatePoint(-math.Sin(longitude)*math.Cos(latitude), math.Sin(latitude), -math.Cos(longitude)*math.Cos(latitude))
+	return coordinates.CreatePoint(0, 0, 0), through
+}
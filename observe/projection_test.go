@@ -0,0 +1,106 @@
+package observe
+
+import (
+	"math"
+	"rattata/coordinates"
+	"rattata/helpers"
+	"rattata/matrices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultProjectionIsPerspective(t *testing.T) {
+	_c := CreateNewCamera(201, 101, math.Pi/2)
+	expected := _c.RayForPixel(0, 0)
+
+	_c.SetProjection(PerspectiveProjection{})
+	assert.Equal(t, expected, _c.RayForPixel(0, 0))
+}
+
+func TestOrthographicRays(t *testing.T) {
+	_c := CreateNewCamera(100, 50, math.Pi/2)
+	_c.SetProjection(NewOrthographicProjection(10))
+
+	for _, data := range []struct {
+		px, py int
+		ox, oy float64
+		origin coordinates.Coordinate
+	}{
+		{50, 25, 0, 0, coordinates.CreatePoint(0, 0, 0)},
+		{0, 0, 0, 0, coordinates.CreatePoint(5, 2.5, 0)},
+		{99, 49, 1, 1, coordinates.CreatePoint(-5, -2.5, 0)},
+	} {
+		r := _c.RayForPixelOffset(data.px, data.py, data.ox, data.oy)
+		helpers.TestApproxEqualCoordinate(t, data.origin, r.Origin, 0.0001)
+		helpers.TestApproxEqualCoordinate(t, coordinates.CreateVector(0, 0, -1), r.Direction, 0.0001)
+	}
+}
+
+func TestOrthographicSizeIgnoresDistance(t *testing.T) {
+	w := flatSphereWorld()
+
+	for _, distance := range []float64{5, 50} {
+		_c := CreateNewCamera(40, 40, math.Pi/2)
+		_c.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(0, 0, -distance), coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(0, 1, 0)))
+		_c.SetProjection(NewOrthographicProjection(4))
+
+		// the unit sphere spans pixels 10 to 29 whatever the distance
+		assert.Equal(t, 1.0, _c.PixelColour(w, 11, 20)[0])
+		assert.Equal(t, 1.0, _c.PixelColour(w, 28, 20)[0])
+		assert.Equal(t, 0.0, _c.PixelColour(w, 8, 20)[0])
+		assert.Equal(t, 0.0, _c.PixelColour(w, 31, 20)[0])
+	}
+}
+
+func TestFisheyeRays(t *testing.T) {
+	_c := CreateNewCamera(100, 100, math.Pi/2)
+	_c.SetProjection(NewFisheyeProjection(math.Pi))
+
+	for _, data := range []struct {
+		x, y      float64
+		direction coordinates.Coordinate
+	}{
+		{50, 50, coordinates.CreateVector(0, 0, -1)},
+		{0, 50, coordinates.CreateVector(1, 0, 0)},
+		{50, 0, coordinates.CreateVector(0, 1, 0)},
+		{25, 50, coordinates.CreateVector(math.Sqrt(2)/2, 0, -math.Sqrt(2)/2)},
+	} {
+		r := _c.RayForPixelOffset(0, 0, data.x, data.y)
+		helpers.TestApproxEqualCoordinate(t, coordinates.CreatePoint(0, 0, 0), r.Origin, 0.0001)
+		helpers.TestApproxEqualCoordinate(t, data.direction, r.Direction, 0.0001)
+	}
+}
+
+func TestEquirectangularRays(t *testing.T) {
+	_c := CreateNewCamera(200, 100, math.Pi/2)
+	_c.SetProjection(EquirectangularProjection{})
+
+	for _, data := range []struct {
+		x, y      float64
+		direction coordinates.Coordinate
+	}{
+		{100, 50, coordinates.CreateVector(0, 0, -1)},
+		{150, 50, coordinates.CreateVector(-1, 0, 0)},
+		{50, 50, coordinates.CreateVector(1, 0, 0)},
+		{0, 50, coordinates.CreateVector(0, 0, 1)},
+		{100, 0, coordinates.CreateVector(0, 1, 0)},
+	} {
+		r := _c.RayForPixelOffset(0, 0, data.x, data.y)
+		helpers.TestApproxEqualCoordinate(t, data.direction, r.Direction, 0.0001)
+	}
+}
+
+func TestRenderWithProjections(t *testing.T) {
+	w := flatSphereWorld()
+
+	for _, p := range []Projection{NewOrthographicProjection(4), NewFisheyeProjection(math.Pi / 2), EquirectangularProjection{}} {
+		_c := CreateNewCamera(20, 10, math.Pi/2)
+		_c.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(0, 0, -3), coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(0, 1, 0)))
+		_c.SetProjection(p)
+
+		img := Render(_c, w)
+		assert.Equal(t, img, RenderParaller(_c, w, 3))
+		assert.NotEqual(t, uint8(0), img.ReadPixel(10, 5).Colour[0], "%T", p)
+	}
+}
@@ -0,0 +1,174 @@
+package observe
+
+import (
+	"context"
+	"rattata/canvas"
+	"rattata/rays"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ---------------------------------- Rendering ----------------------------------
+const DEFAULT_TILE_SIZE = 16
+
+type RenderOptions struct {
+	Workers  int // 0 picks runtime.NumCPU()
+	TileSize int // side of the square tiles handed to workers, 0 picks DEFAULT_TILE_SIZE
+	// How many surfaces a camera ray may shade, its own hit plus every reflection and refraction after it.
+	// 1 turns reflection and refraction off, 0 picks rays.REC_LIMIT
+	RecursionLimit uint
+
+	// Called after every finished tile. Calls never overlap, so the callback does not need to be thread safe
+	Progress func(RenderProgress)
+}
+
+type RenderProgress struct {
+	TilesDone   int
+	TilesTotal  int
+	PixelsDone  int
+	PixelsTotal int
+	Elapsed     time.Duration
+	ETA         time.Duration // extrapolated from the pixels done so far
+}
+
+func (p RenderProgress) Fraction() float64 {
+	if p.PixelsTotal == 0 {
+		return 1
+	}
+	return float64(p.PixelsDone) / float64(p.PixelsTotal)
+}
+
+type tile struct {
+	x0, y0, x1, y1 int
+}
+
+func Render(cam Camera, world World) canvas.Canvas {
+	my_canvas := canvas.CreateCanvas(cam.Hsize, cam.Vsize)
+
+	for py := 0; py < my_canvas.GetHeight(); py++ {
+		for px := 0; px < my_canvas.GetWidth(); px++ {
+			c := cam.PixelColour(world, px, py)
+			my_canvas.WritePixel(uint32(px), uint32(py), canvas.RayColorToCanvasColor(c))
+		}
+	}
+
+	return my_canvas
+}
+
+func RenderParaller(cam Camera, world World, parallel_count int) canvas.Canvas {
+	my_canvas, _, _ := RenderContext(context.Background(), cam, world, RenderOptions{Workers: parallel_count})
+	return my_canvas
+}
+
+/*
+Renders the image in square tiles spread over a pool of workers. The output is identical to Render.
+
+Once ctx is done no new tiles are started and the partially rendered canvas is returned together with ctx.Err();
+tiles that never got rendered stay black. Stats always cover the work that was actually done.
+*/
+func RenderContext(ctx context.Context, cam Camera, world World, opts RenderOptions) (canvas.Canvas, RenderStats, error) {
+	hdr_canvas, stats, err := RenderHDRContext(ctx, cam, world, opts)
+	return hdr_canvas.ToneMap(canvas.ToneMapOptions{}), stats, err
+}
+
+/*
+Same as RenderContext, but keeps the colours unclamped so that they can be tone mapped or saved as PFM afterwards
+*/
+func RenderHDRContext(ctx context.Context, cam Camera, world World, opts RenderOptions) (canvas.HDRCanvas, RenderStats, error) {
+	start := time.Now()
+	my_canvas := canvas.CreateHDRCanvas(cam.Hsize, cam.Vsize)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	tile_size := opts.TileSize
+	if tile_size <= 0 {
+		tile_size = DEFAULT_TILE_SIZE
+	}
+
+	// group bounds are cached; recompute them up front so edited shapes are picked up and workers only ever read them
+	for _, obj := range world.tracedObjects() {
+		rays.RefreshBounds(obj)
+	}
+
+	tiles := splitIntoTiles(my_canvas.GetWidth(), my_canvas.GetHeight(), tile_size)
+	tile_chan := make(chan tile, len(tiles))
+	for _, t := range tiles {
+		tile_chan <- t
+	}
+	close(tile_chan)
+
+	progress := RenderProgress{TilesTotal: len(tiles), PixelsTotal: my_canvas.GetWidth() * my_canvas.GetHeight()}
+	progress_lock := sync.Mutex{}
+
+	worker_stats := make([]*RenderStats, workers)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+
+	for i := range workers {
+		worker_stats[i] = newRenderStats()
+
+		// every worker gets its own copy of the camera, as computing pixel sizes writes to it,
+		// and of the world, so that it counts into its own stats and traces as deep as asked
+		_world := world
+		_world.stats = worker_stats[i]
+		_world.recLimit = opts.RecursionLimit
+
+		go func(_cam Camera) {
+			defer wg.Done()
+
+			for t := range tile_chan {
+				if ctx.Err() != nil {
+					return
+				}
+				renderTile(&_cam, _world, my_canvas, t)
+
+				if opts.Progress != nil {
+					progress_lock.Lock()
+					progress.tileDone(t, time.Since(start))
+					opts.Progress(progress)
+					progress_lock.Unlock()
+				}
+			}
+		}(cam)
+	}
+
+	wg.Wait()
+
+	stats := newRenderStats()
+	for _, ws := range worker_stats {
+		stats.merge(ws)
+	}
+	stats.WallTime = time.Since(start)
+
+	return my_canvas, *stats, ctx.Err()
+}
+
+func (p *RenderProgress) tileDone(t tile, elapsed time.Duration) {
+	p.TilesDone++
+	p.PixelsDone += (t.x1 - t.x0) * (t.y1 - t.y0)
+	p.Elapsed = elapsed
+	p.ETA = time.Duration(float64(elapsed) * float64(p.PixelsTotal-p.PixelsDone) / float64(p.PixelsDone))
+}
+
+func splitIntoTiles(width, height, tile_size int) []tile {
+	tiles := make([]tile, 0)
+
+	for y := 0; y < height; y += tile_size {
+		for x := 0; x < width; x += tile_size {
+			tiles = append(tiles, tile{x0: x, y0: y, x1: min(x+tile_size, width), y1: min(y+tile_size, height)})
+		}
+	}
+
+	return tiles
+}
+
+func renderTile(cam *Camera, world World, my_canvas canvas.HDRCanvas, t tile) {
+	for py := t.y0; py < t.y1; py++ {
+		for px := t.x0; px < t.x1; px++ {
+			my_canvas.WritePixel(uint32(px), uint32(py), cam.PixelColour(world, px, py))
+		}
+	}
+}
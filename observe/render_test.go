@@ -0,0 +1,159 @@
+package observe
+
+import (
+	"context"
+	"math"
+	"rattata/canvas"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/rays"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderContextMatchesRender(t *testing.T) {
+	w := NewDefaultWorld()
+	_c := samplingTestCamera()
+
+	expected := Render(_c, w)
+
+	for _, opts := range []RenderOptions{{}, {Workers: 1}, {Workers: 3, TileSize: 7}, {Workers: 64, TileSize: 1}} {
+		img, _, err := RenderContext(context.Background(), _c, w, opts)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, img)
+	}
+}
+
+func TestRenderContextMatchesRenderWithSampling(t *testing.T) {
+	w := NewDefaultWorld()
+	_c := samplingTestCamera()
+	_c.SetSampling(NewJitteredSampling(2, 7))
+	_c.Aperture = 0.1
+	_c.FocalDistance = 4
+
+	img, _, err := RenderContext(context.Background(), _c, w, RenderOptions{Workers: 4, TileSize: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, Render(_c, w), img)
+}
+
+func TestRenderContextCancelled(t *testing.T) {
+	w := NewDefaultWorld()
+	_c := samplingTestCamera()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	img, _, err := RenderContext(ctx, _c, w, RenderOptions{Workers: 2})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int(_c.Hsize), img.GetWidth())
+	assert.Equal(t, int(_c.Vsize), img.GetHeight())
+	assert.Equal(t, canvas.CreateCanvas(_c.Hsize, _c.Vsize), img)
+}
+
+func TestSplitIntoTilesCoversCanvas(t *testing.T) {
+	tiles := splitIntoTiles(35, 20, 16)
+
+	assert.Equal(t, 6, len(tiles))
+	assert.Equal(t, tile{x0: 32, y0: 16, x1: 35, y1: 20}, tiles[5])
+
+	covered := 0
+	for _, _t := range tiles {
+		covered += (_t.x1 - _t.x0) * (_t.y1 - _t.y0)
+	}
+	assert.Equal(t, 35*20, covered)
+}
+
+func TestRenderContextStats(t *testing.T) {
+	w := NewDefaultWorld()
+	_c := CreateNewCamera(11, 11, math.Pi/2)
+	_c.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(0, 0, -5), coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(0, 1, 0)))
+
+	_, stats, err := RenderContext(context.Background(), _c, w, RenderOptions{Workers: 3, TileSize: 4})
+	assert.Nil(t, err)
+
+	pixels := uint64(11 * 11)
+	assert.Equal(t, pixels, stats.Rays.Camera)
+	assert.Greater(t, stats.Rays.Shadow, uint64(0))
+	assert.LessOrEqual(t, stats.Rays.Shadow, pixels)
+	assert.Equal(t, uint64(0), stats.Rays.Reflection)
+	assert.Equal(t, uint64(0), stats.Rays.Refraction)
+	assert.Equal(t, uint(0), stats.MaxDepth)
+
+	// every camera and shadow ray is tested against both spheres
+	assert.Equal(t, 2*stats.Rays.Total(), stats.IntersectionTests["Sphere"])
+	assert.Equal(t, stats.IntersectionTests["Sphere"], stats.TotalIntersectionTests())
+	assert.Greater(t, stats.WallTime, time.Duration(0))
+}
+
+func TestRenderContextStatsCountReflections(t *testing.T) {
+	w := NewDefaultWorld()
+	plane := rays.NewPlane(coordinates.CreatePoint(0, -1, 0))
+	plane.Material.Reflective = 0.5
+	plane.SetTransformation(matrices.TranslationMatrix(0, -1, 0))
+	w.AddObject(plane)
+
+	_c := CreateNewCamera(11, 11, math.Pi/2)
+	_c.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(0, 1, -5), coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(0, 1, 0)))
+
+	_, stats, _ := RenderContext(context.Background(), _c, w, RenderOptions{})
+
+	assert.Greater(t, stats.Rays.Reflection, uint64(0))
+	assert.Greater(t, stats.IntersectionTests["XZPlane"], uint64(0))
+	assert.GreaterOrEqual(t, stats.MaxDepth, uint(1))
+	assert.LessOrEqual(t, stats.MaxDepth, rays.REC_LIMIT)
+
+	// a limit of one hit leaves the plane a plain matte surface
+	flat, stats, _ := RenderContext(context.Background(), _c, w, RenderOptions{RecursionLimit: 1})
+	assert.Equal(t, uint64(0), stats.Rays.Reflection)
+	assert.Equal(t, uint(0), stats.MaxDepth)
+
+	matte := NewDefaultWorld()
+	plane.Material.Reflective = 0
+	matte.AddObject(plane)
+	assert.Equal(t, Render(_c, matte), flat)
+}
+
+func TestRenderContextReportsProgress(t *testing.T) {
+	w := NewDefaultWorld()
+	_c := CreateNewCamera(20, 10, math.Pi/2)
+
+	reports := make([]RenderProgress, 0)
+	_, _, err := RenderContext(context.Background(), _c, w, RenderOptions{Workers: 4, TileSize: 8, Progress: func(p RenderProgress) {
+		reports = append(reports, p)
+	}})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 6, len(reports))
+	for i, p := range reports {
+		assert.Equal(t, i+1, p.TilesDone)
+		assert.Equal(t, 6, p.TilesTotal)
+		assert.Equal(t, 200, p.PixelsTotal)
+		if i > 0 {
+			assert.Greater(t, p.PixelsDone, reports[i-1].PixelsDone)
+		}
+	}
+
+	last := reports[len(reports)-1]
+	assert.Equal(t, 200, last.PixelsDone)
+	assert.Equal(t, 1.0, last.Fraction())
+	assert.Equal(t, time.Duration(0), last.ETA)
+}
+
+func TestRenderHDRContextKeepsUnclampedColours(t *testing.T) {
+	w := NewDefaultWorld()
+	w.SetLightSource(nil)
+	w.AddLight(rays.NewLightSource(-10, 10, -10, rays.Colour{4, 4, 4}))
+	_c := samplingTestCamera()
+
+	hdr, _, err := RenderHDRContext(context.Background(), _c, w, RenderOptions{})
+	assert.Nil(t, err)
+
+	centre := hdr.ReadPixel(50, 50)
+	assert.Greater(t, centre[0], 1.0)
+
+	img, _, _ := RenderContext(context.Background(), _c, w, RenderOptions{})
+	assert.Equal(t, img, hdr.ToneMap(canvas.ToneMapOptions{}))
+	assert.Equal(t, Render(_c, w), img)
+}
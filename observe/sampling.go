@@ -0,0 +1,149 @@
+package observe
+
+import (
+	"math"
+	"math/rand/v2"
+	"rattata/rays"
+)
+
+// ---------------------------------- Sampling ----------------------------------
+type SamplingMode uint8
+
+const (
+	SingleSample SamplingMode = iota // one ray through the pixel centre
+	GridSampling
+	JitteredSampling
+	AdaptiveSampling
+)
+
+/*
+How many rays the camera shoots per pixel and where they go.
+
+Grid and jittered sampling shoot Samples x Samples rays per pixel, jittered ones at a random spot inside each cell.
+Adaptive sampling starts with the 4 pixel corners and keeps splitting a square into quarters,
+up to Samples times, as long as its corners differ by more than Threshold in any channel.
+
+Jitter, as well as the lens samples of a camera with an aperture, is driven by Seed and the pixel position only,
+so the same seed always renders the same image.
+*/
+type Sampling struct {
+	Mode      SamplingMode
+	Samples   int
+	Threshold float64
+	Seed      uint64
+}
+
+func NewGridSampling(samples int) Sampling {
+	return Sampling{Mode: GridSampling, Samples: samples}
+}
+
+func NewJitteredSampling(samples int, seed uint64) Sampling {
+	return Sampling{Mode: JitteredSampling, Samples: samples, Seed: seed}
+}
+
+func NewAdaptiveSampling(max_depth int, threshold float64) Sampling {
+	return Sampling{Mode: AdaptiveSampling, Samples: max_depth, Threshold: threshold}
+}
+
+func (c *Camera) SetSampling(s Sampling) {
+	c.Sampling = s
+}
+
+/*
+Colour of pixel (px, py), averaged over every ray the camera's sampling shoots through it
+*/
+func (c *Camera) PixelColour(w World, px, py int) rays.Colour {
+	n := max(1, c.Sampling.Samples)
+	rng := rand.New(rand.NewPCG(c.Sampling.Seed, uint64(py)<<32|uint64(uint32(px))))
+
+	switch c.Sampling.Mode {
+	case GridSampling:
+		return c.gridColour(w, px, py, n, false, rng)
+	case JitteredSampling:
+		return c.gridColour(w, px, py, n, true, rng)
+	case AdaptiveSampling:
+		corners := make(map[[2]float64]rays.Colour)
+		return c.adaptiveColour(w, px, py, 0, 0, 1, n, corners, rng)
+	}
+
+	return w.traceRay(c.sampleRay(px, py, 0.5, 0.5, rng), w.recursionLimit(), cameraRay)
+}
+
+// Pinhole cameras shoot straight through the pixel, lens cameras through a random spot of the lens.
+// Depth of field only exists for the perspective projection.
+func (c *Camera) sampleRay(px, py int, ox, oy float64, rng *rand.Rand) rays.Ray {
+	if _, isPerspective := c.projection().(PerspectiveProjection); c.Aperture <= 0 || !isPerspective {
+		return c.RayForPixelOffset(px, py, ox, oy)
+	}
+	return c.RayThroughLens(px, py, ox, oy, rng.Float64(), rng.Float64())
+}
+
+func (c *Camera) gridColour(w World, px, py, n int, jitter bool, rng *rand.Rand) rays.Colour {
+	res := rays.Colour{0, 0, 0}
+	cell := 1 / float64(n)
+
+	for j := range n {
+		for i := range n {
+			ox, oy := 0.5, 0.5
+			if jitter {
+				ox, oy = rng.Float64(), rng.Float64()
+			}
+
+			r := c.sampleRay(px, py, (float64(i)+ox)*cell, (float64(j)+oy)*cell, rng)
+			res = res.Add(w.traceRay(r, w.recursionLimit(), cameraRay))
+		}
+	}
+
+	return averageColour(res, n*n)
+}
+
+/*
+Samples the square of side size at (x, y) inside the pixel through its corners, splitting it into quarters
+while the corners disagree and depth allows. Corner colours are shared between neighbouring squares.
+*/
+func (c *Camera) adaptiveColour(w World, px, py int, x, y, size float64, depth int, corners map[[2]float64]rays.Colour, rng *rand.Rand) rays.Colour {
+	corner_at := func(ox, oy float64) rays.Colour {
+		key := [2]float64{ox, oy}
+		if col, isKnown := corners[key]; isKnown {
+			return col
+		}
+		col := w.traceRay(c.sampleRay(px, py, ox, oy, rng), w.recursionLimit(), cameraRay)
+		corners[key] = col
+		return col
+	}
+
+	samples := [4]rays.Colour{corner_at(x, y), corner_at(x+size, y), corner_at(x, y+size), corner_at(x+size, y+size)}
+
+	res := rays.Colour{0, 0, 0}
+	for _, s := range samples {
+		res = res.Add(s)
+	}
+	res = averageColour(res, 4)
+
+	if depth == 0 || !coloursDiffer(samples[:], res, c.Sampling.Threshold) {
+		return res
+	}
+
+	half := size / 2
+	res = rays.Colour{0, 0, 0}
+	for _, quarter := range [][2]float64{{x, y}, {x + half, y}, {x, y + half}, {x + half, y + half}} {
+		res = res.Add(c.adaptiveColour(w, px, py, quarter[0], quarter[1], half, depth-1, corners, rng))
+	}
+
+	return averageColour(res, 4)
+}
+
+func coloursDiffer(samples []rays.Colour, mean rays.Colour, threshold float64) bool {
+	for _, s := range samples {
+		for i := range s {
+			if math.Abs(s[i]-mean[i]) > threshold {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func averageColour(sum rays.Colour, n int) rays.Colour {
+	return rays.Colour{sum[0] / float64(n), sum[1] / float64(n), sum[2] / float64(n)}
+}
@@ -0,0 +1,131 @@
+package observe
+
+import (
+	"math"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/rays"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func samplingTestCamera() Camera {
+	cam := CreateNewCamera(101, 101, math.Pi/2)
+	cam.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(0, 0, -5), coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(0, 1, 0)))
+	return cam
+}
+
+// first pixel of the middle row whose centre hits the sphere
+func firstLitPixel(cam Camera, w World) int {
+	for px := range int(cam.Hsize) {
+		if cam.PixelColour(w, px, 50) != (rays.Colour{0, 0, 0}) {
+			return px
+		}
+	}
+	return -1
+}
+
+func TestRayForPixelOffset(t *testing.T) {
+	cam := samplingTestCamera()
+	assert.Equal(t, cam.RayForPixel(30, 70), cam.RayForPixelOffset(30, 70, 0.5, 0.5))
+
+	// the right edge of one pixel is the left edge of the next
+	assert.Equal(t, cam.RayForPixelOffset(31, 70, 0, 0.25), cam.RayForPixelOffset(30, 70, 1, 0.25))
+}
+
+// a single unlit sphere, so every sample is either the sphere colour or black
+func flatSphereWorld() World {
+	w := NewEmptyWorld()
+	s := rays.NewCenteredSphere()
+	s.Material = rays.Material{Ambient: 1, Pattern: rays.NewPlainPattern(rays.Colour{1, 1, 1})}
+	w.AddObject(s)
+	return w
+}
+
+func TestGridSamplingSmoothsEdges(t *testing.T) {
+	w := flatSphereWorld()
+	cam := samplingTestCamera()
+
+	single_centre := cam.PixelColour(w, 50, 50)
+	cam.SetSampling(NewGridSampling(1))
+	assert.Equal(t, single_centre, cam.PixelColour(w, 50, 50))
+
+	blended := 0
+	cam.SetSampling(NewGridSampling(4))
+	for px := range int(cam.Hsize) {
+		c := cam.PixelColour(w, px, 45)
+		assert.True(t, c[0] >= 0 && c[0] <= 1)
+
+		if c[0] > 0 && c[0] < 1 {
+			blended++
+		}
+	}
+	// both edges of the sphere cross the row
+	assert.True(t, blended >= 2, "%d", blended)
+}
+
+func TestJitteredSamplingIsReproducible(t *testing.T) {
+	w := NewDefaultWorld()
+	cam := samplingTestCamera()
+	edge := firstLitPixel(cam, w)
+
+	cam.SetSampling(NewJitteredSampling(3, 42))
+	first := cam.PixelColour(w, edge, 50)
+	assert.Equal(t, first, cam.PixelColour(w, edge, 50))
+
+	other := samplingTestCamera()
+	other.SetSampling(NewJitteredSampling(3, 42))
+	assert.Equal(t, first, other.PixelColour(w, edge, 50))
+
+	other.SetSampling(NewJitteredSampling(3, 7))
+	assert.NotEqual(t, first, other.PixelColour(w, edge, 50))
+}
+
+func TestAdaptiveSamplingOnlyRefinesEdges(t *testing.T) {
+	w := flatSphereWorld()
+	cam := samplingTestCamera()
+	edge := firstLitPixel(cam, w)
+	cam.SetSampling(NewAdaptiveSampling(3, 0.01))
+
+	// flat background stays black, the edge gets blended
+	assert.Equal(t, rays.Colour{0, 0, 0}, cam.PixelColour(w, 0, 50))
+
+	c := cam.PixelColour(w, edge, 50)
+	grid_cam := samplingTestCamera()
+	grid_cam.SetSampling(NewGridSampling(8))
+	expected := grid_cam.PixelColour(w, edge, 50)
+
+	assert.InDelta(t, expected[0], c[0], 0.1)
+	assert.Equal(t, rays.Colour{1, 1, 1}, cam.PixelColour(w, 50, 50))
+}
+
+func TestLensBlurCombinesWithSampling(t *testing.T) {
+	w := flatSphereWorld()
+
+	blended_pixels := func(cam Camera) int {
+		blended := 0
+		for px := range int(cam.Hsize) {
+			if c := cam.PixelColour(w, px, 50); c[0] > 0 && c[0] < 1 {
+				blended++
+			}
+		}
+		return blended
+	}
+
+	sharp := samplingTestCamera()
+	sharp.SetSampling(NewJitteredSampling(4, 1))
+
+	blurred := samplingTestCamera()
+	blurred.SetSampling(NewJitteredSampling(4, 1))
+	blurred.Aperture = 0.5
+	blurred.SetFocus(coordinates.CreatePoint(0, 0, 20))
+
+	assert.Greater(t, blended_pixels(blurred), 2*blended_pixels(sharp))
+
+	again := samplingTestCamera()
+	again.SetSampling(NewJitteredSampling(4, 1))
+	again.Aperture = 0.5
+	again.SetFocus(coordinates.CreatePoint(0, 0, 20))
+	assert.Equal(t, blurred.PixelColour(w, 40, 50), again.PixelColour(w, 40, 50))
+}
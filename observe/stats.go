@@ -0,0 +1,84 @@
+package observe
+
+import (
+	"rattata/rays"
+	"time"
+)
+
+// ---------------------------------- Render Statistics ----------------------------------
+type rayKind uint8
+
+const (
+	cameraRay rayKind = iota
+	shadowRay
+	reflectionRay
+	refractionRay
+)
+
+type RayCounts struct {
+	Camera     uint64
+	Shadow     uint64
+	Reflection uint64
+	Refraction uint64
+}
+
+func (rc RayCounts) Total() uint64 {
+	return rc.Camera + rc.Shadow + rc.Reflection + rc.Refraction
+}
+
+/*
+What a render went through. IntersectionTests is keyed by shape type (see rays.ShapeTypeName)
+and counts groups as well as the shapes inside them.
+MaxDepth is the deepest reflection/refraction bounce that was traced, camera rays being depth 0.
+*/
+type RenderStats struct {
+	Rays              RayCounts
+	IntersectionTests rays.IntersectionTally
+	MaxDepth          uint
+	WallTime          time.Duration
+}
+
+func newRenderStats() *RenderStats {
+	return &RenderStats{IntersectionTests: make(rays.IntersectionTally)}
+}
+
+func (s *RenderStats) TotalIntersectionTests() uint64 {
+	var total uint64
+	for _, n := range s.IntersectionTests {
+		total += n
+	}
+	return total
+}
+
+// Safe to call on a nil *RenderStats, in which case nothing is recorded
+func (s *RenderStats) countRay(kind rayKind, depth uint) {
+	if s == nil {
+		return
+	}
+
+	switch kind {
+	case cameraRay:
+		s.Rays.Camera++
+	case shadowRay:
+		s.Rays.Shadow++
+	case reflectionRay:
+		s.Rays.Reflection++
+	case refractionRay:
+		s.Rays.Refraction++
+	}
+
+	s.MaxDepth = max(s.MaxDepth, depth)
+}
+
+func (s *RenderStats) merge(other *RenderStats) {
+	s.Rays.Camera += other.Rays.Camera
+	s.Rays.Shadow += other.Rays.Shadow
+	s.Rays.Reflection += other.Rays.Reflection
+	s.Rays.Refraction += other.Rays.Refraction
+
+	for shape_type, n := range other.IntersectionTests {
+		s.IntersectionTests[shape_type] += n
+	}
+
+	s.MaxDepth = max(s.MaxDepth, other.MaxDepth)
+}
@@ -8,12 +8,19 @@ import (
 )
 
 type World struct {
-	lightSource *rays.Light
-	objects     []rays.Shape
+	lights  []rays.LightSource
+	objects []rays.Shape
+	// what rays are tested against after Divide: the unbounded objects and one group over the rest, nil before
+	divided []rays.Shape
+
+	// set on the copies of the world handed to render workers, nil otherwise
+	stats *RenderStats
+	// set on those same copies from RenderOptions, 0 means rays.REC_LIMIT
+	recLimit uint
 }
 
 func NewEmptyWorld() World {
-	return World{lightSource: nil, objects: make([]rays.Shape, 0)}
+	return World{lights: make([]rays.LightSource, 0), objects: make([]rays.Shape, 0)}
 }
 
 func NewDefaultWorld() World {
@@ -28,21 +35,46 @@ func NewDefaultWorld() World {
 	_objects = append(_objects, s1)
 	_objects = append(_objects, s2)
 	return World{
-		lightSource: &lightSrc,
-		objects:     _objects,
+		lights:  []rays.LightSource{lightSrc},
+		objects: _objects,
 	}
 }
 
+/*
+Returns a copy of the first light of the world if it is a point or area light, nil otherwise.
+Kept for single light scenes, see Lights
+*/
 func (w *World) LightSource() *rays.Light {
-	return w.lightSource
+	if len(w.lights) == 0 {
+		return nil
+	}
+	if light, isLight := w.lights[0].(rays.Light); isLight {
+		return &light
+	}
+	return nil
 }
 
+/*
+Replaces every light of the world with lightSrc. A nil light leaves the world without any light
+*/
 func (w *World) SetLightSource(lightSrc *rays.Light) {
-	w.lightSource = lightSrc
+	w.lights = make([]rays.LightSource, 0)
+	if lightSrc != nil {
+		w.lights = append(w.lights, *lightSrc)
+	}
+}
+
+func (w *World) AddLight(light rays.LightSource) {
+	w.lights = append(w.lights, light)
+}
+
+func (w *World) Lights() []rays.LightSource {
+	return w.lights
 }
 
 func (w *World) AddObject(obj rays.Shape) {
 	w.objects = append(w.objects, obj)
+	w.divided = nil
 }
 
 func (w *World) ListObjects() []rays.Shape {
@@ -54,6 +86,7 @@ func (w *World) RemoveObjectAt(index int) {
 		return
 	}
 	w.objects = append(w.objects[0:index], w.objects[index+1:len(w.objects)]...)
+	w.divided = nil
 }
 
 func (w *World) ReplaceObjectAt(index int, obj rays.Shape) {
@@ -61,16 +94,59 @@ func (w *World) ReplaceObjectAt(index int, obj rays.Shape) {
 		return
 	}
 	w.objects[index] = obj
+	w.divided = nil
 }
 
 func (w *World) PerformObjectModifications(index int, ops func(obj rays.Shape) rays.Shape) {
 	w.ReplaceObjectAt(index, ops(w.ListObjects()[index]))
 }
 
+/*
+Gathers every object with finite bounds into a single group and builds a bounding volume hierarchy over it,
+see rays.Group.Divide. Infinite objects such as planes stay at the top level as they would only widen every box.
+
+The hierarchy is kept next to the objects, so ListObjects and the edits by index still see them as they were added.
+Adding, removing or replacing an object drops the hierarchy again, call Divide once the world is complete.
+The bounded objects become children of groups without a transformation, which leaves their world space as it was.
+*/
+func (w *World) Divide(threshold int) {
+	bounded_group := rays.NewGroup()
+	remaining := make([]rays.Shape, 0)
+
+	for _, obj := range w.objects {
+		groupable, isGroupable := rays.ToGroupable(obj)
+
+		if !isGroupable || !rays.ParentSpaceBounds(obj).IsFinite() {
+			remaining = append(remaining, obj)
+			continue
+		}
+		bounded_group.IndoctrinateShapeToGroup(groupable)
+	}
+
+	if len(bounded_group.ContainedShapes()) == 0 {
+		return
+	}
+
+	bounded_group.Divide(threshold)
+	w.divided = append(remaining, &bounded_group)
+}
+
+// The shapes rays are tested against, the hierarchy built by Divide when there is one
+func (w World) tracedObjects() []rays.Shape {
+	if w.divided != nil {
+		return w.divided
+	}
+	return w.objects
+}
+
 func (w World) IntersectWithRay(r rays.Ray) []rays.Intersection {
 	res := make([]rays.Intersection, 0)
 
-	for _, obj := range w.objects {
+	if w.stats != nil {
+		r = r.WithTally(w.stats.IntersectionTests)
+	}
+
+	for _, obj := range w.tracedObjects() {
 		res = append(res, rays.Intersect(obj, r)...)
 	}
 
@@ -95,20 +171,50 @@ func (w World) Color_At(r rays.Ray, limit uint) rays.Colour {
 
 	precomp := PreparePrecompData(*res, r, xs)
 
-	return precomp.Shade_Hit(*w.LightSource(), w, limit)
+	return precomp.Shade_Hit(w, limit)
 }
 
-func (w World) IsShadowed(point coordinates.Coordinate) bool {
-	_vec := w.lightSource.Origin.Sub(&point)
-	dist := _vec.Magnitude()
-	direction := _vec.Norm()
+// Color_At for a ray of the given kind, counted in the world's stats when it keeps any
+func (w World) traceRay(r rays.Ray, limit uint, kind rayKind) rays.Colour {
+	if limit > 0 && limit <= w.recursionLimit() {
+		w.stats.countRay(kind, w.recursionLimit()-limit)
+	}
+	return w.Color_At(r, limit)
+}
+
+// How many surfaces a camera ray may shade, its own hit included
+func (w World) recursionLimit() uint {
+	if w.recLimit == 0 {
+		return rays.REC_LIMIT
+	}
+	return w.recLimit
+}
+
+/*
+Returns the share of the light's samples that are blocked on their way to point,
+0 meaning fully lit and 1 fully in shadow. Single sample lights only ever return 0 or 1.
+*/
+func (w World) IsShadowed(point coordinates.Coordinate, light rays.LightSource) float64 {
+	blocked := 0
+
+	samples := light.Samples()
+	for i := range samples {
+		if w.isBlocked(point, light.SampleAt(i, point)) {
+			blocked++
+		}
+	}
+
+	return float64(blocked) / float64(samples)
+}
 
-	ray := rays.NewRay(point, *direction)
+func (w World) isBlocked(point coordinates.Coordinate, sample rays.LightSample) bool {
+	ray := rays.NewRay(point, sample.Direction)
+	w.stats.countRay(shadowRay, 0)
 
 	xs := w.IntersectWithRay(ray)
 	h, doesHit := rays.Hit(xs)
 
-	if doesHit && h.Tvalue < dist && h.Obj.GetMaterial().Transparency == 0 {
+	if doesHit && h.Tvalue < sample.Distance && h.Obj.GetMaterial().Transparency == 0 {
 		return true
 	}
 
@@ -68,32 +68,77 @@ func TestWorldColorRayBehind(t *testing.T) {
 	assert.Equal(t, rays.Colour{1, 1, 1}, c)
 }
 
+func TestWorldColorWithTwoLights(t *testing.T) {
+	w := NewDefaultWorld()
+	r := rays.NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1))
+	single := w.Color_At(r, 1)
+
+	w.AddLight(*w.LightSource())
+	assert.Equal(t, 2, len(w.Lights()))
+
+	c := w.Color_At(r, 1)
+	for i := range single {
+		helpers.ApproxEqual(t, 2*single[i], c[i], 0.0001)
+	}
+}
+
+func TestWorldColorWithShadowFromOneLight(t *testing.T) {
+	w := NewEmptyWorld()
+	w.AddLight(rays.NewLightSource(0, 0, -10, rays.NewWhiteLightColour()))
+	w.AddLight(rays.NewLightSource(0, 10, 0, rays.NewWhiteLightColour()))
+
+	floor := rays.NewPlane(coordinates.CreatePoint(0, 0, 0))
+	blocker := rays.NewCenteredSphere()
+	blocker.SetTransformation(matrices.TranslationMatrix(0, 3, 0))
+	w.AddObject(floor)
+	w.AddObject(blocker)
+
+	p := coordinates.CreatePoint(0, 0.0001, 0)
+	assert.Equal(t, 0.0, w.IsShadowed(p, w.Lights()[0]))
+	assert.Equal(t, 1.0, w.IsShadowed(p, w.Lights()[1]))
+}
+
+func TestWorldWithoutLightsIsAmbientOnly(t *testing.T) {
+	w := NewDefaultWorld()
+	w.SetLightSource(nil)
+	assert.Nil(t, w.LightSource())
+	assert.Empty(t, w.Lights())
+
+	r := rays.NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1))
+	c := w.Color_At(r, 1)
+
+	expected_c := rays.Colour{0.08, 0.1, 0.06}
+	for i := range expected_c {
+		helpers.ApproxEqual(t, expected_c[i], c[i], 0.0001)
+	}
+}
+
 func TestNoShadowWhenNothingCollinear(t *testing.T) {
 	w := NewDefaultWorld()
 	p := coordinates.CreatePoint(0, 10, 0)
-	in_shadow := w.IsShadowed(p)
-	assert.False(t, in_shadow)
+	in_shadow := w.IsShadowed(p, *w.LightSource())
+	assert.Equal(t, 0.0, in_shadow)
 }
 
 func TestShadowWhenObjectBetweenAndLight(t *testing.T) {
 	w := NewDefaultWorld()
 	p := coordinates.CreatePoint(10, -10, 10)
-	in_shadow := w.IsShadowed(p)
-	assert.True(t, in_shadow)
+	in_shadow := w.IsShadowed(p, *w.LightSource())
+	assert.Equal(t, 1.0, in_shadow)
 }
 
 func TestNoShadowWhenObjectBehindLight(t *testing.T) {
 	w := NewDefaultWorld()
 	p := coordinates.CreatePoint(-20, 20, -20)
-	in_shadow := w.IsShadowed(p)
-	assert.False(t, in_shadow)
+	in_shadow := w.IsShadowed(p, *w.LightSource())
+	assert.Equal(t, 0.0, in_shadow)
 }
 
 func TestNoShadowWhenObjectBehindPoint(t *testing.T) {
 	w := NewDefaultWorld()
 	p := coordinates.CreatePoint(-2, 2, -2)
-	in_shadow := w.IsShadowed(p)
-	assert.False(t, in_shadow)
+	in_shadow := w.IsShadowed(p, *w.LightSource())
+	assert.Equal(t, 0.0, in_shadow)
 }
 
 func TestShadeHitWithReflectiveObject(t *testing.T) {
@@ -185,3 +230,76 @@ func TestShadeHitWithReflectiveRefractiveObject(t *testing.T) {
 		helpers.ApproxEqual(t, expected_c[i], c[i], 0.0001)
 	}
 }
+
+func TestWorldDivideKeepsIntersections(t *testing.T) {
+	w := NewDefaultWorld()
+	plane := rays.NewPlane(coordinates.CreatePoint(0, 0, 0))
+	plane.SetTransformation(matrices.TranslationMatrix(0, -1, 0))
+	w.AddObject(plane)
+
+	r := rays.NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, -0.1, 1))
+	expected := w.IntersectWithRay(r)
+
+	objects := append([]rays.Shape{}, w.ListObjects()...)
+	w.Divide(1)
+	assert.Equal(t, objects, w.ListObjects())
+
+	xs := w.IntersectWithRay(r)
+	assert.Equal(t, len(expected), len(xs))
+	for i := range expected {
+		assert.Equal(t, expected[i].Obj.Id(), xs[i].Obj.Id())
+		helpers.ApproxEqual(t, expected[i].Tvalue, xs[i].Tvalue, 0.00001)
+	}
+
+	c := w.Color_At(rays.NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1)), 1)
+	expected_c := rays.Colour{0.38066, 0.47583, 0.2855}
+	for i := range expected_c {
+		helpers.ApproxEqual(t, expected_c[i], c[i], 0.0001)
+	}
+}
+
+func TestWorldEditsAfterDivide(t *testing.T) {
+	w := NewDefaultWorld()
+	w.Divide(1)
+
+	// indices keep pointing at the objects as they were added
+	w.RemoveObjectAt(0)
+	assert.Equal(t, 1, len(w.ListObjects()))
+
+	r := rays.NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1))
+	xs := w.IntersectWithRay(r)
+	assert.Equal(t, 2, len(xs))
+	helpers.ApproxEqual(t, 4.5, xs[0].Tvalue, 0.00001)
+}
+
+func TestAreaLightShadowAmount(t *testing.T) {
+	w := NewDefaultWorld()
+	light := rays.NewAreaLight(coordinates.CreatePoint(-0.5, -0.5, -5), coordinates.CreateVector(1, 0, 0), 2, coordinates.CreateVector(0, 1, 0), 2, rays.NewWhiteLightColour())
+
+	for _, data := range []struct {
+		point    coordinates.Coordinate
+		expected float64
+	}{
+		{coordinates.CreatePoint(0, 0, 2), 1.0},
+		{coordinates.CreatePoint(1, -1, 2), 0.75},
+		{coordinates.CreatePoint(1.5, 0, 2), 0.5},
+		{coordinates.CreatePoint(1.25, 1.25, 3), 0.25},
+		{coordinates.CreatePoint(0, 0, -2), 0.0},
+	} {
+		assert.Equal(t, data.expected, w.IsShadowed(data.point, light), "%v", data.point)
+	}
+}
+
+func TestDirectionalLightShadows(t *testing.T) {
+	w := NewEmptyWorld()
+	w.AddLight(rays.NewDirectionalLight(coordinates.CreateVector(0, -1, 0), rays.NewWhiteLightColour()))
+	assert.Nil(t, w.LightSource())
+
+	blocker := rays.NewCenteredSphere()
+	blocker.SetTransformation(matrices.TranslationMatrix(0, 1000, 0))
+	w.AddObject(blocker)
+
+	// however far away the blocker is, parallel shadow rays still reach it
+	assert.Equal(t, 1.0, w.IsShadowed(coordinates.CreatePoint(0.5, 0, 0), w.Lights()[0]))
+	assert.Equal(t, 0.0, w.IsShadowed(coordinates.CreatePoint(1.5, 0, 0), w.Lights()[0]))
+}
@@ -1,16 +1,19 @@
 package playground
 
 import (
-	"fmt"
 	"math"
-	"rattata/canvas"
 	"rattata/coordinates"
 	"rattata/matrices"
 	"rattata/observe"
 	"rattata/rays"
+	"rattata/scene"
 )
 
-func CreateGoofyWorld() {
+func init() {
+	register("goofy-world", "a checkered cube and a cylinder on a reflective floor between two walls", goofyWorld)
+}
+
+func goofyWorld() scene.Scene {
 	w := observe.NewEmptyWorld()
 
 	light_src := rays.NewLightSource(5, 10, -10, rays.NewLightColour(1, 1, 1))
@@ -108,8 +111,6 @@ func CreateGoofyWorld() {
 	view_t := matrices.View_Transform(coordinates.CreatePoint(0, 1, -10), coordinates.CreatePoint(0, -1, 0), coordinates.CreateVector(0, 1, 0))
 
 	cam.SetTransformationMatrix(view_t)
-	// my_canvas := observe.Render(cam, w)
-	my_canvas := observe.RenderParaller(cam, w, 8)
-	fmt.Println(canvas.CanvasToPPMData(my_canvas))
 
+	return scene.Scene{World: w, Camera: cam}
 }
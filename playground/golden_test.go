@@ -0,0 +1,95 @@
+package playground
+
+import (
+	"context"
+	"flag"
+	"image/png"
+	"os"
+	"path/filepath"
+	"rattata/canvas"
+	"rattata/observe"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ---------------------------------- Golden images ----------------------------------
+// Every built-in scene is rendered small and compared against testdata/golden/<scene>.png.
+// After a deliberate change to how scenes look, rewrite the references with
+//
+//	go test ./playground -run TestGoldenImages -update
+//
+// and look over the new images before committing them.
+
+var update = flag.Bool("update", false, "rewrite the golden images from the current renders")
+
+const (
+	GOLDEN_DIR   = "testdata/golden"
+	GOLDEN_WIDTH = 64
+	// channels may drift by this much, e.g. from fused multiply-adds on other architectures
+	GOLDEN_TOLERANCE = 2
+)
+
+func renderGolden(t *testing.T, s BuiltinScene) canvas.Canvas {
+	t.Helper()
+	built := s.Build()
+	cam := built.Camera
+	cam.Resize(GOLDEN_WIDTH, max(1, uint32(float64(GOLDEN_WIDTH)*float64(cam.Vsize)/float64(cam.Hsize)+0.5)))
+
+	img, _, err := observe.RenderContext(context.Background(), cam, built.World, observe.RenderOptions{})
+	assert.Nil(t, err)
+	return img
+}
+
+func loadGolden(path string) (canvas.Canvas, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	return canvas.FromImage(img), nil
+}
+
+// Puts the render and the diff of a failed comparison where they outlive the test, returning their directory
+func saveFailure(t *testing.T, name string, actual, diff canvas.Canvas) string {
+	t.Helper()
+	dir := filepath.Join(os.TempDir(), "rattata-golden")
+	assert.Nil(t, os.MkdirAll(dir, 0755))
+	assert.Nil(t, actual.Save(filepath.Join(dir, name+".actual.png")))
+	assert.Nil(t, diff.Save(filepath.Join(dir, name+".diff.png")))
+	return dir
+}
+
+func TestGoldenImages(t *testing.T) {
+	for _, s := range Scenes() {
+		t.Run(s.Name, func(t *testing.T) {
+			path := filepath.Join(GOLDEN_DIR, s.Name+".png")
+			actual := renderGolden(t, s)
+
+			if *update {
+				assert.Nil(t, os.MkdirAll(GOLDEN_DIR, 0755))
+				assert.Nil(t, actual.Save(path))
+				return
+			}
+
+			expected, err := loadGolden(path)
+			if err != nil {
+				t.Fatalf("no golden image for %s, run the test with -update to create it: %v", s.Name, err)
+			}
+
+			diff, err := canvas.Compare(expected, actual, GOLDEN_TOLERANCE)
+			if err != nil {
+				t.Fatalf("%s: %v", s.Name, err)
+			}
+			if diff.Mismatched > 0 {
+				dir := saveFailure(t, s.Name, actual, diff.Diff)
+				t.Errorf("%s no longer matches %s: %v\nthe render and its diff are in %s", s.Name, path, diff, dir)
+			}
+		})
+	}
+}
@@ -1,16 +1,19 @@
 package playground
 
 import (
-	"fmt"
 	"math"
-	"rattata/canvas"
 	"rattata/coordinates"
 	"rattata/matrices"
 	"rattata/observe"
 	"rattata/rays"
+	"rattata/scene"
 )
 
-func AHexagon() {
+func init() {
+	register("hexagon", "spheres and cylinders grouped into the sides of a hexagon", aHexagon)
+}
+
+func aHexagon() scene.Scene {
 	my_world := observe.NewEmptyWorld()
 
 	// Adjust light source position for better illumination
@@ -30,9 +33,7 @@ func AHexagon() {
 	)
 	cam.SetTransformationMatrix(view_t)
 
-	// Render the scene
-	my_canvas := observe.RenderParaller(cam, my_world, 8)
-	fmt.Println(canvas.CanvasToPPMData(my_canvas))
+	return scene.Scene{World: my_world, Camera: cam}
 }
 
 func hexagon_corner_a() rays.Sphere {
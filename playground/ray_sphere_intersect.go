@@ -1,41 +1,34 @@
 package playground
 
 import (
-	"fmt"
-	"rattata/canvas"
+	"math"
 	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/observe"
 	"rattata/rays"
+	"rattata/scene"
 )
 
-func ProcessRaySphereIntersection() {
-	ray_origin := coordinates.CreatePoint(0, 0, -5)
-	wall_z := 10.0
-	wall_size := 7.0
-
-	canvas_pixels := 200.0
-	pixel_size := wall_size / canvas_pixels
-	half := wall_size / 2
-
-	my_canvas := canvas.CreateCanvas(uint32(canvas_pixels), uint32(canvas_pixels))
-	color := canvas.NewColour()
-	color.SetValue(canvas.Red, 255)
-	sph := rays.NewCenteredSphere()
+func init() {
+	register("sphere-silhouette", "the flat red silhouette of a unit sphere", raySphereIntersection)
+}
 
-	for y := 0; y < my_canvas.GetHeight(); y++ {
-		world_y := half - pixel_size*float64(y)
+func raySphereIntersection() scene.Scene {
+	w := observe.NewEmptyWorld()
 
-		for x := 0; x < my_canvas.GetWidth(); x++ {
-			world_x := -half + pixel_size*float64(x)
+	light := rays.NewLightSource(-10, 10, -10, rays.NewWhiteLightColour())
+	w.SetLightSource(&light)
 
-			position := coordinates.CreatePoint(world_x, world_y, wall_z)
+	// only ambient light, so every hit comes out the same red
+	sph := rays.NewCenteredSphere()
+	sph.Material.Pattern = rays.NewPlainPattern(rays.Colour{1, 0, 0})
+	sph.Material.Ambient, sph.Material.Diffuse, sph.Material.Specular = 1, 0, 0
+	w.AddObject(&sph)
 
-			r := rays.NewRay(ray_origin, *position.Sub(&ray_origin).Norm())
-			xs := rays.Intersect(sph, r)
+	// looking from z = -5 at a 7 units wide wall standing at z = 10
+	wall_z, wall_size := 10.0, 7.0
+	cam := observe.CreateNewCamera(200, 200, 2*math.Atan(wall_size/2/(wall_z+5)))
+	cam.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(0, 0, -5), coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(0, 1, 0)))
 
-			if _, isPresent := rays.Hit(xs); isPresent {
-				my_canvas.WritePixel(uint32(x), uint32(y), color)
-			}
-		}
-	}
-	fmt.Println(canvas.CanvasToPPMData(my_canvas))
+	return scene.Scene{World: w, Camera: cam}
 }
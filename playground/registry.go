@@ -0,0 +1,48 @@
+package playground
+
+import (
+	"rattata/scene"
+	"slices"
+	"strings"
+)
+
+// ---------------------------------- Scene registry ----------------------------------
+
+/*
+A scene that ships with rattata, built in code rather than loaded from a file
+*/
+type BuiltinScene struct {
+	Name        string
+	Description string
+	Build       func() scene.Scene
+}
+
+var builtins = make(map[string]BuiltinScene)
+
+// Every scene file registers its builders from init
+func register(name, description string, build func() scene.Scene) {
+	if _, isTaken := builtins[name]; isTaken {
+		panic("playground scene " + name + " is registered twice")
+	}
+	builtins[name] = BuiltinScene{Name: name, Description: description, Build: build}
+}
+
+/*
+Every built-in scene, sorted by name
+*/
+func Scenes() []BuiltinScene {
+	res := make([]BuiltinScene, 0, len(builtins))
+	for _, s := range builtins {
+		res = append(res, s)
+	}
+
+	slices.SortFunc(res, func(a, b BuiltinScene) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return res
+}
+
+func Lookup(name string) (BuiltinScene, bool) {
+	s, isKnown := builtins[name]
+	return s, isKnown
+}
@@ -0,0 +1,33 @@
+package playground
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenesAreSortedAndBuild(t *testing.T) {
+	scenes := Scenes()
+	assert.NotEmpty(t, scenes)
+
+	for i, s := range scenes {
+		if i > 0 {
+			assert.Less(t, scenes[i-1].Name, s.Name)
+		}
+		assert.NotEmpty(t, s.Description, s.Name)
+
+		built := s.Build()
+		assert.NotZero(t, built.Camera.Hsize, s.Name)
+		assert.NotEmpty(t, built.World.ListObjects(), s.Name)
+		assert.NotEmpty(t, built.World.Lights(), s.Name)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	s, isKnown := Lookup("hexagon")
+	assert.True(t, isKnown)
+	assert.Equal(t, "hexagon", s.Name)
+
+	_, isKnown = Lookup("nope")
+	assert.False(t, isKnown)
+}
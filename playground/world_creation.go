@@ -1,16 +1,20 @@
 package playground
 
 import (
-	"fmt"
 	"math"
-	"rattata/canvas"
 	"rattata/coordinates"
 	"rattata/matrices"
 	"rattata/observe"
 	"rattata/rays"
+	"rattata/scene"
 )
 
-func PerformWorldBuildingDefault() {
+func init() {
+	register("default-world", "the two spheres of the default world", worldBuildingDefault)
+	register("patterned-spheres", "spheres with stripe, gradient and uv checker patterns in front of two walls", worldBuildingCustom)
+}
+
+func worldBuildingDefault() scene.Scene {
 	w := observe.NewDefaultWorld()
 	cam := observe.CreateNewCamera(500, 400, math.Pi/2)
 	from := coordinates.CreatePoint(0, 0, -5)
@@ -18,11 +22,10 @@ func PerformWorldBuildingDefault() {
 	up := coordinates.CreateVector(0, 1, 0)
 	cam.SetTransformationMatrix(matrices.View_Transform(from, to, up))
 
-	my_canvas := observe.RenderParaller(cam, w, 3)
-	fmt.Println(canvas.CanvasToPPMData(my_canvas))
+	return scene.Scene{World: w, Camera: cam}
 }
 
-func PerformWorldBuildingCustom() {
+func worldBuildingCustom() scene.Scene {
 
 	my_world := observe.NewEmptyWorld()
 	light_src := rays.NewLightSource(-10, 10, -10, rays.NewLightColour(1, 1, 1))
@@ -152,6 +155,6 @@ func PerformWorldBuildingCustom() {
 	view_t := matrices.View_Transform(coordinates.CreatePoint(0, 1.5, -5), coordinates.CreatePoint(0, 1, 0), coordinates.CreateVector(0, 1, 0))
 
 	cam.SetTransformationMatrix(matrices.PerformOrderedChainingOps(matrices.NewIdentityMatrix(4), view_t))
-	my_canvas := observe.RenderParaller(cam, my_world, 8)
-	fmt.Println(canvas.CanvasToPPMData(my_canvas))
+
+	return scene.Scene{World: my_world, Camera: cam}
 }
@@ -0,0 +1,318 @@
+package rays
+
+import (
+	"math"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"sync/atomic"
+)
+
+// ---------------------------------- Bounding Box ----------------------------------
+type BoundingBox struct {
+	Min coordinates.Coordinate
+	Max coordinates.Coordinate
+}
+
+/*
+An empty box has its minimum at +Inf and maximum at -Inf, so that adding any point makes it valid
+*/
+func NewEmptyBoundingBox() BoundingBox {
+	return BoundingBox{
+		Min: coordinates.CreatePoint(math.Inf(1), math.Inf(1), math.Inf(1)),
+		Max: coordinates.CreatePoint(math.Inf(-1), math.Inf(-1), math.Inf(-1)),
+	}
+}
+
+func NewInfiniteBoundingBox() BoundingBox {
+	return BoundingBox{
+		Min: coordinates.CreatePoint(math.Inf(-1), math.Inf(-1), math.Inf(-1)),
+		Max: coordinates.CreatePoint(math.Inf(1), math.Inf(1), math.Inf(1)),
+	}
+}
+
+func NewBoundingBox(min, max coordinates.Coordinate) BoundingBox {
+	return BoundingBox{Min: min, Max: max}
+}
+
+func (b BoundingBox) IsEmpty() bool {
+	for _, axis := range []coordinates.CoordinateAxis{coordinates.X, coordinates.Y, coordinates.Z} {
+		if b.Min.Get(axis) > b.Max.Get(axis) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b BoundingBox) IsFinite() bool {
+	for _, axis := range []coordinates.CoordinateAxis{coordinates.X, coordinates.Y, coordinates.Z} {
+		if math.IsInf(b.Min.Get(axis), 0) || math.IsInf(b.Max.Get(axis), 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *BoundingBox) AddPoint(p coordinates.Coordinate) {
+	for _, axis := range []coordinates.CoordinateAxis{coordinates.X, coordinates.Y, coordinates.Z} {
+		b.Min.Set(axis, math.Min(b.Min.Get(axis), p.Get(axis)))
+		b.Max.Set(axis, math.Max(b.Max.Get(axis), p.Get(axis)))
+	}
+}
+
+func (b *BoundingBox) Merge(other BoundingBox) {
+	if other.IsEmpty() {
+		return
+	}
+	b.AddPoint(other.Min)
+	b.AddPoint(other.Max)
+}
+
+func (b BoundingBox) ContainsPoint(p coordinates.Coordinate) bool {
+	for _, axis := range []coordinates.CoordinateAxis{coordinates.X, coordinates.Y, coordinates.Z} {
+		if p.Get(axis) < b.Min.Get(axis) || p.Get(axis) > b.Max.Get(axis) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b BoundingBox) ContainsBox(other BoundingBox) bool {
+	return b.ContainsPoint(other.Min) && b.ContainsPoint(other.Max)
+}
+
+/*
+Returns the box enclosing all 8 transformed corners of b.
+A box reaching infinity cannot be transformed meaningfully and stays infinite in every direction.
+*/
+func (b BoundingBox) Transform(mt matrices.Matrix) BoundingBox {
+	if b.IsEmpty() {
+		return b
+	}
+	if !b.IsFinite() {
+		return NewInfiniteBoundingBox()
+	}
+
+	res := NewEmptyBoundingBox()
+	for _, x := range []float64{b.Min.Get(coordinates.X), b.Max.Get(coordinates.X)} {
+		for _, y := range []float64{b.Min.Get(coordinates.Y), b.Max.Get(coordinates.Y)} {
+			for _, z := range []float64{b.Min.Get(coordinates.Z), b.Max.Get(coordinates.Z)} {
+				corner := matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(coordinates.CreatePoint(x, y, z)), mt)
+				res.AddPoint(matrices.MatrixToCoordinate(corner))
+			}
+		}
+	}
+	return res
+}
+
+// Slab test, same as the one used by the cube but against arbitrary extents
+func (b BoundingBox) IntersectsRay(ray Ray) bool {
+	if b.IsEmpty() {
+		return false
+	}
+
+	tmin, tmax := math.Inf(-1), math.Inf(1)
+
+	for _, axis := range []coordinates.CoordinateAxis{coordinates.X, coordinates.Y, coordinates.Z} {
+		origin, direction := ray.Origin.Get(axis), ray.Direction.Get(axis)
+
+		// tiny but non-zero directions are left to the division, which turns them into +-Inf as needed;
+		// rays in scaled up groups have directions of that size
+		if direction == 0 {
+			if origin < b.Min.Get(axis) || origin > b.Max.Get(axis) {
+				return false
+			}
+			continue
+		}
+
+		t1 := (b.Min.Get(axis) - origin) / direction
+		t2 := (b.Max.Get(axis) - origin) / direction
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+
+		tmin = math.Max(tmin, t1)
+		tmax = math.Min(tmax, t2)
+	}
+
+	return tmin <= tmax && tmax >= 0
+}
+
+/*
+Cuts the box in half across its widest axis
+*/
+func (b BoundingBox) Split() (BoundingBox, BoundingBox) {
+	dx := b.Max.Get(coordinates.X) - b.Min.Get(coordinates.X)
+	dy := b.Max.Get(coordinates.Y) - b.Min.Get(coordinates.Y)
+	dz := b.Max.Get(coordinates.Z) - b.Min.Get(coordinates.Z)
+
+	axis := coordinates.Z
+	if dx >= dy && dx >= dz {
+		axis = coordinates.X
+	} else if dy >= dz {
+		axis = coordinates.Y
+	}
+
+	mid := (b.Min.Get(axis) + b.Max.Get(axis)) / 2
+
+	left_max := b.Max.Copy()
+	left_max.Set(axis, mid)
+	right_min := b.Min.Copy()
+	right_min.Set(axis, mid)
+
+	return NewBoundingBox(b.Min, left_max), NewBoundingBox(right_min, b.Max)
+}
+
+/*
+Returns the bounds of the shape as seen from its parent, i.e. after applying the shape's own transformation
+*/
+func ParentSpaceBounds(shape Shape) BoundingBox {
+	return shape.Bounds().Transform(shape.Transformation())
+}
+
+/*
+Shared by every copy of a group. The box is published through an atomic pointer so that render workers
+read it without locking, nil meaning it has to be computed again.
+*/
+type boundsCache struct {
+	box atomic.Pointer[BoundingBox]
+}
+
+// Drops the cached bounds of the group and everything above it
+func invalidateBounds(g *Group) {
+	for ; g != nil; g = g.parent {
+		if g.bounds != nil {
+			g.bounds.box.Store(nil)
+		}
+	}
+}
+
+/*
+Recomputes the cached bounds of every group in and under shape.
+Groups only notice children being added or re-transformed, so call this after editing
+geometry fields (Sphere.Radius, XZCylinder.Minimum, Triangle points, ...) of a shape already in a group.
+*/
+func RefreshBounds(shape Shape) {
+	switch s := shape.(type) {
+	case *Group:
+		for _, shape_ptr := range s.containedShapes {
+			RefreshBounds(*shape_ptr)
+		}
+		invalidateBounds(s)
+	case *CSG:
+		RefreshBounds(s.left)
+		RefreshBounds(s.right)
+	}
+	shape.Bounds()
+}
+
+// ---------------------------------- BVH ----------------------------------
+
+/*
+Builds a bounding volume hierarchy out of the group.
+
+Whenever a group holds at least threshold children, the children that fit entirely into one half of the group's
+bounds are moved into a new sub group for that half; the rest stay where they are. The same is repeated for every
+group (and CSG operand) underneath.
+*/
+func (g *Group) Divide(threshold int) {
+	if threshold <= len(g.containedShapes) {
+		left, right := g.partitionChildren()
+
+		if len(left) > 0 {
+			g.makeSubgroup(left)
+		}
+		if len(right) > 0 {
+			g.makeSubgroup(right)
+		}
+	}
+
+	for _, shape_ptr := range g.containedShapes {
+		divideShape(*shape_ptr, threshold)
+	}
+}
+
+func divideShape(shape Shape, threshold int) {
+	switch s := shape.(type) {
+	case *Group:
+		s.Divide(threshold)
+	case *CSG:
+		divideShape(s.left, threshold)
+		divideShape(s.right, threshold)
+	}
+}
+
+func (g *Group) partitionChildren() ([]*Shape, []*Shape) {
+	// infinite children can never fit into a half, so only the finite ones decide where to split
+	box := NewEmptyBoundingBox()
+	for _, shape_ptr := range g.containedShapes {
+		if child_box := ParentSpaceBounds(*shape_ptr); child_box.IsFinite() {
+			box.Merge(child_box)
+		}
+	}
+
+	if box.IsEmpty() {
+		return nil, nil
+	}
+
+	left_box, right_box := box.Split()
+	left, right, remaining := make([]*Shape, 0), make([]*Shape, 0), make([]*Shape, 0)
+
+	for _, shape_ptr := range g.containedShapes {
+		child_box := ParentSpaceBounds(*shape_ptr)
+
+		if left_box.ContainsBox(child_box) {
+			left = append(left, shape_ptr)
+		} else if right_box.ContainsBox(child_box) {
+			right = append(right, shape_ptr)
+		} else {
+			remaining = append(remaining, shape_ptr)
+		}
+	}
+
+	g.containedShapes = remaining
+	invalidateBounds(g)
+	return left, right
+}
+
+func (g *Group) makeSubgroup(shapes []*Shape) {
+	sub_group := NewGroup()
+
+	for _, shape_ptr := range shapes {
+		sub_group.IndoctrinateShapeToGroup((*shape_ptr).(IsGroupable))
+	}
+
+	g.IndoctrinateShapeToGroup(&sub_group)
+}
+
+/*
+Returns a handle through which the shape can be indoctrinated into a group.
+Shapes held by value are copied onto the heap, so the caller should use the returned handle from then on.
+*/
+func ToGroupable(shape Shape) (IsGroupable, bool) {
+	if groupable, isGroupable := shape.(IsGroupable); isGroupable {
+		return groupable, true
+	}
+
+	switch s := shape.(type) {
+	case Sphere:
+		return &s, true
+	case XZPlane:
+		return &s, true
+	case Cube:
+		return &s, true
+	case XZCylinder:
+		return &s, true
+	case Cone:
+		return &s, true
+	case Triangle:
+		return &s, true
+	case SmoothTriangle:
+		return &s, true
+	case Group:
+		return &s, true
+	case CSG:
+		return &s, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,273 @@
+package rays
+
+import (
+	"math"
+	"rattata/coordinates"
+	"rattata/helpers"
+	"rattata/matrices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShapeBounds(t *testing.T) {
+	cyl := NewXZCylinder()
+	cyl.Minimum, cyl.Maximum = -5, 3
+	cone := NewDoubleNappedCone()
+	cone.Minimum, cone.Maximum = -5, 3
+	tri := NewTriangle(coordinates.CreatePoint(-3, 7, 2), coordinates.CreatePoint(6, 2, -4), coordinates.CreatePoint(2, -1, -1))
+
+	for _, data := range []struct {
+		shape    Shape
+		min, max coordinates.Coordinate
+	}{
+		{NewCenteredSphere(), coordinates.CreatePoint(-1, -1, -1), coordinates.CreatePoint(1, 1, 1)},
+		{NewCube(), coordinates.CreatePoint(-1, -1, -1), coordinates.CreatePoint(1, 1, 1)},
+		{NewPlane(coordinates.CreatePoint(0, 0, 0)), coordinates.CreatePoint(math.Inf(-1), 0, math.Inf(-1)), coordinates.CreatePoint(math.Inf(1), 0, math.Inf(1))},
+		{NewXZCylinder(), coordinates.CreatePoint(-1, math.Inf(-1), -1), coordinates.CreatePoint(1, math.Inf(1), 1)},
+		{cyl, coordinates.CreatePoint(-1, -5, -1), coordinates.CreatePoint(1, 3, 1)},
+		{cone, coordinates.CreatePoint(-5, -5, -5), coordinates.CreatePoint(5, 3, 5)},
+		{tri, coordinates.CreatePoint(-3, -1, -4), coordinates.CreatePoint(6, 7, 2)},
+	} {
+		box := data.shape.Bounds()
+		assert.Equal(t, data.min, box.Min, data.shape.Name())
+		assert.Equal(t, data.max, box.Max, data.shape.Name())
+	}
+}
+
+func TestBoundingBoxMergeAndContains(t *testing.T) {
+	box := NewEmptyBoundingBox()
+	assert.True(t, box.IsEmpty())
+
+	box.AddPoint(coordinates.CreatePoint(-5, 2, 0))
+	box.AddPoint(coordinates.CreatePoint(7, 0, -3))
+	box.Merge(NewBoundingBox(coordinates.CreatePoint(8, -7, -2), coordinates.CreatePoint(14, 4, 8)))
+
+	assert.Equal(t, coordinates.CreatePoint(-5, -7, -3), box.Min)
+	assert.Equal(t, coordinates.CreatePoint(14, 4, 8), box.Max)
+	assert.True(t, box.ContainsPoint(coordinates.CreatePoint(0, 0, 0)))
+	assert.False(t, box.ContainsPoint(coordinates.CreatePoint(15, 0, 0)))
+	assert.True(t, box.ContainsBox(NewBoundingBox(coordinates.CreatePoint(-5, -7, -3), coordinates.CreatePoint(0, 0, 0))))
+	assert.False(t, box.ContainsBox(NewBoundingBox(coordinates.CreatePoint(-6, -7, -3), coordinates.CreatePoint(0, 0, 0))))
+}
+
+func TestBoundingBoxTransform(t *testing.T) {
+	box := NewBoundingBox(coordinates.CreatePoint(-1, -1, -1), coordinates.CreatePoint(1, 1, 1))
+	mt, _ := matrices.GivensRotationMatrix3D(coordinates.X, math.Pi/4).Multiply(matrices.GivensRotationMatrix3D(coordinates.Y, math.Pi/4))
+
+	res := box.Transform(mt)
+	helpers.TestApproxEqualCoordinate(t, coordinates.CreatePoint(-1.41421, -1.70710, -1.70710), res.Min, 0.0001)
+	helpers.TestApproxEqualCoordinate(t, coordinates.CreatePoint(1.41421, 1.70710, 1.70710), res.Max, 0.0001)
+
+	plane := NewPlane(coordinates.CreatePoint(0, 0, 0))
+	assert.Equal(t, NewInfiniteBoundingBox(), plane.Bounds().Transform(mt))
+}
+
+func TestBoundingBoxRayIntersection(t *testing.T) {
+	box := NewBoundingBox(coordinates.CreatePoint(5, -2, 0), coordinates.CreatePoint(11, 4, 7))
+
+	for _, data := range []struct {
+		origin, direction coordinates.Coordinate
+		result            bool
+	}{
+		{coordinates.CreatePoint(15, 1, 2), coordinates.CreateVector(-1, 0, 0), true},
+		{coordinates.CreatePoint(-5, -1, 4), coordinates.CreateVector(1, 0, 0), true},
+		{coordinates.CreatePoint(7, 6, 5), coordinates.CreateVector(0, -1, 0), true},
+		{coordinates.CreatePoint(9, -5, 6), coordinates.CreateVector(0, 1, 0), true},
+		{coordinates.CreatePoint(8, 2, 12), coordinates.CreateVector(0, 0, -1), true},
+		{coordinates.CreatePoint(6, 0, -5), coordinates.CreateVector(0, 0, 1), true},
+		{coordinates.CreatePoint(8, 1, 3.5), coordinates.CreateVector(0, 0, 1), true},
+		{coordinates.CreatePoint(9, -1, -8), coordinates.CreateVector(2, 4, 6), false},
+		{coordinates.CreatePoint(8, 3, -4), coordinates.CreateVector(6, 2, 4), false},
+		{coordinates.CreatePoint(9, -1, -2), coordinates.CreateVector(4, 6, 2), false},
+		{coordinates.CreatePoint(4, 0, 9), coordinates.CreateVector(0, 0, -1), false},
+		{coordinates.CreatePoint(8, 6, -1), coordinates.CreateVector(0, -1, 0), false},
+		{coordinates.CreatePoint(12, 5, 4), coordinates.CreateVector(-1, 0, 0), false},
+		{coordinates.CreatePoint(8, 1, 20), coordinates.CreateVector(0, 0, 1), false},
+	} {
+		r := NewRay(data.origin, *data.direction.Norm())
+		assert.Equal(t, data.result, box.IntersectsRay(r), "%v %v", data.origin, data.direction)
+	}
+}
+
+func TestBoundingBoxSplit(t *testing.T) {
+	box := NewBoundingBox(coordinates.CreatePoint(-1, -2, -3), coordinates.CreatePoint(9, 5.5, 3))
+	left, right := box.Split()
+
+	assert.Equal(t, coordinates.CreatePoint(-1, -2, -3), left.Min)
+	assert.Equal(t, coordinates.CreatePoint(4, 5.5, 3), left.Max)
+	assert.Equal(t, coordinates.CreatePoint(4, -2, -3), right.Min)
+	assert.Equal(t, coordinates.CreatePoint(9, 5.5, 3), right.Max)
+}
+
+func TestGroupBounds(t *testing.T) {
+	s := NewSphere(coordinates.CreatePoint(0, 0, 0), 1)
+	s.SetTransformation(matrices.PerformOrderedChainingOps(matrices.ScalingMatrix(2, 2, 2), matrices.TranslationMatrix(2, 5, -3)))
+	cyl := NewXZCylinder()
+	cyl.Minimum, cyl.Maximum = -2, 2
+	cyl.SetTransformation(matrices.PerformOrderedChainingOps(matrices.ScalingMatrix(0.5, 1, 0.5), matrices.TranslationMatrix(-4, -1, 4)))
+
+	grp := NewGroup()
+	grp.IndoctrinateShapeToGroup(&s)
+	grp.IndoctrinateShapeToGroup(&cyl)
+
+	box := grp.Bounds()
+	helpers.TestApproxEqualCoordinate(t, coordinates.CreatePoint(-4.5, -3, -5), box.Min, 0.00001)
+	helpers.TestApproxEqualCoordinate(t, coordinates.CreatePoint(4, 7, 4.5), box.Max, 0.00001)
+
+	// moving a child afterwards must not leave a stale box behind
+	s.SetTransformation(matrices.TranslationMatrix(10, 0, 0))
+	box = grp.Bounds()
+	helpers.ApproxEqual(t, 11, box.Max.Get(coordinates.X), 0.00001)
+}
+
+func TestRefreshBoundsPicksUpEditedGeometry(t *testing.T) {
+	cyl := NewXZCylinder()
+	cyl.Minimum, cyl.Maximum = -1, 1
+	inner := NewGroup()
+	inner.IndoctrinateShapeToGroup(&cyl)
+	outer := NewGroup()
+	outer.IndoctrinateShapeToGroup(&inner)
+	box := outer.Bounds()
+	helpers.ApproxEqual(t, 1, box.Max.Get(coordinates.Y), 0.00001)
+
+	cyl.Maximum = 4
+	RefreshBounds(&outer)
+	box = outer.Bounds()
+	helpers.ApproxEqual(t, 4, box.Max.Get(coordinates.Y), 0.00001)
+	box = inner.Bounds()
+	helpers.ApproxEqual(t, 4, box.Max.Get(coordinates.Y), 0.00001)
+}
+
+func TestGroupBoundsConcurrentReads(t *testing.T) {
+	grp := NewGroup()
+	for i := 0; i < 8; i++ {
+		s := NewCenteredSphere()
+		s.SetTransformation(matrices.TranslationMatrix(float64(i), 0, 0))
+		grp.IndoctrinateShapeToGroup(&s)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			box := grp.Bounds()
+			helpers.ApproxEqual(t, 8, box.Max.Get(coordinates.X), 0.00001)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBoundsOfLargeScaleGroupStillHit(t *testing.T) {
+	grp := NewGroup()
+	grp.SetTransformation(matrices.ScalingMatrix(2e4, 2e4, 2e4))
+	s := NewCenteredSphere()
+	grp.IndoctrinateShapeToGroup(&s)
+
+	r := NewRay(coordinates.CreatePoint(0, 0, -1e5), coordinates.CreateVector(0, 0, 1))
+	xs := Intersect(&grp, r)
+	assert.Equal(t, 2, len(xs))
+	helpers.ApproxEqual(t, 8e4, xs[0].Tvalue, 1e-6)
+	helpers.ApproxEqual(t, 1.2e5, xs[1].Tvalue, 1e-6)
+}
+
+func TestGroupSkipsChildrenWhenBoundsMiss(t *testing.T) {
+	grp := NewGroup()
+	s := NewCenteredSphere()
+	grp.IndoctrinateShapeToGroup(&s)
+
+	assert.Empty(t, grp.IntersectWithRay(NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 1, 0))))
+	assert.Equal(t, 2, len(grp.IntersectWithRay(NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1)))))
+}
+
+func TestGroupDivide(t *testing.T) {
+	s1 := NewCenteredSphere()
+	s1.SetTransformation(matrices.TranslationMatrix(-2, -2, 0))
+	s2 := NewCenteredSphere()
+	s2.SetTransformation(matrices.TranslationMatrix(-2, 2, 0))
+	s3 := NewCenteredSphere()
+	s3.SetTransformation(matrices.ScalingMatrix(4, 4, 4))
+	plane := NewPlane(coordinates.CreatePoint(0, 0, 0))
+
+	grp := NewGroup()
+	grp.IndoctrinateShapeToGroup(&s1)
+	grp.IndoctrinateShapeToGroup(&s2)
+	grp.IndoctrinateShapeToGroup(&s3)
+	grp.IndoctrinateShapeToGroup(&plane)
+	grp.Divide(1)
+
+	children := grp.ContainedShapes()
+	assert.Equal(t, 3, len(children))
+	assert.Equal(t, s3.Id(), children[0].Id())
+	assert.Equal(t, plane.Id(), children[1].Id())
+
+	sub_group := children[2].(*Group)
+	assert.Equal(t, 2, len(sub_group.ContainedShapes()))
+
+	left := sub_group.ContainedShapes()[0].(*Group)
+	right := sub_group.ContainedShapes()[1].(*Group)
+	assert.Equal(t, s1.Id(), left.ContainedShapes()[0].Id())
+	assert.Equal(t, s2.Id(), right.ContainedShapes()[0].Id())
+	assert.Equal(t, left, s1.Parent())
+
+	n := s1.NormalAtPoint(coordinates.CreatePoint(-2, -1, 0))
+	helpers.TestApproxEqualCoordinate(t, coordinates.CreateVector(0, 1, 0), n, 0.00001)
+}
+
+func TestDividedGroupIntersectsLikeFlatGroup(t *testing.T) {
+	flat, divided := sphereGrid(10), sphereGrid(10)
+	divided.Divide(4)
+	diagonal := coordinates.CreateVector(1, 1, 1)
+
+	for _, r := range []Ray{
+		NewRay(coordinates.CreatePoint(0.1, 0.2, -50), coordinates.CreateVector(0, 0, 1)),
+		NewRay(coordinates.CreatePoint(-50, 4.2, 3.1), coordinates.CreateVector(1, 0, 0)),
+		NewRay(coordinates.CreatePoint(-20, -20, -20), *diagonal.Norm()),
+	} {
+		xs_flat, xs_divided := flat.IntersectWithRay(r), divided.IntersectWithRay(r)
+
+		assert.Equal(t, len(xs_flat), len(xs_divided))
+		for i := range xs_flat {
+			helpers.ApproxEqual(t, xs_flat[i].Tvalue, xs_divided[i].Tvalue, 0.00001)
+		}
+	}
+}
+
+func sphereGrid(n int) *Group {
+	grp := NewGroup()
+
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			for z := 0; z < n; z++ {
+				s := NewCenteredSphere()
+				s.SetTransformation(matrices.PerformOrderedChainingOps(matrices.ScalingMatrix(0.4, 0.4, 0.4),
+					matrices.TranslationMatrix(float64(x-n/2), float64(y-n/2), float64(z-n/2))))
+				grp.IndoctrinateShapeToGroup(&s)
+			}
+		}
+	}
+
+	return &grp
+}
+
+func benchmarkGroupIntersection(b *testing.B, grp *Group) {
+	r := NewRay(coordinates.CreatePoint(0.1, 0.2, -50), coordinates.CreateVector(0, 0, 1))
+	grp.Bounds()
+
+	b.ResetTimer()
+	for range b.N {
+		grp.IntersectWithRay(r)
+	}
+}
+
+func BenchmarkFlatGroupIntersection(b *testing.B) {
+	benchmarkGroupIntersection(b, sphereGrid(12))
+}
+
+func BenchmarkDividedGroupIntersection(b *testing.B) {
+	grp := sphereGrid(12)
+	grp.Divide(8)
+	benchmarkGroupIntersection(b, grp)
+}
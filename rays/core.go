@@ -2,48 +2,52 @@ package rays
 
 import (
 	"math"
+	"rattata/colour"
 	"rattata/coordinates"
 	"rattata/matrices"
+	"reflect"
 )
 
 var EPSILON float64 = 0.0001
 var REC_LIMIT uint = 3
 
 // ------------------------------------- Light and Color struct ------------------------------------
-type Colour = [3]float64
+type Colour = colour.Colour
 
-type Light struct {
-	Origin coordinates.Coordinate
-	Colour Colour
-}
+// Point lights are area lights with a single cell and no extent, see AreaLight
+type Light = AreaLight
 
 func NewLightColour(red, green, blue float64) Colour {
-	return Colour{red, green, blue}
+	return colour.New(red, green, blue)
 }
 
 func NewWhiteLightColour() Colour {
-	return NewLightColour(1, 1, 1)
+	return colour.White()
 }
 
+// Deprecated: use Colour.Add
 func AddColour(c1, c2 Colour) Colour {
-	c3 := Colour{}
-
-	for i := 0; i < 3; i++ {
-		c3[i] = c1[i] + c2[i]
-	}
-
-	return c3
+	return c1.Add(c2)
 }
 
+// Deprecated: use Colour.Sub
 func SubColour(c1, c2 Colour) Colour {
-	c3 := Colour{}
+	return c1.Sub(c2)
+}
 
-	for i := 0; i < 3; i++ {
-		c3[i] = c1[i] - c2[i]
-	}
-	return c3
+/*
+Ambient term of the Phong model alone, as if lit by a white light that reaches nothing else
+*/
+func AmbientLighting(shp Shape, pos coordinates.Coordinate) Colour {
+	m := shp.GetMaterial()
+	_point_color := PatternAtShape(shp, pos, m.Pattern)
+
+	return _point_color.Scale(m.Ambient)
 }
 
+/*
+Deprecated: use Colour.Scale. Unlike Scale, every channel is capped at 255
+*/
 func MulColour(c1 Colour, k float64) Colour {
 	c3 := Colour{}
 
@@ -55,13 +59,16 @@ func MulColour(c1 Colour, k float64) Colour {
 }
 
 func NewLightSource(x, y, z float64, colour Colour) Light {
-	return Light{Origin: coordinates.CreatePoint(x, y, z), Colour: colour}
+	origin := coordinates.CreatePoint(x, y, z)
+	return Light{Origin: origin, Colour: colour, Corner: origin, USteps: 1, VSteps: 1}
 }
 
 // ------------------------------------- Rays and Intersections ------------------------------------
 type Ray struct {
 	Origin    coordinates.Coordinate
 	Direction coordinates.Coordinate
+
+	tally IntersectionTally
 }
 
 func NewRay(origin, direction coordinates.Coordinate) Ray {
@@ -77,6 +84,15 @@ func NewRay(origin, direction coordinates.Coordinate) Ray {
 	return Ray{Origin: org_cpy, Direction: dir_cpy}
 }
 
+/*
+Returns a copy of the ray that counts every shape it is tested against into tally.
+Rays derived from it through Transform keep counting into the same tally.
+*/
+func (r Ray) WithTally(tally IntersectionTally) Ray {
+	r.tally = tally
+	return r
+}
+
 func (r *Ray) PointAtTime(dir float64) *coordinates.Coordinate {
 	scaled_vector := r.Direction.Mul(dir)
 
@@ -117,10 +133,25 @@ func Hit(intersections []Intersection) (*Intersection, bool) {
 	return &res, true
 }
 
+/*
+Number of intersection tests per shape type, keyed by ShapeTypeName. Not safe for concurrent use
+*/
+type IntersectionTally map[string]uint64
+
+func ShapeTypeName(shape Shape) string {
+	t := reflect.TypeOf(shape)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
 func Intersect(shape Shape, ray Ray) []Intersection {
+	if ray.tally != nil {
+		ray.tally[ShapeTypeName(shape)]++
+	}
 
-	inv_transform, _ := shape.Transformation().Inverse()
-	transformed_ray := Transform(ray, inv_transform)
+	transformed_ray := TransformMat4(ray, shape.InverseTransformation())
 
 	return shape.IntersectWithRay(transformed_ray)
 }
@@ -131,7 +162,12 @@ func Transform(ray Ray, matrix matrices.Matrix) Ray {
 	ray_origin_post_transform, _ := matrix.Multiply(ray_origin_matrix)
 	ray_direction_post_transform, _ := matrix.Multiply(ray_direction_matrix)
 
-	return NewRay(matrices.MatrixToCoordinate(ray_origin_post_transform), matrices.MatrixToCoordinate(ray_direction_post_transform))
+	return NewRay(matrices.MatrixToCoordinate(ray_origin_post_transform), matrices.MatrixToCoordinate(ray_direction_post_transform)).WithTally(ray.tally)
+}
+
+// Same as Transform, without going through heap allocated matrices
+func TransformMat4(ray Ray, matrix matrices.Mat4) Ray {
+	return NewRay(matrix.MulCoordinate(ray.Origin), matrix.MulCoordinate(ray.Direction)).WithTally(ray.tally)
 }
 
 // ------------------------------------- Utility Functions  ------------------------------------
@@ -147,53 +183,43 @@ Phong reflection model of lighting
 Ambient -> Background lighting;
 Diffuse -> Light reflected from matte surface;
 Specular -> Reflection of light source
+
+shadowAmount is the share of the light that is blocked, 0 being fully lit and 1 fully in shadow
 */
-func Lighting(shp Shape, light Light, pos, eyeVector, normalVector coordinates.Coordinate, isInShadow bool) Colour {
+func Lighting(shp Shape, light LightSource, pos, eyeVector, normalVector coordinates.Coordinate, shadowAmount float64) Colour {
 
 	m := shp.GetMaterial()
+	intensity := light.IntensityAt(pos)
 
-	_point_color := PatternAtPoint(pos, shp.Transformation(), m.Pattern)
-	effectiveColour := Colour{_point_color[0] * light.Colour[0],
-		_point_color[1] * light.Colour[1],
-		_point_color[2] * light.Colour[2],
-	}
+	_point_color := PatternAtShape(shp, pos, m.Pattern)
+	effectiveColour := _point_color.Hadamard(intensity)
 
-	ambient := Colour{
-		effectiveColour[0] * m.Ambient,
-		effectiveColour[1] * m.Ambient,
-		effectiveColour[2] * m.Ambient,
-	}
-	var diffuse, specular Colour = Colour{}, Colour{}
+	ambient := effectiveColour.Scale(m.Ambient)
+	var diffuse, specular Colour = colour.Black(), colour.Black()
 
-	lightVector := *light.Origin.Sub(&pos).Norm()
-	light_dot_normal := lightVector.DotP(&normalVector)
+	samples := light.Samples()
+	for i := range samples {
+		lightVector := light.SampleAt(i, pos).Direction
+		light_dot_normal := lightVector.DotP(&normalVector)
 
-	if light_dot_normal >= 0 && !isInShadow {
-		diffuse = Colour{
-			effectiveColour[0] * m.Diffuse * light_dot_normal,
-			effectiveColour[1] * m.Diffuse * light_dot_normal,
-			effectiveColour[2] * m.Diffuse * light_dot_normal,
+		if light_dot_normal >= 0 {
+			diffuse = diffuse.Add(effectiveColour.Scale(m.Diffuse).Scale(light_dot_normal))
 		}
-	}
 
-	reflectV := ReflectVector(*lightVector.Negate(), normalVector)
-	reflect_dot_eye := reflectV.DotP(&eyeVector)
+		reflectV := ReflectVector(*lightVector.Negate(), normalVector)
+		reflect_dot_eye := reflectV.DotP(&eyeVector)
 
-	if reflect_dot_eye > 0 && light_dot_normal >= 0 && !isInShadow {
-		factor := math.Pow(reflect_dot_eye, m.Shininess)
+		if reflect_dot_eye > 0 && light_dot_normal >= 0 {
+			factor := math.Pow(reflect_dot_eye, m.Shininess)
 
-		specular = Colour{
-			light.Colour[0] * m.Specular * factor,
-			light.Colour[1] * m.Specular * factor,
-			light.Colour[2] * m.Specular * factor,
+			specular = specular.Add(intensity.Scale(m.Specular).Scale(factor))
 		}
 	}
 
-	return Colour{
-		ambient[0] + diffuse[0] + specular[0],
-		ambient[1] + diffuse[1] + specular[1],
-		ambient[2] + diffuse[2] + specular[2],
-	}
+	// diffuse and specular only come from the unshadowed share of the samples
+	lit_share := (1 - shadowAmount) / float64(samples)
+
+	return ambient.Add(diffuse.Scale(lit_share)).Add(specular.Scale(lit_share))
 }
 
 func RefractiveVector(normal_vector, incidence_vector coordinates.Coordinate, inbound_ri, outbound_ri float64) (*coordinates.Coordinate, bool) {
@@ -255,8 +281,10 @@ func CreateDefaultMaterial() Material {
 		Reflective: 0.0, RefractiveIndex: 1.0, Transparency: 0.0}
 }
 
+func PatternAtShape(shp Shape, world_point coordinates.Coordinate, pattern Pattern) Colour {
+	return pattern.PatternAt(shp.InverseTransformation().MulCoordinate(world_point))
+}
+
 func PatternAtPoint(world_point coordinates.Coordinate, objectTransformation matrices.Matrix, pattern Pattern) Colour {
-	objectTransformationInverse, _ := objectTransformation.Inverse()
-	object_point := matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(world_point), objectTransformationInverse)
-	return pattern.PatternAt(matrices.MatrixToCoordinate(object_point))
+	return pattern.PatternAt(matrices.MustInvertTransformation(objectTransformation).MulCoordinate(world_point))
 }
@@ -4,6 +4,7 @@ import (
 	"math"
 	"rattata/coordinates"
 	"rattata/helpers"
+	"rattata/matrices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -42,7 +43,7 @@ func TestLightingScenarioEyeBetweenLightNSurface(t *testing.T) {
 	eyeV := coordinates.CreateVector(0, 0, -1)
 	normalV := coordinates.CreateVector(0, 0, -1)
 	light := NewLightSource(0, 0, -10, NewWhiteLightColour())
-	result := Lighting(sph, light, position, eyeV, normalV, false)
+	result := Lighting(sph, light, position, eyeV, normalV, 0)
 
 	assert.Equal(t, Colour{1.9, 1.9, 1.9}, result)
 
@@ -54,7 +55,7 @@ func TestLightingScenarioEyeBetweenLightNSurfaceEyeOffset45Deg(t *testing.T) {
 	eyeV := coordinates.CreateVector(0, math.Sqrt(2)/2, -math.Sqrt(2)/2)
 	normalV := coordinates.CreateVector(0, 0, -1)
 	light := NewLightSource(0, 0, -10, NewWhiteLightColour())
-	result := Lighting(sph, light, position, eyeV, normalV, false)
+	result := Lighting(sph, light, position, eyeV, normalV, 0)
 
 	assert.Equal(t, Colour{1, 1, 1}, result)
 }
@@ -65,7 +66,7 @@ func TestLightingScenarioEyeOppositeSurface(t *testing.T) {
 	eyeV := coordinates.CreateVector(0, 0, -1)
 	normalV := coordinates.CreateVector(0, 0, -1)
 	light := NewLightSource(0, 10, -10, NewWhiteLightColour())
-	result := Lighting(sph, light, position, eyeV, normalV, false)
+	result := Lighting(sph, light, position, eyeV, normalV, 0)
 
 	assert.Equal(t, Colour{0.7363961030678927, 0.7363961030678927, 0.7363961030678927}, result)
 }
@@ -76,7 +77,7 @@ func TestLightingScenarioEyeInReflectionPath(t *testing.T) {
 	eyeV := coordinates.CreateVector(0, -float64(math.Sqrt(2)/2), -float64(math.Sqrt(2)/2))
 	normalV := coordinates.CreateVector(0, 0, -1)
 	light := NewLightSource(0, 10, -10, NewWhiteLightColour())
-	result := Lighting(sph, light, position, eyeV, normalV, false)
+	result := Lighting(sph, light, position, eyeV, normalV, 0)
 
 	assert.Equal(t, Colour{1.6363961030678928, 1.6363961030678928, 1.6363961030678928}, result)
 }
@@ -87,7 +88,7 @@ func TestLightingScenarioLightSourceBehindSurface(t *testing.T) {
 	eyeV := coordinates.CreateVector(0, 0, -1)
 	normalV := coordinates.CreateVector(0, 0, -1)
 	light := NewLightSource(0, 0, 10, NewWhiteLightColour())
-	result := Lighting(sph, light, position, eyeV, normalV, false)
+	result := Lighting(sph, light, position, eyeV, normalV, 0)
 
 	assert.Equal(t, Colour{0.1, 0.1, 0.1}, result)
 }
@@ -98,7 +99,7 @@ func TestLightingShadowRegion(t *testing.T) {
 	eyeV := coordinates.CreateVector(0, 0, -1)
 	normalV := coordinates.CreateVector(0, 0, -1)
 	light := NewLightSource(0, 0, -10, NewWhiteLightColour())
-	result := Lighting(sph, light, position, eyeV, normalV, true)
+	result := Lighting(sph, light, position, eyeV, normalV, 1)
 
 	assert.Equal(t, Colour{0.1, 0.1, 0.1}, result)
 }
@@ -160,3 +161,45 @@ func TestSchlickFor_N2_GT_N1(t *testing.T) {
 	helpers.ApproxEqual(t, 0.190147, schlick_val, 0.001)
 
 }
+
+func TestTransformMat4MatchesTransform(t *testing.T) {
+	mt := matrices.PerformOrderedChainingOps(matrices.ScalingMatrix(2, 3, 4), matrices.TranslationMatrix(3, 4, 5))
+	m4, _ := matrices.Mat4FromMatrix(mt)
+	r := NewRay(coordinates.CreatePoint(1, 2, 3), coordinates.CreateVector(0, 1, 0))
+
+	assert.Equal(t, Transform(r, mt), TransformMat4(r, m4))
+}
+
+func TestIntersectionTallyFollowsRayIntoGroups(t *testing.T) {
+	grp := NewGroup()
+	sph := NewCenteredSphere()
+	sph.SetTransformation(matrices.TranslationMatrix(0, 0, 5))
+	grp.IndoctrinateShapeToGroup(&sph)
+	grp.SetTransformation(matrices.ScalingMatrix(2, 2, 2))
+
+	tally := make(IntersectionTally)
+	r := NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1)).WithTally(tally)
+
+	Intersect(&grp, r)
+	Intersect(NewCube(), r)
+
+	assert.Equal(t, IntersectionTally{"Group": 1, "Sphere": 1, "Cube": 1}, tally)
+}
+
+func TestIntersectWithoutTallyCountsNothing(t *testing.T) {
+	r := NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1))
+
+	assert.Equal(t, 2, len(Intersect(NewCenteredSphere(), r)))
+	assert.Equal(t, r, TransformMat4(r, matrices.NewIdentityMat4()))
+}
+
+func BenchmarkIntersectTransformedSphere(b *testing.B) {
+	sph := NewCenteredSphere()
+	sph.SetTransformation(matrices.PerformOrderedChainingOps(matrices.ScalingMatrix(2, 2, 2), matrices.TranslationMatrix(1, 0, 3)))
+	r := NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1))
+	b.ReportAllocs()
+
+	for range b.N {
+		Intersect(sph, r)
+	}
+}
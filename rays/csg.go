@@ -0,0 +1,190 @@
+package rays
+
+import (
+	"rattata/coordinates"
+	"rattata/matrices"
+	"sort"
+
+	"github.com/gofrs/uuid"
+)
+
+// ---------------------------------- CSG ----------------------------------
+type CSGOperation uint8
+
+const (
+	CSGUnion CSGOperation = iota
+	CSGIntersection
+	CSGDifference
+)
+
+func (op CSGOperation) String() string {
+	switch op {
+	case CSGUnion:
+		return "union"
+	case CSGIntersection:
+		return "intersection"
+	case CSGDifference:
+		return "difference"
+	}
+	return "unknown"
+}
+
+/*
+Constructive solid geometry over two shapes.
+
+Both operands are held by an inner group which carries the transformation and parent of the CSG,
+so normals of the operands are oriented through the CSG exactly like they would be through a group.
+*/
+type CSG struct {
+	Operation CSGOperation
+	left      Shape
+	right     Shape
+	container *Group
+	id        string
+}
+
+func NewCSG(op CSGOperation, left, right IsGroupable) CSG {
+	new_uuid, _ := uuid.NewV4()
+	container := NewGroup()
+	container.IndoctrinateShapeToGroup(left)
+	container.IndoctrinateShapeToGroup(right)
+
+	return CSG{Operation: op, left: *left.GetRefAddress(), right: *right.GetRefAddress(), container: &container, id: new_uuid.String()}
+}
+
+func (c CSG) Id() string {
+	return c.id
+}
+
+func (c CSG) Name() string {
+	return "CSG"
+}
+
+func (c CSG) Left() Shape {
+	return c.left
+}
+
+func (c CSG) Right() Shape {
+	return c.right
+}
+
+func (c CSG) Transformation() matrices.Matrix {
+	return c.container.Transformation()
+}
+
+func (c CSG) InverseTransformation() matrices.Mat4 {
+	return c.container.InverseTransformation()
+}
+
+func (c CSG) InverseTransposeTransformation() matrices.Mat4 {
+	return c.container.InverseTransposeTransformation()
+}
+
+func (c *CSG) SetTransformation(mt matrices.Matrix) {
+	c.container.SetTransformation(mt)
+}
+
+func (c CSG) Parent() *Group {
+	return c.container.Parent()
+}
+
+func (c CSG) GetMaterial() Material {
+	panic("CSGs do not have materials")
+}
+
+func (c CSG) ContainedShapes() []Shape {
+	return []Shape{c.left, c.right}
+}
+
+func (c CSG) IntersectWithRay(ray_wrt_obj Ray) []Intersection {
+	if !c.Bounds().IntersectsRay(ray_wrt_obj) {
+		return []Intersection{}
+	}
+
+	all_intersections := make([]Intersection, 0)
+	all_intersections = append(all_intersections, Intersect(c.left, ray_wrt_obj)...)
+	all_intersections = append(all_intersections, Intersect(c.right, ray_wrt_obj)...)
+
+	sort.Slice(all_intersections, func(i, j int) bool {
+		return all_intersections[i].Tvalue <= all_intersections[j].Tvalue
+	})
+
+	return c.FilterIntersections(all_intersections)
+}
+
+/*
+Walks the sorted intersections while tracking whether the ray is inside either operand,
+keeping only those that lie on the surface of the combined shape.
+*/
+func (c CSG) FilterIntersections(xs []Intersection) []Intersection {
+	in_left, in_right := false, false
+	res := make([]Intersection, 0)
+
+	for _, i := range xs {
+		left_hit := Includes(c.left, i.Obj)
+
+		if CSGIntersectionAllowed(c.Operation, left_hit, in_left, in_right) {
+			res = append(res, i)
+		}
+
+		if left_hit {
+			in_left = !in_left
+		} else {
+			in_right = !in_right
+		}
+	}
+
+	return res
+}
+
+func (c CSG) NormalAtPoint(world_point coordinates.Coordinate) coordinates.Coordinate {
+	panic("CSGs do not have normals")
+}
+
+func (c CSG) Bounds() BoundingBox {
+	box := NewEmptyBoundingBox()
+	box.Merge(ParentSpaceBounds(c.left))
+	box.Merge(ParentSpaceBounds(c.right))
+	return box
+}
+
+func (c *CSG) SetParent(parent *Group) {
+	c.container.SetParent(parent)
+}
+
+func (c *CSG) GetRefAddress() *Shape {
+	var _shape Shape = c
+	return &_shape
+}
+
+func CSGIntersectionAllowed(op CSGOperation, left_hit, in_left, in_right bool) bool {
+	switch op {
+	case CSGUnion:
+		return (left_hit && !in_right) || (!left_hit && !in_left)
+	case CSGIntersection:
+		return (left_hit && in_right) || (!left_hit && in_left)
+	case CSGDifference:
+		return (left_hit && !in_right) || (!left_hit && in_left)
+	}
+	return false
+}
+
+type shapeContainer interface {
+	ContainedShapes() []Shape
+}
+
+/*
+Reports whether target is shape itself or lives anywhere underneath it (through groups and CSGs)
+*/
+func Includes(shape Shape, target Shape) bool {
+	if container, isContainer := shape.(shapeContainer); isContainer {
+		for _, child := range container.ContainedShapes() {
+			if Includes(child, target) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return shape.Id() == target.Id()
+}
@@ -0,0 +1,136 @@
+package rays
+
+import (
+	"rattata/coordinates"
+	"rattata/helpers"
+	"rattata/matrices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSGConstruction(t *testing.T) {
+	s1 := NewCenteredSphere()
+	s2 := NewCube()
+	c := NewCSG(CSGUnion, &s1, &s2)
+
+	assert.Equal(t, CSGUnion, c.Operation)
+	assert.Equal(t, s1.Id(), c.Left().Id())
+	assert.Equal(t, s2.Id(), c.Right().Id())
+	assert.NotNil(t, s1.Parent())
+	assert.Equal(t, s1.Parent(), s2.Parent())
+}
+
+func TestCSGRulesEvaluation(t *testing.T) {
+	for _, data := range []struct {
+		op                          CSGOperation
+		left_hit, in_left, in_right bool
+		result                      bool
+	}{
+		{CSGUnion, true, true, true, false},
+		{CSGUnion, true, true, false, true},
+		{CSGUnion, true, false, true, false},
+		{CSGUnion, true, false, false, true},
+		{CSGUnion, false, true, true, false},
+		{CSGUnion, false, true, false, false},
+		{CSGUnion, false, false, true, true},
+		{CSGUnion, false, false, false, true},
+		{CSGIntersection, true, true, true, true},
+		{CSGIntersection, true, true, false, false},
+		{CSGIntersection, true, false, true, true},
+		{CSGIntersection, true, false, false, false},
+		{CSGIntersection, false, true, true, true},
+		{CSGIntersection, false, true, false, true},
+		{CSGIntersection, false, false, true, false},
+		{CSGIntersection, false, false, false, false},
+		{CSGDifference, true, true, true, false},
+		{CSGDifference, true, true, false, true},
+		{CSGDifference, true, false, true, false},
+		{CSGDifference, true, false, false, true},
+		{CSGDifference, false, true, true, true},
+		{CSGDifference, false, true, false, true},
+		{CSGDifference, false, false, true, false},
+		{CSGDifference, false, false, false, false},
+	} {
+		assert.Equal(t, data.result, CSGIntersectionAllowed(data.op, data.left_hit, data.in_left, data.in_right),
+			"%s %v %v %v", data.op, data.left_hit, data.in_left, data.in_right)
+	}
+}
+
+func TestCSGFilterIntersections(t *testing.T) {
+	s1 := NewCenteredSphere()
+	s2 := NewCube()
+
+	for _, data := range []struct {
+		op     CSGOperation
+		x0, x1 int
+	}{
+		{CSGUnion, 0, 3},
+		{CSGIntersection, 1, 2},
+		{CSGDifference, 0, 1},
+	} {
+		c := NewCSG(data.op, &s1, &s2)
+		xs := Intersections(NewIntersection(1, s1), NewIntersection(2, s2), NewIntersection(3, s1), NewIntersection(4, s2))
+		res := c.FilterIntersections(xs)
+
+		assert.Equal(t, 2, len(res))
+		assert.Equal(t, xs[data.x0], res[0])
+		assert.Equal(t, xs[data.x1], res[1])
+	}
+}
+
+func TestCSGRayMiss(t *testing.T) {
+	s1 := NewCenteredSphere()
+	s2 := NewCube()
+	c := NewCSG(CSGUnion, &s1, &s2)
+
+	xs := c.IntersectWithRay(NewRay(coordinates.CreatePoint(0, 2, -5), coordinates.CreateVector(0, 0, 1)))
+	assert.Empty(t, xs)
+}
+
+func TestCSGRayHit(t *testing.T) {
+	s1 := NewCenteredSphere()
+	s2 := NewCenteredSphere()
+	s2.SetTransformation(matrices.TranslationMatrix(0, 0, 0.5))
+	c := NewCSG(CSGUnion, &s1, &s2)
+
+	xs := c.IntersectWithRay(NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1)))
+
+	assert.Equal(t, 2, len(xs))
+	helpers.ApproxEqual(t, 4, xs[0].Tvalue, 0.00001)
+	assert.Equal(t, s1.Id(), xs[0].Obj.Id())
+	helpers.ApproxEqual(t, 6.5, xs[1].Tvalue, 0.00001)
+	assert.Equal(t, s2.Id(), xs[1].Obj.Id())
+}
+
+func TestCSGIncludesRecursesThroughGroups(t *testing.T) {
+	s1 := NewCenteredSphere()
+	s2 := NewCube()
+	s3 := NewXZCylinder()
+
+	grp := NewGroup()
+	grp.IndoctrinateShapeToGroup(&s1)
+	c := NewCSG(CSGDifference, &grp, &s2)
+
+	assert.True(t, Includes(c.Left(), s1))
+	assert.False(t, Includes(c.Left(), s2))
+	assert.True(t, Includes(c, s2))
+	assert.False(t, Includes(c, s3))
+}
+
+func TestTransformedCSGNormal(t *testing.T) {
+	s1 := NewCenteredSphere()
+	s2 := NewCube()
+	s2.SetTransformation(matrices.TranslationMatrix(0, 0, 1))
+	c := NewCSG(CSGDifference, &s1, &s2)
+	c.SetTransformation(matrices.TranslationMatrix(5, 0, 0))
+
+	xs := Intersect(c, NewRay(coordinates.CreatePoint(5, 0, -5), coordinates.CreateVector(0, 0, 1)))
+
+	assert.Equal(t, 2, len(xs))
+	helpers.ApproxEqual(t, 4, xs[0].Tvalue, 0.00001)
+	helpers.ApproxEqual(t, 5, xs[1].Tvalue, 0.00001)
+
+	n := xs[1].Obj.NormalAtPoint(coordinates.CreatePoint(5, 0, 0))
+	helpers.TestApproxEqualCoordinate(t, coordinates.CreateVector(0, 0, -1), n, 0.00001)
+}
@@ -0,0 +1,684 @@
+package rays_test
+
+import (
+	"context"
+	"fmt"
+	"rattata/coordinates"
+	"rattata/features/steps"
+	"rattata/matrices"
+	"rattata/rays"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+// ---------------------------------- Scenario state ----------------------------------
+
+// Everything a scenario has named so far, shapes are held by pointer so later steps can change them
+type featureState struct {
+	*steps.State
+	shapes       map[string]rays.Shape
+	patterns     map[string]rays.Pattern
+	lights       map[string]rays.Light
+	intersection rays.Intersection
+	xs           []rays.Intersection
+}
+
+type featureStateKey struct{}
+
+func newFeatureState() *featureState {
+	return &featureState{
+		State:    steps.NewState(),
+		shapes:   make(map[string]rays.Shape),
+		patterns: make(map[string]rays.Pattern),
+		lights:   make(map[string]rays.Light),
+	}
+}
+
+func stateOf(ctx context.Context) *featureState {
+	return ctx.Value(featureStateKey{}).(*featureState)
+}
+
+func (st *featureState) shape(name string) (rays.Shape, error) {
+	shp, isKnown := st.shapes[name]
+	if !isKnown {
+		return nil, fmt.Errorf("no shape called %q", name)
+	}
+	return shp, nil
+}
+
+func (st *featureState) pattern(name string) (rays.Pattern, error) {
+	pat, isKnown := st.patterns[name]
+	if !isKnown {
+		return nil, fmt.Errorf("no pattern called %q", name)
+	}
+	return pat, nil
+}
+
+// ---------------------------------- Rays ----------------------------------
+
+func transformRay(ctx context.Context, name, ray_name, matrix_expr string) error {
+	st := stateOf(ctx)
+	r, err := st.Ray(ray_name)
+	if err != nil {
+		return err
+	}
+	mt, err := st.Matrix(matrix_expr)
+	if err != nil {
+		return err
+	}
+
+	st.Rays[name] = rays.Transform(r, mt)
+	return nil
+}
+
+func checkPosition(ctx context.Context, ray_name, t_expr, expr string) error {
+	st := stateOf(ctx)
+	r, err := st.Ray(ray_name)
+	if err != nil {
+		return err
+	}
+	t, err := steps.ParseNumber(t_expr)
+	if err != nil {
+		return err
+	}
+	expected, err := st.Tuple(expr)
+	if err != nil {
+		return err
+	}
+	return steps.ApproxEqualTuple(expected, *r.PointAtTime(t))
+}
+
+func reflectTuple(ctx context.Context, name, incidence_expr, normal_expr string) error {
+	st := stateOf(ctx)
+	incidence, err := st.Tuple(incidence_expr)
+	if err != nil {
+		return err
+	}
+	normal, err := st.Tuple(normal_expr)
+	if err != nil {
+		return err
+	}
+
+	st.Tuples[name] = rays.ReflectVector(incidence, normal)
+	return nil
+}
+
+// ---------------------------------- Lighting ----------------------------------
+
+func givenPointLight(ctx context.Context, name, position_expr, colour_expr string) error {
+	st := stateOf(ctx)
+	position, err := st.Tuple(position_expr)
+	if err != nil {
+		return err
+	}
+	col, err := st.Colour(colour_expr)
+	if err != nil {
+		return err
+	}
+
+	st.lights[name] = rays.NewLightSource(position.Get(coordinates.X), position.Get(coordinates.Y), position.Get(coordinates.Z), col)
+	return nil
+}
+
+func computeLighting(ctx context.Context, name, shape_name, light_name, position_name, eye_name, normal_name, shadow_expr string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+	light, isKnown := st.lights[light_name]
+	if !isKnown {
+		return fmt.Errorf("no light called %q", light_name)
+	}
+	shadow, err := steps.ParseNumber(shadow_expr)
+	if err != nil {
+		return err
+	}
+
+	var tuples [3]coordinates.Coordinate
+	for i, tuple_name := range []string{position_name, eye_name, normal_name} {
+		if tuples[i], err = st.Tuple(tuple_name); err != nil {
+			return err
+		}
+	}
+
+	st.Colours[name] = rays.Lighting(shp, light, tuples[0], tuples[1], tuples[2], shadow)
+	return nil
+}
+
+// ---------------------------------- Shapes ----------------------------------
+
+func givenShape(ctx context.Context, name, kind string) error {
+	var shp rays.Shape
+	switch kind {
+	case "sphere":
+		sph := rays.NewCenteredSphere()
+		shp = &sph
+	case "plane":
+		pl := rays.NewPlane(coordinates.CreatePoint(0, 0, 0))
+		shp = &pl
+	case "cube":
+		c := rays.NewCube()
+		shp = &c
+	case "cylinder":
+		cyl := rays.NewXZCylinder()
+		shp = &cyl
+	case "group":
+		grp := rays.NewGroup()
+		shp = &grp
+	default:
+		return fmt.Errorf("unknown shape %q", kind)
+	}
+
+	stateOf(ctx).shapes[name] = shp
+	return nil
+}
+
+func givenTriangle(ctx context.Context, name, p1_expr, p2_expr, p3_expr string) error {
+	st := stateOf(ctx)
+
+	var points [3]coordinates.Coordinate
+	for i, expr := range []string{p1_expr, p2_expr, p3_expr} {
+		p, err := st.Tuple(expr)
+		if err != nil {
+			return err
+		}
+		points[i] = p
+	}
+
+	tri := rays.NewTriangle(points[0], points[1], points[2])
+	st.shapes[name] = &tri
+	return nil
+}
+
+func setTransform(ctx context.Context, name, matrix_expr string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(name)
+	if err != nil {
+		return err
+	}
+	mt, err := st.Matrix(matrix_expr)
+	if err != nil {
+		return err
+	}
+
+	transformable, isTransformable := shp.(interface{ SetTransformation(matrices.Matrix) })
+	if !isTransformable {
+		return fmt.Errorf("%s cannot be transformed", name)
+	}
+	transformable.SetTransformation(mt)
+	return nil
+}
+
+func checkTransform(ctx context.Context, name, matrix_expr string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(name)
+	if err != nil {
+		return err
+	}
+	expected, err := st.Matrix(matrix_expr)
+	if err != nil {
+		return err
+	}
+
+	if !expected.IsEqual(shp.Transformation()) {
+		return fmt.Errorf("expected transformation %v, got %v", expected, shp.Transformation())
+	}
+	return nil
+}
+
+func checkMaterial(ctx context.Context, name, field, expr string) error {
+	shp, err := stateOf(ctx).shape(name)
+	if err != nil {
+		return err
+	}
+	expected, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+
+	mat := shp.GetMaterial()
+	actual := map[string]float64{"ambient": mat.Ambient, "diffuse": mat.Diffuse, "specular": mat.Specular, "shininess": mat.Shininess}[field]
+	return steps.ApproxEqualNumber(expected, actual)
+}
+
+func normalAt(ctx context.Context, name, shape_name, point_expr string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+	point, err := st.Tuple(point_expr)
+	if err != nil {
+		return err
+	}
+
+	st.Tuples[name] = shp.NormalAtPoint(point)
+	return nil
+}
+
+func checkNormalAt(ctx context.Context, shape_name, point_expr, expr string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+	point, err := st.Tuple(point_expr)
+	if err != nil {
+		return err
+	}
+	expected, err := st.Tuple(expr)
+	if err != nil {
+		return err
+	}
+	return steps.ApproxEqualTuple(expected, shp.NormalAtPoint(point))
+}
+
+func setCylinderLimit(ctx context.Context, name, field, expr string) error {
+	shp, err := stateOf(ctx).shape(name)
+	if err != nil {
+		return err
+	}
+	cyl, isCylinder := shp.(*rays.XZCylinder)
+	if !isCylinder {
+		return fmt.Errorf("%s is not a cylinder", name)
+	}
+
+	switch field {
+	case "closed":
+		cyl.Closed, err = strconv.ParseBool(expr)
+		return err
+	case "minimum":
+		cyl.Minimum, err = steps.ParseNumber(expr)
+	case "maximum":
+		cyl.Maximum, err = steps.ParseNumber(expr)
+	}
+	return err
+}
+
+func addChild(ctx context.Context, group_name, child_name string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(group_name)
+	if err != nil {
+		return err
+	}
+	grp, isGroup := shp.(*rays.Group)
+	if !isGroup {
+		return fmt.Errorf("%s is not a group", group_name)
+	}
+	child, err := st.shape(child_name)
+	if err != nil {
+		return err
+	}
+	groupable, isGroupable := child.(rays.IsGroupable)
+	if !isGroupable {
+		return fmt.Errorf("%s cannot join a group", child_name)
+	}
+
+	grp.IndoctrinateShapeToGroup(groupable)
+	return nil
+}
+
+func checkGroupEmptiness(ctx context.Context, name, not string) error {
+	shp, err := stateOf(ctx).shape(name)
+	if err != nil {
+		return err
+	}
+	grp, isGroup := shp.(*rays.Group)
+	if !isGroup {
+		return fmt.Errorf("%s is not a group", name)
+	}
+
+	if is_empty := len(grp.ContainedShapes()) == 0; is_empty != (not == "") {
+		return fmt.Errorf("%s holds %d shapes", name, len(grp.ContainedShapes()))
+	}
+	return nil
+}
+
+func checkParent(ctx context.Context, name, parent_name string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(name)
+	if err != nil {
+		return err
+	}
+	parent, err := st.shape(parent_name)
+	if err != nil {
+		return err
+	}
+
+	if shp.Parent() != parent {
+		return fmt.Errorf("%s is not the parent of %s", parent_name, name)
+	}
+	return nil
+}
+
+func checkTriangleField(ctx context.Context, name, field, expr string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(name)
+	if err != nil {
+		return err
+	}
+	tri, isTriangle := shp.(*rays.Triangle)
+	if !isTriangle {
+		return fmt.Errorf("%s is not a triangle", name)
+	}
+	expected, err := st.Tuple(expr)
+	if err != nil {
+		return err
+	}
+
+	actual := map[string]coordinates.Coordinate{"e1": tri.E1, "e2": tri.E2, "normal": tri.Normal}[field]
+	return steps.ApproxEqualTuple(expected, actual)
+}
+
+// ---------------------------------- Intersections ----------------------------------
+
+func givenIntersection(ctx context.Context, t_expr, shape_name string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+	t, err := steps.ParseNumber(t_expr)
+	if err != nil {
+		return err
+	}
+
+	st.intersection = rays.NewIntersection(t, shp)
+	return nil
+}
+
+func checkIntersectionT(ctx context.Context, expr string) error {
+	expected, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+	return steps.ApproxEqualNumber(expected, stateOf(ctx).intersection.Tvalue)
+}
+
+func checkIntersectionObject(ctx context.Context, shape_name string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+	if st.intersection.Obj.Id() != shp.Id() {
+		return fmt.Errorf("the intersection is not with %s", shape_name)
+	}
+	return nil
+}
+
+func givenIntersections(ctx context.Context, ts_expr, shape_name string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+
+	st.xs = make([]rays.Intersection, 0)
+	for _, expr := range strings.Split(ts_expr, ",") {
+		t, err := steps.ParseNumber(expr)
+		if err != nil {
+			return err
+		}
+		st.xs = append(st.xs, rays.NewIntersection(t, shp))
+	}
+	return nil
+}
+
+func checkHit(ctx context.Context, expr string) error {
+	expected, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+
+	hit, isHit := rays.Hit(stateOf(ctx).xs)
+	if !isHit {
+		return fmt.Errorf("expected a hit at t = %v, there is none", expected)
+	}
+	return steps.ApproxEqualNumber(expected, hit.Tvalue)
+}
+
+func checkNoHit(ctx context.Context) error {
+	if hit, isHit := rays.Hit(stateOf(ctx).xs); isHit {
+		return fmt.Errorf("expected no hit, got one at t = %v", hit.Tvalue)
+	}
+	return nil
+}
+
+func intersectShape(ctx context.Context, shape_name, ray_name string) error {
+	st := stateOf(ctx)
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+	r, err := st.Ray(ray_name)
+	if err != nil {
+		return err
+	}
+
+	st.xs = rays.Intersect(shp, r)
+	return nil
+}
+
+func checkIntersectionCount(ctx context.Context, count int) error {
+	if xs := stateOf(ctx).xs; len(xs) != count {
+		return fmt.Errorf("expected %d intersections, got %d", count, len(xs))
+	}
+	return nil
+}
+
+func nthIntersection(ctx context.Context, idx int) (rays.Intersection, error) {
+	xs := stateOf(ctx).xs
+	if idx >= len(xs) {
+		return rays.Intersection{}, fmt.Errorf("there are only %d intersections", len(xs))
+	}
+	return xs[idx], nil
+}
+
+func checkNthIntersectionT(ctx context.Context, idx int, expr string) error {
+	i, err := nthIntersection(ctx, idx)
+	if err != nil {
+		return err
+	}
+	expected, err := steps.ParseNumber(expr)
+	if err != nil {
+		return err
+	}
+	return steps.ApproxEqualNumber(expected, i.Tvalue)
+}
+
+func checkNthIntersectionObject(ctx context.Context, idx int, shape_name string) error {
+	i, err := nthIntersection(ctx, idx)
+	if err != nil {
+		return err
+	}
+	shp, err := stateOf(ctx).shape(shape_name)
+	if err != nil {
+		return err
+	}
+
+	if i.Obj.Id() != shp.Id() {
+		return fmt.Errorf("intersection %d is not with %s", idx, shape_name)
+	}
+	return nil
+}
+
+// ---------------------------------- Patterns ----------------------------------
+
+func givenPattern(ctx context.Context, name, kind, colour_a_expr, colour_b_expr string) error {
+	st := stateOf(ctx)
+	colour_a, err := st.Colour(colour_a_expr)
+	if err != nil {
+		return err
+	}
+	colour_b, err := st.Colour(colour_b_expr)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "stripe":
+		pat := rays.NewXStripe(colour_a, colour_b)
+		st.patterns[name] = &pat
+	case "gradient":
+		pat := rays.NewXGradient(colour_a, colour_b)
+		st.patterns[name] = &pat
+	case "ring":
+		pat := rays.NewXZRing(colour_a, colour_b)
+		st.patterns[name] = &pat
+	case "checkers":
+		pat := rays.NewChecker3D(colour_a, colour_b)
+		st.patterns[name] = &pat
+	default:
+		return fmt.Errorf("unknown pattern %q", kind)
+	}
+	return nil
+}
+
+func givenUVPattern(ctx context.Context, name, colour_a_expr, colour_b_expr, width_expr, height_expr string) error {
+	st := stateOf(ctx)
+	colour_a, err := st.Colour(colour_a_expr)
+	if err != nil {
+		return err
+	}
+	colour_b, err := st.Colour(colour_b_expr)
+	if err != nil {
+		return err
+	}
+	width, err := steps.ParseNumber(width_expr)
+	if err != nil {
+		return err
+	}
+	height, err := steps.ParseNumber(height_expr)
+	if err != nil {
+		return err
+	}
+
+	pat := rays.NewUnitSphereUVChecker(colour_a, colour_b, width, height)
+	st.patterns[name] = &pat
+	return nil
+}
+
+func setPatternTransform(ctx context.Context, name, matrix_expr string) error {
+	st := stateOf(ctx)
+	pat, err := st.pattern(name)
+	if err != nil {
+		return err
+	}
+	mt, err := st.Matrix(matrix_expr)
+	if err != nil {
+		return err
+	}
+
+	transformable, isTransformable := pat.(interface{ SetPatternTransformation(matrices.Matrix) })
+	if !isTransformable {
+		return fmt.Errorf("%s cannot be transformed", name)
+	}
+	transformable.SetPatternTransformation(mt)
+	return nil
+}
+
+func checkPatternAt(ctx context.Context, name, point_expr, colour_expr string) error {
+	st := stateOf(ctx)
+	pat, err := st.pattern(name)
+	if err != nil {
+		return err
+	}
+	point, err := st.Tuple(point_expr)
+	if err != nil {
+		return err
+	}
+	expected, err := st.Colour(colour_expr)
+	if err != nil {
+		return err
+	}
+	return steps.ApproxEqualColour(expected, pat.PatternAt(point))
+}
+
+func checkPatternAtShape(ctx context.Context, name, shape_name, point_expr, colour_expr string) error {
+	st := stateOf(ctx)
+	pat, err := st.pattern(name)
+	if err != nil {
+		return err
+	}
+	shp, err := st.shape(shape_name)
+	if err != nil {
+		return err
+	}
+	point, err := st.Tuple(point_expr)
+	if err != nil {
+		return err
+	}
+	expected, err := st.Colour(colour_expr)
+	if err != nil {
+		return err
+	}
+	return steps.ApproxEqualColour(expected, rays.PatternAtShape(shp, point, pat))
+}
+
+// ---------------------------------- Suite ----------------------------------
+
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
+		st := newFeatureState()
+		return context.WithValue(steps.WithState(ctx, st.State), featureStateKey{}, st), nil
+	})
+	steps.InitializeScenario(ctx)
+
+	ctx.Step(`^(\w+) ← transform\((\w+), `+steps.MATRIX+`\)$`, transformRay)
+	ctx.Step(`^position\((\w+), `+steps.NUMBER+`\) = `+steps.TUPLE+`$`, checkPosition)
+	ctx.Step(`^(\w+) ← reflect\(`+steps.TUPLE+`, `+steps.TUPLE+`\)$`, reflectTuple)
+
+	ctx.Step(`^(\w+) ← point_light\(`+steps.TUPLE+`, `+steps.COLOUR+`\)$`, givenPointLight)
+	ctx.Step(`^(\w+) ← lighting\((\w+), (\w+), (\w+), (\w+), (\w+), `+steps.NUMBER+`\)$`, computeLighting)
+
+	ctx.Step(`^(\w+) ← (sphere|plane|cube|cylinder|group)\(\)$`, givenShape)
+	ctx.Step(`^(\w+) ← triangle\(`+steps.TUPLE+`, `+steps.TUPLE+`, `+steps.TUPLE+`\)$`, givenTriangle)
+	ctx.Step(`^set_transform\((\w+), `+steps.MATRIX+`\)$`, setTransform)
+	ctx.Step(`^(\w+)\.transform = `+steps.MATRIX+`$`, checkTransform)
+	ctx.Step(`^(\w+)\.material\.(ambient|diffuse|specular|shininess) = `+steps.NUMBER+`$`, checkMaterial)
+	ctx.Step(`^(\w+) ← normal_at\((\w+), `+steps.TUPLE+`\)$`, normalAt)
+	ctx.Step(`^normal_at\((\w+), `+steps.TUPLE+`\) = `+steps.TUPLE+`$`, checkNormalAt)
+	ctx.Step(`^(\w+)\.(minimum|maximum|closed) ← (\S+)$`, setCylinderLimit)
+	ctx.Step(`^add_child\((\w+), (\w+)\)$`, addChild)
+	ctx.Step(`^(\w+) is (not )?empty$`, checkGroupEmptiness)
+	ctx.Step(`^(\w+)\.parent = (\w+)$`, checkParent)
+	ctx.Step(`^(\w+)\.(e1|e2|normal) = `+steps.TUPLE+`$`, checkTriangleField)
+
+	ctx.Step(`^i ← intersection\(`+steps.NUMBER+`, (\w+)\)$`, givenIntersection)
+	ctx.Step(`^i\.t = `+steps.NUMBER+`$`, checkIntersectionT)
+	ctx.Step(`^i\.object = (\w+)$`, checkIntersectionObject)
+	ctx.Step(`^xs ← intersections\(([^)]*)\) of (\w+)$`, givenIntersections)
+	ctx.Step(`^the hit has t = `+steps.NUMBER+`$`, checkHit)
+	ctx.Step(`^there is no hit$`, checkNoHit)
+	ctx.Step(`^xs ← intersect\((\w+), (\w+)\)$`, intersectShape)
+	ctx.Step(`^xs\.count = (\d+)$`, checkIntersectionCount)
+	ctx.Step(`^xs\[(\d+)\]\.t = `+steps.NUMBER+`$`, checkNthIntersectionT)
+	ctx.Step(`^xs\[(\d+)\]\.object = (\w+)$`, checkNthIntersectionObject)
+
+	ctx.Step(`^(\w+) ← (stripe|gradient|ring|checkers)_pattern\(`+steps.COLOUR+`, `+steps.COLOUR+`\)$`, givenPattern)
+	ctx.Step(`^(\w+) ← uv_checkers_pattern\(`+steps.COLOUR+`, `+steps.COLOUR+`, `+steps.NUMBER+`, `+steps.NUMBER+`\)$`, givenUVPattern)
+	ctx.Step(`^set_pattern_transform\((\w+), `+steps.MATRIX+`\)$`, setPatternTransform)
+	ctx.Step(`^pattern_at\((\w+), `+steps.TUPLE+`\) = `+steps.COLOUR+`$`, checkPatternAt)
+	ctx.Step(`^pattern_at_shape\((\w+), (\w+), `+steps.TUPLE+`\) = `+steps.COLOUR+`$`, checkPatternAtShape)
+}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: InitializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"../features/rays"},
+			Strict:   true,
+			TestingT: t, // Testing instance that will run subtests.
+		},
+	}
+
+	if suite.Run() != 0 {
+		t.Fatal("non-zero status returned, failed to run feature tests")
+	}
+}
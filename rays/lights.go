@@ -0,0 +1,226 @@
+package rays
+
+import (
+	"math"
+	"rattata/coordinates"
+)
+
+/*
+Anything that can light a surface. The shading code only talks to lights through this interface.
+
+A light is sampled Samples() times; every sample tells which way the light is from the shaded point and how far away it is.
+*/
+type LightSource interface {
+	Samples() int
+	SampleAt(i int, pos coordinates.Coordinate) LightSample
+	// Colour reaching pos before any shadowing, i.e. with falloff and attenuation applied
+	IntensityAt(pos coordinates.Coordinate) Colour
+}
+
+type LightSample struct {
+	Direction coordinates.Coordinate // normalised, from the shaded point towards the light
+	Distance  float64                // +Inf for lights that are infinitely far away
+}
+
+func sampleTowards(light_point, pos coordinates.Coordinate) LightSample {
+	_vec := light_point.Sub(&pos)
+	return LightSample{Direction: *_vec.Norm(), Distance: _vec.Magnitude()}
+}
+
+// ------------------------------------- Attenuation ------------------------------------
+
+/*
+Fades a light with distance d by 1 / (Constant + Linear*d + Quadratic*d*d).
+The zero value means no attenuation at all.
+*/
+type Attenuation struct {
+	Constant  float64
+	Linear    float64
+	Quadratic float64
+}
+
+func NewAttenuation(constant, linear, quadratic float64) Attenuation {
+	return Attenuation{Constant: constant, Linear: linear, Quadratic: quadratic}
+}
+
+func (a Attenuation) Factor(d float64) float64 {
+	if a == (Attenuation{}) {
+		return 1
+	}
+	return 1 / (a.Constant + a.Linear*d + a.Quadratic*d*d)
+}
+
+func attenuateColour(c Colour, a Attenuation, d float64) Colour {
+	if a == (Attenuation{}) {
+		return c
+	}
+	return c.Scale(a.Factor(d))
+}
+
+// ------------------------------------- Area Light ------------------------------------
+
+/*
+A rectangular light spanned by two edge vectors from a corner, split into USteps x VSteps cells.
+Every cell contributes one sample, placed at the cell centre or, with Jitter, somewhere random inside it.
+
+A light with a single cell and no extent behaves exactly like a point light at Origin.
+*/
+type AreaLight struct {
+	Origin coordinates.Coordinate // position of a point light, centre of an area light
+	Colour Colour
+	Corner coordinates.Coordinate
+	UVec   coordinates.Coordinate // one cell along the first edge
+	VVec   coordinates.Coordinate // one cell along the second edge
+	USteps int
+	VSteps int
+	Jitter bool
+
+	Attenuation Attenuation
+}
+
+func NewAreaLight(corner, full_uvec coordinates.Coordinate, usteps int, full_vvec coordinates.Coordinate, vsteps int, colour Colour) AreaLight {
+	uvec := *full_uvec.Div(float64(usteps))
+	vvec := *full_vvec.Div(float64(vsteps))
+
+	// from the cells rather than the edges, so that a light rebuilt from its cells ends up with the same centre
+	centre := *corner.Add(uvec.Mul(float64(usteps) / 2)).Add(vvec.Mul(float64(vsteps) / 2))
+
+	return AreaLight{Origin: centre, Colour: colour, Corner: corner, UVec: uvec, VVec: vvec, USteps: usteps, VSteps: vsteps}
+}
+
+/*
+Number of cells along each edge. Lights built without any steps are treated as a single cell
+*/
+func (l AreaLight) Steps() (int, int) {
+	return max(1, l.USteps), max(1, l.VSteps)
+}
+
+func (l AreaLight) Samples() int {
+	usteps, vsteps := l.Steps()
+	return usteps * vsteps
+}
+
+// Samples run through the cells row by row, u first
+func (l AreaLight) SampleAt(i int, pos coordinates.Coordinate) LightSample {
+	usteps, _ := l.Steps()
+	return sampleTowards(l.PointOnLight(i%usteps, i/usteps, pos), pos)
+}
+
+// Attenuation is measured from the centre of the light
+func (l AreaLight) IntensityAt(pos coordinates.Coordinate) Colour {
+	return attenuateColour(l.Colour, l.Attenuation, l.Origin.Sub(&pos).Magnitude())
+}
+
+/*
+Returns the sample point of cell (u, v) as seen when shading pos.
+The jitter only depends on pos and the cell, so shading and shadow tests on the same point agree on the samples.
+*/
+func (l AreaLight) PointOnLight(u, v int, pos coordinates.Coordinate) coordinates.Coordinate {
+	if l.USteps <= 0 || l.VSteps <= 0 {
+		return l.Origin
+	}
+
+	u_offset, v_offset := 0.5, 0.5
+	if l.Jitter {
+		u_offset, v_offset = jitterAt(pos, u, v, 0), jitterAt(pos, u, v, 1)
+	}
+
+	return *l.Corner.Add(l.UVec.Mul(float64(u) + u_offset)).Add(l.VVec.Mul(float64(v) + v_offset))
+}
+
+// Hashes the inputs down to a value in [0, 1), splitmix64 style
+func jitterAt(pos coordinates.Coordinate, u, v int, axis uint64) float64 {
+	h := uint64(u)*0x9e3779b97f4a7c15 ^ uint64(v)*0xc2b2ae3d27d4eb4f ^ axis
+	for i := range 3 {
+		h ^= math.Float64bits(pos[i]) + 0x9e3779b97f4a7c15 + (h << 6) + (h >> 2)
+	}
+
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+
+	return float64(h>>11) / (1 << 53)
+}
+
+// ------------------------------------- Spot Light ------------------------------------
+
+/*
+A point light that only shines inside a cone around Direction.
+Inside InnerAngle the light is at full strength, beyond OuterAngle it is off, in between it fades out smoothly.
+Both angles are measured from the cone axis, in radians.
+*/
+type SpotLight struct {
+	Position   coordinates.Coordinate
+	Direction  coordinates.Coordinate
+	InnerAngle float64
+	OuterAngle float64
+	Colour     Colour
+
+	Attenuation Attenuation
+}
+
+func NewSpotLight(position, direction coordinates.Coordinate, inner_angle, outer_angle float64, colour Colour) SpotLight {
+	return SpotLight{Position: position, Direction: *direction.Norm(), InnerAngle: inner_angle, OuterAngle: outer_angle, Colour: colour}
+}
+
+func (l SpotLight) Samples() int {
+	return 1
+}
+
+func (l SpotLight) SampleAt(i int, pos coordinates.Coordinate) LightSample {
+	return sampleTowards(l.Position, pos)
+}
+
+func (l SpotLight) IntensityAt(pos coordinates.Coordinate) Colour {
+	_vec := pos.Sub(&l.Position)
+	dist := _vec.Magnitude()
+	if dist == 0 {
+		return l.Colour
+	}
+
+	cos_angle := _vec.Norm().DotP(&l.Direction)
+	f := smoothStep(math.Cos(l.OuterAngle), math.Cos(l.InnerAngle), cos_angle)
+
+	return attenuateColour(l.Colour, l.Attenuation, dist).Scale(f)
+}
+
+func smoothStep(edge0, edge1, x float64) float64 {
+	if edge0 >= edge1 {
+		if x >= edge1 {
+			return 1
+		}
+		return 0
+	}
+
+	t := math.Max(0, math.Min(1, (x-edge0)/(edge1-edge0)))
+	return t * t * (3 - 2*t)
+}
+
+// ------------------------------------- Directional Light ------------------------------------
+
+/*
+A light infinitely far away, like the sun: every point is lit from the same direction with the same strength,
+and shadow rays run parallel without ever reaching the light.
+*/
+type DirectionalLight struct {
+	Direction coordinates.Coordinate // the way the light travels
+	Colour    Colour
+}
+
+func NewDirectionalLight(direction coordinates.Coordinate, colour Colour) DirectionalLight {
+	return DirectionalLight{Direction: *direction.Norm(), Colour: colour}
+}
+
+func (l DirectionalLight) Samples() int {
+	return 1
+}
+
+func (l DirectionalLight) SampleAt(i int, pos coordinates.Coordinate) LightSample {
+	return LightSample{Direction: *l.Direction.Negate(), Distance: math.Inf(1)}
+}
+
+func (l DirectionalLight) IntensityAt(pos coordinates.Coordinate) Colour {
+	return l.Colour
+}
@@ -0,0 +1,153 @@
+package rays
+
+import (
+	"math"
+	"rattata/coordinates"
+	"rattata/helpers"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAreaLight(t *testing.T) {
+	light := NewAreaLight(coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(2, 0, 0), 4, coordinates.CreateVector(0, 0, 1), 2, NewWhiteLightColour())
+
+	assert.Equal(t, coordinates.CreateVector(0.5, 0, 0), light.UVec)
+	assert.Equal(t, coordinates.CreateVector(0, 0, 0.5), light.VVec)
+	assert.Equal(t, 8, light.Samples())
+	assert.Equal(t, coordinates.CreatePoint(1, 0, 0.5), light.Origin)
+}
+
+func TestPointLightIsSingleCell(t *testing.T) {
+	light := NewLightSource(1, 2, 3, NewWhiteLightColour())
+	assert.Equal(t, 1, light.Samples())
+	assert.Equal(t, coordinates.CreatePoint(1, 2, 3), light.PointOnLight(0, 0, coordinates.CreatePoint(0, 0, 0)))
+
+	// lights spelled out without any cells still sit at their origin
+	literal := Light{Origin: coordinates.CreatePoint(1, 2, 3), Colour: NewWhiteLightColour()}
+	assert.Equal(t, 1, literal.Samples())
+	assert.Equal(t, coordinates.CreatePoint(1, 2, 3), literal.PointOnLight(0, 0, coordinates.CreatePoint(0, 0, 0)))
+}
+
+func TestPointOnAreaLight(t *testing.T) {
+	light := NewAreaLight(coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(2, 0, 0), 4, coordinates.CreateVector(0, 0, 1), 2, NewWhiteLightColour())
+	pos := coordinates.CreatePoint(0, 5, 0)
+
+	for _, data := range []struct {
+		u, v     int
+		expected coordinates.Coordinate
+	}{
+		{0, 0, coordinates.CreatePoint(0.25, 0, 0.25)},
+		{1, 0, coordinates.CreatePoint(0.75, 0, 0.25)},
+		{0, 1, coordinates.CreatePoint(0.25, 0, 0.75)},
+		{2, 0, coordinates.CreatePoint(1.25, 0, 0.25)},
+		{3, 1, coordinates.CreatePoint(1.75, 0, 0.75)},
+	} {
+		helpers.TestApproxEqualCoordinate(t, data.expected, light.PointOnLight(data.u, data.v, pos), 0.00001)
+	}
+}
+
+func TestJitteredPointStaysInCell(t *testing.T) {
+	light := NewAreaLight(coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(2, 0, 0), 4, coordinates.CreateVector(0, 0, 1), 2, NewWhiteLightColour())
+	light.Jitter = true
+	pos := coordinates.CreatePoint(0.3, 5, -1)
+
+	for v := range 2 {
+		for u := range 4 {
+			p := light.PointOnLight(u, v, pos)
+			assert.Equal(t, p, light.PointOnLight(u, v, pos))
+
+			assert.True(t, p.Get(coordinates.X) >= 0.5*float64(u) && p.Get(coordinates.X) < 0.5*float64(u+1))
+			assert.True(t, p.Get(coordinates.Z) >= 0.5*float64(v) && p.Get(coordinates.Z) < 0.5*float64(v+1))
+		}
+	}
+
+	assert.NotEqual(t, light.PointOnLight(0, 0, pos), light.PointOnLight(0, 0, coordinates.CreatePoint(0.3, 5, -1.1)))
+}
+
+func TestLightingWithAreaLight(t *testing.T) {
+	light := NewAreaLight(coordinates.CreatePoint(-0.5, -0.5, -5), coordinates.CreateVector(1, 0, 0), 2, coordinates.CreateVector(0, 1, 0), 2, NewWhiteLightColour())
+	sph := NewCenteredSphere()
+	sph.Material.Ambient, sph.Material.Diffuse, sph.Material.Specular = 0.1, 0.9, 0
+	sph.Material.Pattern = NewPlainPattern(Colour{1, 1, 1})
+	eye := coordinates.CreatePoint(0, 0, -5)
+
+	for _, data := range []struct {
+		point    coordinates.Coordinate
+		expected float64
+	}{
+		{coordinates.CreatePoint(0, 0, -1), 0.9965},
+		{coordinates.CreatePoint(0, 0.7071, -0.7071), 0.62318},
+	} {
+		eye_v := *eye.Sub(&data.point).Norm()
+		normal_v := sph.NormalAtPoint(data.point)
+
+		res := Lighting(sph, light, data.point, eye_v, normal_v, 0)
+		for i := range res {
+			helpers.ApproxEqual(t, data.expected, res[i], 0.0001)
+		}
+	}
+}
+
+func TestAttenuation(t *testing.T) {
+	assert.Equal(t, 1.0, Attenuation{}.Factor(100))
+	helpers.ApproxEqual(t, 1.0/(1+0.5*2+0.25*4), NewAttenuation(1, 0.5, 0.25).Factor(2), 0.000001)
+
+	light := NewLightSource(0, 0, -10, NewWhiteLightColour())
+	assert.Equal(t, NewWhiteLightColour(), light.IntensityAt(coordinates.CreatePoint(0, 0, 0)))
+
+	light.Attenuation = NewAttenuation(0, 0, 1)
+	helpers.ApproxEqual(t, 0.01, light.IntensityAt(coordinates.CreatePoint(0, 0, 0))[0], 0.000001)
+}
+
+func TestSpotLightFalloff(t *testing.T) {
+	light := NewSpotLight(coordinates.CreatePoint(0, 10, 0), coordinates.CreateVector(0, -1, 0), math.Pi/8, math.Pi/4, NewWhiteLightColour())
+	halfway := math.Acos((math.Cos(math.Pi/8) + math.Cos(math.Pi/4)) / 2)
+
+	assert.Equal(t, 1, light.Samples())
+	sample := light.SampleAt(0, coordinates.CreatePoint(0, 0, 0))
+	assert.Equal(t, coordinates.CreateVector(0, 1, 0), sample.Direction)
+	assert.Equal(t, 10.0, sample.Distance)
+
+	for _, data := range []struct {
+		point    coordinates.Coordinate
+		expected float64
+	}{
+		{coordinates.CreatePoint(0, 0, 0), 1},
+		{coordinates.CreatePoint(10*math.Tan(math.Pi/10), 0, 0), 1},
+		{coordinates.CreatePoint(10*math.Tan(halfway), 0, 0), 0.5},
+		{coordinates.CreatePoint(0, 0, 10*math.Tan(math.Pi/3)), 0},
+		{coordinates.CreatePoint(0, 20, 0), 0},
+	} {
+		helpers.ApproxEqual(t, data.expected, light.IntensityAt(data.point)[0], 0.00001)
+	}
+}
+
+func TestDirectionalLight(t *testing.T) {
+	light := NewDirectionalLight(coordinates.CreateVector(0, -2, 0), NewLightColour(1, 0.5, 0.25))
+
+	for _, pos := range []coordinates.Coordinate{coordinates.CreatePoint(0, 0, 0), coordinates.CreatePoint(100, -3, 7)} {
+		sample := light.SampleAt(0, pos)
+		assert.Equal(t, coordinates.CreateVector(0, 1, 0), sample.Direction)
+		assert.True(t, math.IsInf(sample.Distance, 1))
+		assert.Equal(t, NewLightColour(1, 0.5, 0.25), light.IntensityAt(pos))
+	}
+}
+
+func TestLightingWithSpotAndDirectionalLight(t *testing.T) {
+	sph := NewCenteredSphere()
+	position := coordinates.CreatePoint(0, 0, 0)
+	eyeV := coordinates.CreateVector(0, 0, -1)
+	normalV := coordinates.CreateVector(0, 0, -1)
+
+	// both shine straight onto the surface, same as a point light in front of it
+	point := Lighting(sph, NewLightSource(0, 0, -10, NewWhiteLightColour()), position, eyeV, normalV, 0)
+	directional := Lighting(sph, NewDirectionalLight(coordinates.CreateVector(0, 0, 1), NewWhiteLightColour()), position, eyeV, normalV, 0)
+	spot := Lighting(sph, NewSpotLight(coordinates.CreatePoint(0, 0, -10), coordinates.CreateVector(0, 0, 1), 0.1, 0.2, NewWhiteLightColour()), position, eyeV, normalV, 0)
+	assert.Equal(t, point, directional)
+	assert.Equal(t, point, spot)
+
+	// a spot pointing away leaves the point black, ambient is scaled by the intensity reaching the point as well
+	away := Lighting(sph, NewSpotLight(coordinates.CreatePoint(0, 0, -10), coordinates.CreateVector(0, 1, 0), 0.1, 0.2, NewWhiteLightColour()), position, eyeV, normalV, 0)
+	assert.Equal(t, Colour{0, 0, 0}, away)
+}
@@ -9,6 +9,7 @@ import (
 type Pattern interface {
 	PatternAt(point coordinates.Coordinate) Colour
 	PatternTransformation() matrices.Matrix
+	PatternInverseTransformation() matrices.Mat4
 }
 
 // ------------------------------------ No Pattern ------------------------------------
@@ -20,6 +21,10 @@ func NewPlainPattern(col Colour) PlainPattern {
 	return PlainPattern{col}
 }
 
+func (p PlainPattern) Colour() Colour {
+	return p.colorMain
+}
+
 func (p PlainPattern) PatternAt(point coordinates.Coordinate) Colour {
 	return p.colorMain
 }
@@ -28,21 +33,29 @@ func (p PlainPattern) PatternTransformation() matrices.Matrix {
 	return matrices.NewIdentityMatrix(4)
 }
 
+func (p PlainPattern) PatternInverseTransformation() matrices.Mat4 {
+	return matrices.NewIdentityMat4()
+}
+
 // ------------------------------------ Stripe Pattern ------------------------------------
 type XStripe struct {
 	colourA         Colour
 	colourB         Colour
 	transformMatrix matrices.Matrix
+	inverseMatrix   matrices.Mat4
 }
 
 func NewXStripe(colA, colB Colour) XStripe {
-	return XStripe{colA, colB, matrices.NewIdentityMatrix(4)}
+	return XStripe{colA, colB, matrices.NewIdentityMatrix(4), matrices.NewIdentityMat4()}
+}
+
+func (stripe XStripe) Colours() (Colour, Colour) {
+	return stripe.colourA, stripe.colourB
 }
 
 func (stripe XStripe) PatternAt(point coordinates.Coordinate) Colour {
 
-	patternTransformationInverse, _ := stripe.transformMatrix.Inverse()
-	pattern_point := matrices.MatrixToCoordinate(matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(point), patternTransformationInverse))
+	pattern_point := stripe.inverseMatrix.MulCoordinate(point)
 
 	if int(math.Floor(pattern_point.Get(coordinates.X)))%2 == 0 {
 		return stripe.colourA
@@ -55,8 +68,13 @@ func (stripe XStripe) PatternTransformation() matrices.Matrix {
 	return stripe.transformMatrix
 }
 
+func (stripe XStripe) PatternInverseTransformation() matrices.Mat4 {
+	return stripe.inverseMatrix
+}
+
 func (stripe *XStripe) SetPatternTransformation(_mat matrices.Matrix) {
 	stripe.transformMatrix = _mat
+	stripe.inverseMatrix = matrices.MustInvertTransformation(_mat)
 }
 
 // ------------------------------------ Gradient Pattern ------------------------------------
@@ -65,29 +83,36 @@ type XGradient struct {
 	colourA         Colour
 	colourB         Colour
 	transformMatrix matrices.Matrix
+	inverseMatrix   matrices.Mat4
 }
 
 func NewXGradient(colA, colB Colour) XGradient {
-	return XGradient{colA, colB, matrices.NewIdentityMatrix(4)}
+	return XGradient{colA, colB, matrices.NewIdentityMatrix(4), matrices.NewIdentityMat4()}
+}
+
+func (grad XGradient) Colours() (Colour, Colour) {
+	return grad.colourA, grad.colourB
 }
 
 func (grad XGradient) PatternAt(point coordinates.Coordinate) Colour {
 
-	patternTransformationInverse, _ := grad.transformMatrix.Inverse()
-	pattern_point := matrices.MatrixToCoordinate(matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(point), patternTransformationInverse))
+	pattern_point := grad.inverseMatrix.MulCoordinate(point)
 
-	distance := SubColour(grad.colourB, grad.colourA)
 	fraction := pattern_point.Get(coordinates.X) - math.Floor(pattern_point.Get(coordinates.X))
-	res := AddColour(grad.colourA, MulColour(distance, fraction))
-	return res
+	return grad.colourA.Lerp(grad.colourB, fraction)
 }
 
 func (grad XGradient) PatternTransformation() matrices.Matrix {
 	return grad.transformMatrix
 }
 
+func (grad XGradient) PatternInverseTransformation() matrices.Mat4 {
+	return grad.inverseMatrix
+}
+
 func (grad *XGradient) SetPatternTransformation(_mat matrices.Matrix) {
 	grad.transformMatrix = _mat
+	grad.inverseMatrix = matrices.MustInvertTransformation(_mat)
 }
 
 // ------------------------------------ Ring Pattern ------------------------------------
@@ -96,15 +121,19 @@ type XZRing struct {
 	colourA         Colour
 	colourB         Colour
 	transformMatrix matrices.Matrix
+	inverseMatrix   matrices.Mat4
 }
 
 func NewXZRing(colA, colB Colour) XZRing {
-	return XZRing{colA, colB, matrices.NewIdentityMatrix(4)}
+	return XZRing{colA, colB, matrices.NewIdentityMatrix(4), matrices.NewIdentityMat4()}
+}
+
+func (r XZRing) Colours() (Colour, Colour) {
+	return r.colourA, r.colourB
 }
 
 func (r XZRing) PatternAt(point coordinates.Coordinate) Colour {
-	patternTransformationInverse, _ := r.transformMatrix.Inverse()
-	pattern_point := matrices.MatrixToCoordinate(matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(point), patternTransformationInverse))
+	pattern_point := r.inverseMatrix.MulCoordinate(point)
 
 	if int(math.Floor(math.Sqrt(pattern_point.Get(coordinates.X)*pattern_point.Get(coordinates.X)+pattern_point.Get(coordinates.Z)*pattern_point.Get(coordinates.Z))))%2 == 0 {
 		return r.colourA
@@ -118,8 +147,13 @@ func (r XZRing) PatternTransformation() matrices.Matrix {
 	return r.transformMatrix
 }
 
+func (r XZRing) PatternInverseTransformation() matrices.Mat4 {
+	return r.inverseMatrix
+}
+
 func (r *XZRing) SetPatternTransformation(_mat matrices.Matrix) {
 	r.transformMatrix = _mat
+	r.inverseMatrix = matrices.MustInvertTransformation(_mat)
 }
 
 // ------------------------------------ Checker Pattern ------------------------------------
@@ -128,15 +162,19 @@ type Checker3D struct {
 	colourA         Colour
 	colourB         Colour
 	transformMatrix matrices.Matrix
+	inverseMatrix   matrices.Mat4
 }
 
 func NewChecker3D(colA, colB Colour) Checker3D {
-	return Checker3D{colA, colB, matrices.NewIdentityMatrix(4)}
+	return Checker3D{colA, colB, matrices.NewIdentityMatrix(4), matrices.NewIdentityMat4()}
+}
+
+func (chk Checker3D) Colours() (Colour, Colour) {
+	return chk.colourA, chk.colourB
 }
 
 func (chk Checker3D) PatternAt(point coordinates.Coordinate) Colour {
-	patternTransformationInverse, _ := chk.transformMatrix.Inverse()
-	pattern_point := matrices.MatrixToCoordinate(matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(point), patternTransformationInverse))
+	pattern_point := chk.inverseMatrix.MulCoordinate(point)
 
 	if (int(math.Floor(pattern_point.Get(coordinates.X))+math.Floor(pattern_point.Get(coordinates.Y))+math.Floor(pattern_point.Get(coordinates.Z))) % 2) == 0 {
 		return chk.colourA
@@ -150,8 +188,13 @@ func (chk Checker3D) PatternTransformation() matrices.Matrix {
 	return chk.transformMatrix
 }
 
+func (chk Checker3D) PatternInverseTransformation() matrices.Mat4 {
+	return chk.inverseMatrix
+}
+
 func (chk *Checker3D) SetPatternTransformation(_mat matrices.Matrix) {
 	chk.transformMatrix = _mat
+	chk.inverseMatrix = matrices.MustInvertTransformation(_mat)
 }
 
 // ------------------------------------ UV Checker Pattern ------------------------------------
@@ -160,17 +203,26 @@ type UnitSphereUVChecker struct {
 	colourA         Colour
 	colourB         Colour
 	transformMatrix matrices.Matrix
+	inverseMatrix   matrices.Mat4
 	width           float64
 	height          float64
 }
 
 func NewUnitSphereUVChecker(colA, colB Colour, width, height float64) UnitSphereUVChecker {
-	return UnitSphereUVChecker{colA, colB, matrices.NewIdentityMatrix(4), width, height}
+	return UnitSphereUVChecker{colA, colB, matrices.NewIdentityMatrix(4), matrices.NewIdentityMat4(), width, height}
+}
+
+func (chk UnitSphereUVChecker) Colours() (Colour, Colour) {
+	return chk.colourA, chk.colourB
+}
+
+// Number of checks around the sphere and from pole to pole
+func (chk UnitSphereUVChecker) Size() (float64, float64) {
+	return chk.width, chk.height
 }
 
 func (chk UnitSphereUVChecker) PatternAt(point coordinates.Coordinate) Colour {
-	patternTransformationInverse, _ := chk.transformMatrix.Inverse()
-	pattern_point := matrices.MatrixToCoordinate(matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(point), patternTransformationInverse))
+	pattern_point := chk.inverseMatrix.MulCoordinate(point)
 
 	u := 0.5 + math.Atan2(pattern_point.Get(coordinates.Z), pattern_point.Get(coordinates.X))/(2*math.Pi)
 	v := 0.5 + math.Asin(pattern_point.Get(coordinates.Y))/math.Pi
@@ -186,8 +238,13 @@ func (chk UnitSphereUVChecker) PatternTransformation() matrices.Matrix {
 	return chk.transformMatrix
 }
 
+func (chk UnitSphereUVChecker) PatternInverseTransformation() matrices.Mat4 {
+	return chk.inverseMatrix
+}
+
 func (chk *UnitSphereUVChecker) SetPatternTransformation(_mat matrices.Matrix) {
 	chk.transformMatrix = _mat
+	chk.inverseMatrix = matrices.MustInvertTransformation(_mat)
 }
 
 // ------------------------------------ Radial Gradient Pattern ------------------------------------
@@ -196,28 +253,35 @@ type XZRadialGradient struct {
 	colourA         Colour
 	colourB         Colour
 	transformMatrix matrices.Matrix
+	inverseMatrix   matrices.Mat4
 }
 
 func NewXZRadialGradient(colA, colB Colour) XZRadialGradient {
-	return XZRadialGradient{colA, colB, matrices.NewIdentityMatrix(4)}
+	return XZRadialGradient{colA, colB, matrices.NewIdentityMatrix(4), matrices.NewIdentityMat4()}
+}
+
+func (rg XZRadialGradient) Colours() (Colour, Colour) {
+	return rg.colourA, rg.colourB
 }
 
 func (rg XZRadialGradient) PatternAt(point coordinates.Coordinate) Colour {
-	patternTransformationInverse, _ := rg.transformMatrix.Inverse()
-	pattern_point := matrices.MatrixToCoordinate(matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(point), patternTransformationInverse))
+	pattern_point := rg.inverseMatrix.MulCoordinate(point)
 
-	distance := SubColour(rg.colourB, rg.colourA)
 	fraction := math.Sqrt(pattern_point.Get(coordinates.X)*pattern_point.Get(coordinates.X) + pattern_point.Get(coordinates.Z)*pattern_point.Get(coordinates.Z))
-	res := AddColour(rg.colourA, MulColour(distance, fraction))
-	return res
+	return rg.colourA.Lerp(rg.colourB, fraction)
 }
 
 func (rg XZRadialGradient) PatternTransformation() matrices.Matrix {
 	return rg.transformMatrix
 }
 
+func (rg XZRadialGradient) PatternInverseTransformation() matrices.Mat4 {
+	return rg.inverseMatrix
+}
+
 func (rg *XZRadialGradient) SetPatternTransformation(_mat matrices.Matrix) {
 	rg.transformMatrix = _mat
+	rg.inverseMatrix = matrices.MustInvertTransformation(_mat)
 }
 
 // ------------------------------------ Perturbed Pattern ------------------------------------
@@ -226,15 +290,23 @@ type Perturbed struct {
 	basePattern     Pattern
 	perturbAmount   float64
 	transformMatrix matrices.Matrix
+	inverseMatrix   matrices.Mat4
 }
 
 func NewPerturbedPattern(base Pattern, perturbAmt float64) Perturbed {
-	return Perturbed{base, perturbAmt, matrices.NewIdentityMatrix(4)}
+	return Perturbed{base, perturbAmt, matrices.NewIdentityMatrix(4), matrices.NewIdentityMat4()}
+}
+
+func (p Perturbed) Base() Pattern {
+	return p.basePattern
+}
+
+func (p Perturbed) Amount() float64 {
+	return p.perturbAmount
 }
 
 func (p Perturbed) PatternAt(point coordinates.Coordinate) Colour {
-	patternTransformationInverse, _ := p.transformMatrix.Inverse()
-	pattern_point := matrices.MatrixToCoordinate(matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(point), patternTransformationInverse))
+	pattern_point := p.inverseMatrix.MulCoordinate(point)
 
 	x2 := pattern_point.Get(coordinates.X) + (PerlinNoise3D(pattern_point.Get(coordinates.X), pattern_point.Get(coordinates.Y), pattern_point.Get(coordinates.Z)) * p.perturbAmount)
 	y2 := pattern_point.Get(coordinates.Y) + (PerlinNoise3D(pattern_point.Get(coordinates.Y), pattern_point.Get(coordinates.Z), pattern_point.Get(coordinates.X)) * p.perturbAmount)
@@ -247,8 +319,13 @@ func (p Perturbed) PatternTransformation() matrices.Matrix {
 	return p.transformMatrix
 }
 
+func (p Perturbed) PatternInverseTransformation() matrices.Mat4 {
+	return p.inverseMatrix
+}
+
 func (p *Perturbed) SetPatternTransformation(_mat matrices.Matrix) {
 	p.transformMatrix = _mat
+	p.inverseMatrix = matrices.MustInvertTransformation(_mat)
 }
 
 func PerlinNoise3D(x, y, z float64) float64 {
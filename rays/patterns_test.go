@@ -152,3 +152,28 @@ func TestUnitSphereUVChecker(t *testing.T) {
 		t.Errorf("Expected UV checker pattern to return colourB at (-0.707,-0.707,0)")
 	}
 }
+
+// ------------------------------------ Accessors ------------------------------------
+
+func TestPatternAccessors(t *testing.T) {
+	if NewPlainPattern(Colour{1, 0, 0}).Colour() != (Colour{1, 0, 0}) {
+		t.Errorf("Expected plain pattern to hand back its colour")
+	}
+
+	a, b := NewXStripe(Colour{1, 0, 0}, Colour{0, 0, 1}).Colours()
+	if a != (Colour{1, 0, 0}) || b != (Colour{0, 0, 1}) {
+		t.Errorf("Expected stripe pattern to hand back both colours in order")
+	}
+
+	w, h := NewUnitSphereUVChecker(Colour{1, 1, 1}, Colour{0, 0, 0}, 16, 8).Size()
+	if w != 16 || h != 8 {
+		t.Errorf("Expected UV checker to hand back its width and height")
+	}
+
+	stripe := NewXStripe(Colour{1, 1, 1}, Colour{0, 0, 0})
+	stripe.SetPatternTransformation(matrices.ScalingMatrix(2, 2, 2))
+	perturbed := NewPerturbedPattern(stripe, 0.3)
+	if perturbed.Amount() != 0.3 || !perturbed.Base().PatternTransformation().IsEqual(matrices.ScalingMatrix(2, 2, 2)) {
+		t.Errorf("Expected perturbed pattern to hand back its base pattern and amount")
+	}
+}
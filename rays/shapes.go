@@ -1,7 +1,6 @@
 package rays
 
 import (
-	"fmt"
 	"math"
 	"rattata/coordinates"
 	"rattata/matrices"
@@ -14,6 +13,8 @@ import (
 type Shape interface {
 	Name() string
 	Transformation() matrices.Matrix
+	InverseTransformation() matrices.Mat4
+	InverseTransposeTransformation() matrices.Mat4
 	IntersectWithRay(ray_wrt_obj Ray) []Intersection
 	/*
 		Returns the normalized vector perpendicular to the shape at the given world point
@@ -22,6 +23,10 @@ type Shape interface {
 	GetMaterial() Material
 	Id() string
 	Parent() *Group
+	/*
+		Returns the axis aligned box enclosing the shape in its own object space
+	*/
+	Bounds() BoundingBox
 }
 
 type IsGroupable interface {
@@ -31,12 +36,14 @@ type IsGroupable interface {
 
 // ---------------------------------- Sphere ----------------------------------
 type Sphere struct {
-	Origin            coordinates.Coordinate
-	Radius            float64
-	transformationMat matrices.Matrix
-	Material          Material
-	id                string
-	parent            *Group
+	Origin              coordinates.Coordinate
+	Radius              float64
+	transformationMat   matrices.Matrix
+	inverseMat          matrices.Mat4
+	inverseTransposeMat matrices.Mat4
+	Material            Material
+	id                  string
+	parent              *Group
 }
 
 func NewSphere(origin coordinates.Coordinate, radius float64) Sphere {
@@ -45,7 +52,7 @@ func NewSphere(origin coordinates.Coordinate, radius float64) Sphere {
 	}
 
 	new_uuid, _ := uuid.NewV4()
-	return Sphere{Origin: origin, Radius: radius, transformationMat: matrices.NewIdentityMatrix(4), Material: CreateDefaultMaterial(), id: new_uuid.String()}
+	return Sphere{Origin: origin, Radius: radius, transformationMat: matrices.NewIdentityMatrix(4), inverseMat: matrices.NewIdentityMat4(), inverseTransposeMat: matrices.NewIdentityMat4(), Material: CreateDefaultMaterial(), id: new_uuid.String()}
 }
 
 func NewCenteredSphere() Sphere {
@@ -71,8 +78,18 @@ func (s Sphere) Transformation() matrices.Matrix {
 	return s.transformationMat
 }
 
+func (s Sphere) InverseTransformation() matrices.Mat4 {
+	return s.inverseMat
+}
+
+func (s Sphere) InverseTransposeTransformation() matrices.Mat4 {
+	return s.inverseTransposeMat
+}
+
 func (s *Sphere) SetTransformation(mt matrices.Matrix) {
 	s.transformationMat = mt
+	s.inverseMat, s.inverseTransposeMat = invertTransformation(mt)
+	invalidateBounds(s.parent)
 }
 
 func (s Sphere) Parent() *Group {
@@ -106,6 +123,14 @@ func (s Sphere) NormalAtPoint(world_point coordinates.Coordinate) coordinates.Co
 	return normal_to_world_orientation(s, obj_normal)
 }
 
+func (s Sphere) Bounds() BoundingBox {
+	// the intersection treats Radius as the squared radius, so take whichever extent is larger
+	extent := math.Max(s.Radius, math.Sqrt(s.Radius))
+	return NewBoundingBox(
+		coordinates.CreatePoint(s.Origin.Get(coordinates.X)-extent, s.Origin.Get(coordinates.Y)-extent, s.Origin.Get(coordinates.Z)-extent),
+		coordinates.CreatePoint(s.Origin.Get(coordinates.X)+extent, s.Origin.Get(coordinates.Y)+extent, s.Origin.Get(coordinates.Z)+extent))
+}
+
 func (s *Sphere) SetParent(parent *Group) {
 	s.parent = parent
 }
@@ -117,11 +142,13 @@ func (s *Sphere) GetRefAddress() *Shape {
 
 // ---------------------------------- XZPlane ----------------------------------
 type XZPlane struct {
-	Origin            coordinates.Coordinate
-	transformationMat matrices.Matrix
-	Material          Material
-	id                string
-	parent            *Group
+	Origin              coordinates.Coordinate
+	transformationMat   matrices.Matrix
+	inverseMat          matrices.Mat4
+	inverseTransposeMat matrices.Mat4
+	Material            Material
+	id                  string
+	parent              *Group
 }
 
 func NewPlane(origin coordinates.Coordinate) XZPlane {
@@ -131,7 +158,7 @@ func NewPlane(origin coordinates.Coordinate) XZPlane {
 
 	new_uuid, _ := uuid.NewV4()
 
-	return XZPlane{Origin: origin, transformationMat: matrices.NewIdentityMatrix(4), Material: CreateDefaultMaterial(), id: new_uuid.String()}
+	return XZPlane{Origin: origin, transformationMat: matrices.NewIdentityMatrix(4), inverseMat: matrices.NewIdentityMat4(), inverseTransposeMat: matrices.NewIdentityMat4(), Material: CreateDefaultMaterial(), id: new_uuid.String()}
 }
 
 func (p XZPlane) Name() string {
@@ -146,8 +173,18 @@ func (p XZPlane) Transformation() matrices.Matrix {
 	return p.transformationMat
 }
 
+func (p XZPlane) InverseTransformation() matrices.Mat4 {
+	return p.inverseMat
+}
+
+func (p XZPlane) InverseTransposeTransformation() matrices.Mat4 {
+	return p.inverseTransposeMat
+}
+
 func (p *XZPlane) SetTransformation(mt matrices.Matrix) {
 	p.transformationMat = mt
+	p.inverseMat, p.inverseTransposeMat = invertTransformation(mt)
+	invalidateBounds(p.parent)
 }
 
 func (p XZPlane) Parent() *Group {
@@ -172,6 +209,10 @@ func (p XZPlane) NormalAtPoint(world_point coordinates.Coordinate) coordinates.C
 	return normal_to_world_orientation(p, obj_normal)
 }
 
+func (p XZPlane) Bounds() BoundingBox {
+	return NewBoundingBox(coordinates.CreatePoint(math.Inf(-1), 0, math.Inf(-1)), coordinates.CreatePoint(math.Inf(1), 0, math.Inf(1)))
+}
+
 func (p *XZPlane) SetParent(parent *Group) {
 	p.parent = parent
 }
@@ -184,15 +225,17 @@ func (p *XZPlane) GetRefAddress() *Shape {
 // ---------------------------------- Cube ----------------------------------
 
 type Cube struct {
-	transformationMat matrices.Matrix
-	Material          Material
-	id                string
-	parent            *Group
+	transformationMat   matrices.Matrix
+	inverseMat          matrices.Mat4
+	inverseTransposeMat matrices.Mat4
+	Material            Material
+	id                  string
+	parent              *Group
 }
 
 func NewCube() Cube {
 	new_uuid, _ := uuid.NewV4()
-	return Cube{transformationMat: matrices.NewIdentityMatrix(4), Material: CreateDefaultMaterial(), id: new_uuid.String()}
+	return Cube{transformationMat: matrices.NewIdentityMatrix(4), inverseMat: matrices.NewIdentityMat4(), inverseTransposeMat: matrices.NewIdentityMat4(), Material: CreateDefaultMaterial(), id: new_uuid.String()}
 }
 
 func (c Cube) Id() string {
@@ -207,8 +250,18 @@ func (c Cube) Transformation() matrices.Matrix {
 	return c.transformationMat
 }
 
+func (c Cube) InverseTransformation() matrices.Mat4 {
+	return c.inverseMat
+}
+
+func (c Cube) InverseTransposeTransformation() matrices.Mat4 {
+	return c.inverseTransposeMat
+}
+
 func (c *Cube) SetTransformation(mt matrices.Matrix) {
 	c.transformationMat = mt
+	c.inverseMat, c.inverseTransposeMat = invertTransformation(mt)
+	invalidateBounds(c.parent)
 }
 
 func (c Cube) Parent() *Group {
@@ -272,6 +325,10 @@ func (c Cube) NormalAtPoint(world_point coordinates.Coordinate) coordinates.Coor
 	return normal_to_world_orientation(c, normal_v)
 }
 
+func (c Cube) Bounds() BoundingBox {
+	return NewBoundingBox(coordinates.CreatePoint(-1, -1, -1), coordinates.CreatePoint(1, 1, 1))
+}
+
 func (c *Cube) SetParent(parent *Group) {
 	c.parent = parent
 }
@@ -284,18 +341,20 @@ func (c *Cube) GetRefAddress() *Shape {
 // ---------------------------------- XZCylinder ----------------------------------
 
 type XZCylinder struct {
-	transformationMat matrices.Matrix
-	Material          Material
-	Minimum           float64
-	Maximum           float64
-	Closed            bool
-	id                string
-	parent            *Group
+	transformationMat   matrices.Matrix
+	inverseMat          matrices.Mat4
+	inverseTransposeMat matrices.Mat4
+	Material            Material
+	Minimum             float64
+	Maximum             float64
+	Closed              bool
+	id                  string
+	parent              *Group
 }
 
 func NewXZCylinder() XZCylinder {
 	new_uuid, _ := uuid.NewV4()
-	return XZCylinder{transformationMat: matrices.NewIdentityMatrix(4), Material: CreateDefaultMaterial(), id: new_uuid.String(),
+	return XZCylinder{transformationMat: matrices.NewIdentityMatrix(4), inverseMat: matrices.NewIdentityMat4(), inverseTransposeMat: matrices.NewIdentityMat4(), Material: CreateDefaultMaterial(), id: new_uuid.String(),
 		Minimum: math.Inf(-1), Maximum: math.Inf(1), Closed: false}
 }
 
@@ -311,8 +370,18 @@ func (cy XZCylinder) Transformation() matrices.Matrix {
 	return cy.transformationMat
 }
 
+func (cy XZCylinder) InverseTransformation() matrices.Mat4 {
+	return cy.inverseMat
+}
+
+func (cy XZCylinder) InverseTransposeTransformation() matrices.Mat4 {
+	return cy.inverseTransposeMat
+}
+
 func (cy *XZCylinder) SetTransformation(mt matrices.Matrix) {
 	cy.transformationMat = mt
+	cy.inverseMat, cy.inverseTransposeMat = invertTransformation(mt)
+	invalidateBounds(cy.parent)
 }
 
 func (cy XZCylinder) Parent() *Group {
@@ -409,6 +478,10 @@ func (cy XZCylinder) NormalAtPoint(world_point coordinates.Coordinate) coordinat
 
 }
 
+func (cy XZCylinder) Bounds() BoundingBox {
+	return NewBoundingBox(coordinates.CreatePoint(-1, cy.Minimum, -1), coordinates.CreatePoint(1, cy.Maximum, 1))
+}
+
 func (cy *XZCylinder) SetParent(parent *Group) {
 	cy.parent = parent
 }
@@ -421,18 +494,20 @@ func (cy *XZCylinder) GetRefAddress() *Shape {
 // ---------------------------------- Cone ----------------------------------
 
 type Cone struct {
-	transformationMat matrices.Matrix
-	Material          Material
-	Minimum           float64
-	Maximum           float64
-	Closed            bool
-	id                string
-	parent            *Group
+	transformationMat   matrices.Matrix
+	inverseMat          matrices.Mat4
+	inverseTransposeMat matrices.Mat4
+	Material            Material
+	Minimum             float64
+	Maximum             float64
+	Closed              bool
+	id                  string
+	parent              *Group
 }
 
 func NewDoubleNappedCone() Cone {
 	new_uuid, _ := uuid.NewV4()
-	return Cone{transformationMat: matrices.NewIdentityMatrix(4), Material: CreateDefaultMaterial(), id: new_uuid.String(),
+	return Cone{transformationMat: matrices.NewIdentityMatrix(4), inverseMat: matrices.NewIdentityMat4(), inverseTransposeMat: matrices.NewIdentityMat4(), Material: CreateDefaultMaterial(), id: new_uuid.String(),
 		Minimum: math.Inf(-1), Maximum: math.Inf(1), Closed: false}
 }
 
@@ -448,8 +523,18 @@ func (co Cone) Transformation() matrices.Matrix {
 	return co.transformationMat
 }
 
+func (co Cone) InverseTransformation() matrices.Mat4 {
+	return co.inverseMat
+}
+
+func (co Cone) InverseTransposeTransformation() matrices.Mat4 {
+	return co.inverseTransposeMat
+}
+
 func (co *Cone) SetTransformation(mt matrices.Matrix) {
 	co.transformationMat = mt
+	co.inverseMat, co.inverseTransposeMat = invertTransformation(mt)
+	invalidateBounds(co.parent)
 }
 
 func (co Cone) Parent() *Group {
@@ -497,9 +582,6 @@ func (co Cone) checkCircularIntersection(ray_wrt_obj Ray) []Intersection {
 	t1 := (-b - math.Sqrt(discriminant)) / (2 * a)
 	t2 := (-b + math.Sqrt(discriminant)) / (2 * a)
 
-	fmt.Println(discriminant)
-	fmt.Println(t1, t2)
-
 	res := make([]Intersection, 0)
 
 	if ray_wrt_obj.Origin.Get(coordinates.Y)+t1*ray_wrt_obj.Direction.Get(coordinates.Y) > co.Minimum &&
@@ -566,6 +648,11 @@ func (co Cone) NormalAtPoint(world_point coordinates.Coordinate) coordinates.Coo
 	return normal_to_world_orientation(co, normal_v)
 }
 
+func (co Cone) Bounds() BoundingBox {
+	limit := math.Max(math.Abs(co.Minimum), math.Abs(co.Maximum))
+	return NewBoundingBox(coordinates.CreatePoint(-limit, co.Minimum, -limit), coordinates.CreatePoint(limit, co.Maximum, limit))
+}
+
 func (co *Cone) SetParent(parent *Group) {
 	co.parent = parent
 }
@@ -577,16 +664,23 @@ func (co *Cone) GetRefAddress() *Shape {
 
 // ---------------------------------- Group ----------------------------------
 
+/*
+Groups cache their bounds and drop the cache when children are added or re-transformed.
+Editing a child's geometry fields in place is not noticed, call RefreshBounds afterwards (rendering does so itself).
+*/
 type Group struct {
-	containedShapes   []*Shape
-	transformationMat matrices.Matrix
-	id                string
-	parent            *Group
+	containedShapes     []*Shape
+	transformationMat   matrices.Matrix
+	inverseMat          matrices.Mat4
+	inverseTransposeMat matrices.Mat4
+	id                  string
+	parent              *Group
+	bounds              *boundsCache
 }
 
 func NewGroup() Group {
 	new_uuid, _ := uuid.NewV4()
-	return Group{containedShapes: make([]*Shape, 0), transformationMat: matrices.NewIdentityMatrix(4), id: new_uuid.String()}
+	return Group{containedShapes: make([]*Shape, 0), transformationMat: matrices.NewIdentityMatrix(4), inverseMat: matrices.NewIdentityMat4(), inverseTransposeMat: matrices.NewIdentityMat4(), id: new_uuid.String(), bounds: &boundsCache{}}
 }
 
 func (g Group) Id() string {
@@ -601,8 +695,18 @@ func (g Group) Transformation() matrices.Matrix {
 	return g.transformationMat
 }
 
+func (g Group) InverseTransformation() matrices.Mat4 {
+	return g.inverseMat
+}
+
+func (g Group) InverseTransposeTransformation() matrices.Mat4 {
+	return g.inverseTransposeMat
+}
+
 func (g *Group) SetTransformation(mt matrices.Matrix) {
 	g.transformationMat = mt
+	g.inverseMat, g.inverseTransposeMat = invertTransformation(mt)
+	invalidateBounds(g.parent)
 }
 
 func (g Group) Parent() *Group {
@@ -614,7 +718,7 @@ func (g Group) GetMaterial() Material {
 }
 
 func (g Group) IntersectWithRay(ray_wrt_obj Ray) []Intersection {
-	if len(g.containedShapes) == 0 {
+	if len(g.containedShapes) == 0 || !g.Bounds().IntersectsRay(ray_wrt_obj) {
 		return []Intersection{}
 	}
 
@@ -634,9 +738,41 @@ func (g Group) NormalAtPoint(world_point coordinates.Coordinate) coordinates.Coo
 	panic("Groups do not have normals")
 }
 
+func (g Group) Bounds() BoundingBox {
+	if g.bounds == nil {
+		return g.childBounds()
+	}
+
+	if box := g.bounds.box.Load(); box != nil {
+		return *box
+	}
+
+	// workers racing to fill an empty cache all compute the same box, the first one to finish is kept
+	box := g.childBounds()
+	g.bounds.box.CompareAndSwap(nil, &box)
+	return box
+}
+
+func (g Group) childBounds() BoundingBox {
+	box := NewEmptyBoundingBox()
+	for _, shape_ptr := range g.containedShapes {
+		box.Merge(ParentSpaceBounds(*shape_ptr))
+	}
+	return box
+}
+
 func (g *Group) IndoctrinateShapeToGroup(shape_to_indoctrinate IsGroupable) {
 	g.containedShapes = append(g.containedShapes, shape_to_indoctrinate.GetRefAddress())
 	shape_to_indoctrinate.SetParent(g)
+	invalidateBounds(g)
+}
+
+func (g Group) ContainedShapes() []Shape {
+	res := make([]Shape, len(g.containedShapes))
+	for i, shape_ptr := range g.containedShapes {
+		res[i] = *shape_ptr
+	}
+	return res
 }
 
 func (g *Group) SetParent(p *Group) {
@@ -648,15 +784,19 @@ func (g *Group) GetRefAddress() *Shape {
 	return &_shape
 }
 
+// Inverts the transformation once, so that rays and normals do not have to on every call
+func invertTransformation(mt matrices.Matrix) (matrices.Mat4, matrices.Mat4) {
+	inverse := matrices.MustInvertTransformation(mt)
+	return inverse, inverse.T()
+}
+
 func world_to_object_orientation(shape Shape, world_coord coordinates.Coordinate) coordinates.Coordinate {
 	cur_coord := world_coord
 	if shape.Parent() != nil {
 		cur_coord = world_to_object_orientation(*shape.Parent(), cur_coord)
 	}
 
-	inv_transform, _ := shape.Transformation().Inverse()
-	cur_coord_mat := matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(cur_coord), inv_transform)
-	return matrices.MatrixToCoordinate(cur_coord_mat)
+	return shape.InverseTransformation().MulCoordinate(cur_coord)
 }
 
 func object_to_world_orientation(shape Shape, object_coord coordinates.Coordinate) coordinates.Coordinate {
@@ -674,10 +814,7 @@ func object_to_world_orientation(shape Shape, object_coord coordinates.Coordinat
 
 func normal_to_world_orientation(shape Shape, object_normal_v coordinates.Coordinate) coordinates.Coordinate {
 
-	inverse_transformation, _ := shape.Transformation().Inverse()
-	world_normal := matrices.PerformOrderedChainingOps(matrices.CoordinateToMatrix(object_normal_v), inverse_transformation.T())
-	res := matrices.MatrixToCoordinate(world_normal)
-	res.Set(coordinates.W, 0)
+	res := shape.InverseTransposeTransformation().MulVector(object_normal_v)
 	res = *res.Norm()
 
 	if shape.Parent() != nil {
@@ -23,6 +23,27 @@ func TestTransformationMatrixInShape(t *testing.T) {
 	assert.Equal(t, rotationMat, sph.Transformation())
 }
 
+func TestInverseTransformationCachedOnShapes(t *testing.T) {
+	mt := matrices.PerformOrderedChainingOps(matrices.ScalingMatrix(2, 1, 0.5), matrices.GivensRotationMatrix3D(coordinates.Y, math.Pi/3), matrices.TranslationMatrix(1, -2, 3))
+	m4, _ := matrices.Mat4FromMatrix(mt)
+	inverse, _ := m4.Inverse()
+
+	sph, cube, grp := NewCenteredSphere(), NewCube(), NewGroup()
+	tri := NewTriangle(coordinates.CreatePoint(0, 1, 0), coordinates.CreatePoint(-1, 0, 0), coordinates.CreatePoint(1, 0, 0))
+	csg := NewCSG(CSGUnion, &sph, &cube)
+
+	for _, shape := range []interface {
+		Shape
+		SetTransformation(matrices.Matrix)
+	}{&sph, &cube, &grp, &tri, &csg} {
+		assert.Equal(t, matrices.NewIdentityMat4(), shape.InverseTransformation())
+
+		shape.SetTransformation(mt)
+		assert.Equal(t, inverse, shape.InverseTransformation())
+		assert.Equal(t, inverse.T(), shape.InverseTransposeTransformation())
+	}
+}
+
 func TestNormalComputationOnSphere(t *testing.T) {
 	sph := NewCenteredSphere()
 	point := coordinates.CreatePoint(0, 0, 1)
@@ -91,6 +112,26 @@ func Test0IntersectionWithSphere(t *testing.T) {
 	assert.Equal(t, 0, len(xs))
 }
 
+func TestIntersectionsWithTinySphere(t *testing.T) {
+	sph := NewCenteredSphere()
+	sph.SetTransformation(matrices.ScalingMatrix(1e-4, 1e-4, 1e-4))
+
+	xs := Intersect(sph, NewRay(coordinates.CreatePoint(10, 10, -5), coordinates.CreateVector(0, 0, 1)))
+	assert.Equal(t, 0, len(xs))
+
+	xs = Intersect(sph, NewRay(coordinates.CreatePoint(0, 0, -5), coordinates.CreateVector(0, 0, 1)))
+	assert.Equal(t, 2, len(xs))
+	helpers.ApproxEqual(t, 5-1e-4, xs[0].Tvalue, 1e-9)
+	helpers.ApproxEqual(t, 5+1e-4, xs[1].Tvalue, 1e-9)
+}
+
+func TestSingularTransformationPanics(t *testing.T) {
+	sph := NewCenteredSphere()
+	assert.PanicsWithValue(t, "transformation [[1 0 0 0] [0 0 0 0] [0 0 1 0] [0 0 0 1]] cannot be inverted: matrix is singular", func() {
+		sph.SetTransformation(matrices.ScalingMatrix(1, 0, 1))
+	})
+}
+
 func Test0IntersectionWithXZPlane(t *testing.T) {
 
 	r := NewRay(coordinates.CreatePoint(0, 1, 0), coordinates.CreateVector(0, 0, 1))
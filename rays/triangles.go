@@ -0,0 +1,199 @@
+package rays
+
+import (
+	"math"
+	"rattata/coordinates"
+	"rattata/matrices"
+
+	"github.com/gofrs/uuid"
+)
+
+// ---------------------------------- Triangle ----------------------------------
+type Triangle struct {
+	P1                  coordinates.Coordinate
+	P2                  coordinates.Coordinate
+	P3                  coordinates.Coordinate
+	E1                  coordinates.Coordinate
+	E2                  coordinates.Coordinate
+	Normal              coordinates.Coordinate
+	transformationMat   matrices.Matrix
+	inverseMat          matrices.Mat4
+	inverseTransposeMat matrices.Mat4
+	Material            Material
+	id                  string
+	parent              *Group
+}
+
+func NewTriangle(p1, p2, p3 coordinates.Coordinate) Triangle {
+	if !p1.IsAPoint() || !p2.IsAPoint() || !p3.IsAPoint() {
+		panic("triangle vertices are not points")
+	}
+
+	e1 := *p2.Sub(&p1)
+	e2 := *p3.Sub(&p1)
+	normal := *e2.CrossP(&e1).Norm()
+
+	new_uuid, _ := uuid.NewV4()
+	return Triangle{P1: p1, P2: p2, P3: p3, E1: e1, E2: e2, Normal: normal,
+		transformationMat: matrices.NewIdentityMatrix(4), inverseMat: matrices.NewIdentityMat4(), inverseTransposeMat: matrices.NewIdentityMat4(), Material: CreateDefaultMaterial(), id: new_uuid.String()}
+}
+
+func (tr Triangle) Name() string {
+	return "Triangle"
+}
+
+func (tr Triangle) Id() string {
+	return tr.id
+}
+
+func (tr Triangle) Transformation() matrices.Matrix {
+	return tr.transformationMat
+}
+
+func (tr Triangle) InverseTransformation() matrices.Mat4 {
+	return tr.inverseMat
+}
+
+func (tr Triangle) InverseTransposeTransformation() matrices.Mat4 {
+	return tr.inverseTransposeMat
+}
+
+func (tr *Triangle) SetTransformation(mt matrices.Matrix) {
+	tr.transformationMat = mt
+	tr.inverseMat, tr.inverseTransposeMat = invertTransformation(mt)
+	invalidateBounds(tr.parent)
+}
+
+func (tr Triangle) Parent() *Group {
+	return tr.parent
+}
+
+func (tr Triangle) GetMaterial() Material {
+	return tr.Material
+}
+
+func (tr Triangle) IntersectWithRay(ray_wrt_obj Ray) []Intersection {
+	t, isHit := mollerTrumboreIntersection(tr.P1, tr.E1, tr.E2, ray_wrt_obj)
+	if !isHit {
+		return []Intersection{}
+	}
+
+	return Intersections(NewIntersection(t, tr))
+}
+
+func (tr Triangle) NormalAtPoint(world_point coordinates.Coordinate) coordinates.Coordinate {
+	return normal_to_world_orientation(tr, tr.Normal)
+}
+
+func (tr Triangle) Bounds() BoundingBox {
+	box := NewEmptyBoundingBox()
+	box.AddPoint(tr.P1)
+	box.AddPoint(tr.P2)
+	box.AddPoint(tr.P3)
+	return box
+}
+
+func (tr *Triangle) SetParent(parent *Group) {
+	tr.parent = parent
+}
+
+func (tr *Triangle) GetRefAddress() *Shape {
+	var _shape Shape = tr
+	return &_shape
+}
+
+// ---------------------------------- SmoothTriangle ----------------------------------
+type SmoothTriangle struct {
+	Triangle
+	N1 coordinates.Coordinate
+	N2 coordinates.Coordinate
+	N3 coordinates.Coordinate
+}
+
+func NewSmoothTriangle(p1, p2, p3, n1, n2, n3 coordinates.Coordinate) SmoothTriangle {
+	if !n1.IsAVector() || !n2.IsAVector() || !n3.IsAVector() {
+		panic("triangle vertex normals are not vectors")
+	}
+
+	return SmoothTriangle{Triangle: NewTriangle(p1, p2, p3), N1: n1, N2: n2, N3: n3}
+}
+
+func (st SmoothTriangle) Name() string {
+	return "SmoothTriangle"
+}
+
+func (st SmoothTriangle) IntersectWithRay(ray_wrt_obj Ray) []Intersection {
+	t, isHit := mollerTrumboreIntersection(st.P1, st.E1, st.E2, ray_wrt_obj)
+	if !isHit {
+		return []Intersection{}
+	}
+
+	return Intersections(NewIntersection(t, st))
+}
+
+/*
+The vertex normals are blended using the barycentric weights of the point on the triangle,
+so the shading varies smoothly across the face while the geometry stays flat.
+*/
+func (st SmoothTriangle) NormalAtPoint(world_point coordinates.Coordinate) coordinates.Coordinate {
+	obj_point := world_to_object_orientation(st, world_point)
+	u, v := st.barycentricWeights(obj_point)
+
+	obj_normal := st.N2.Mul(u).Add(st.N3.Mul(v)).Add(st.N1.Mul(1 - u - v))
+	return normal_to_world_orientation(st, *obj_normal)
+}
+
+// Returns the weights of P2 and P3 for a point lying on the plane of the triangle
+func (st SmoothTriangle) barycentricWeights(obj_point coordinates.Coordinate) (float64, float64) {
+	p1_to_point := *obj_point.Sub(&st.P1)
+
+	d11 := st.E1.DotP(&st.E1)
+	d12 := st.E1.DotP(&st.E2)
+	d22 := st.E2.DotP(&st.E2)
+	dp1 := p1_to_point.DotP(&st.E1)
+	dp2 := p1_to_point.DotP(&st.E2)
+
+	denominator := d11*d22 - d12*d12
+	if math.Abs(denominator) < EPSILON*EPSILON {
+		return 0, 0
+	}
+
+	u := (d22*dp1 - d12*dp2) / denominator
+	v := (d11*dp2 - d12*dp1) / denominator
+	return u, v
+}
+
+func (st *SmoothTriangle) SetParent(parent *Group) {
+	st.parent = parent
+}
+
+func (st *SmoothTriangle) GetRefAddress() *Shape {
+	var _shape Shape = st
+	return &_shape
+}
+
+// https://en.wikipedia.org/wiki/M%C3%B6ller%E2%80%93Trumbore_intersection_algorithm
+func mollerTrumboreIntersection(p1, e1, e2 coordinates.Coordinate, ray_wrt_obj Ray) (float64, bool) {
+	dir_cross_e2 := ray_wrt_obj.Direction.CrossP(&e2)
+	determinant := e1.DotP(dir_cross_e2)
+
+	if math.Abs(determinant) < EPSILON {
+		return 0, false // Ray is parallel to the triangle
+	}
+
+	f := 1.0 / determinant
+	p1_to_origin := ray_wrt_obj.Origin.Sub(&p1)
+	u := f * p1_to_origin.DotP(dir_cross_e2)
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+
+	origin_cross_e1 := p1_to_origin.CrossP(&e1)
+	v := f * ray_wrt_obj.Direction.DotP(origin_cross_e1)
+	if v < 0 || (u+v) > 1 {
+		return 0, false
+	}
+
+	t := f * e2.DotP(origin_cross_e1)
+	return t, true
+}
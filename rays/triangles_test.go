@@ -0,0 +1,108 @@
+package rays
+
+import (
+	"rattata/coordinates"
+	"rattata/helpers"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func defaultTriangle() Triangle {
+	return NewTriangle(coordinates.CreatePoint(0, 1, 0), coordinates.CreatePoint(-1, 0, 0), coordinates.CreatePoint(1, 0, 0))
+}
+
+func defaultSmoothTriangle() SmoothTriangle {
+	return NewSmoothTriangle(coordinates.CreatePoint(0, 1, 0), coordinates.CreatePoint(-1, 0, 0), coordinates.CreatePoint(1, 0, 0),
+		coordinates.CreateVector(0, 1, 0), coordinates.CreateVector(-1, 0, 0), coordinates.CreateVector(1, 0, 0))
+}
+
+func TestTriangleConstruction(t *testing.T) {
+	tr := defaultTriangle()
+
+	assert.Equal(t, coordinates.CreateVector(-1, -1, 0), tr.E1)
+	assert.Equal(t, coordinates.CreateVector(1, -1, 0), tr.E2)
+	assert.Equal(t, coordinates.CreateVector(0, 0, -1), tr.Normal)
+}
+
+func TestTriangleNormal(t *testing.T) {
+	tr := defaultTriangle()
+
+	for _, point := range []coordinates.Coordinate{
+		coordinates.CreatePoint(0, 0.5, 0),
+		coordinates.CreatePoint(-0.5, 0.75, 0),
+		coordinates.CreatePoint(0.5, 0.25, 0),
+	} {
+		assert.Equal(t, tr.Normal, tr.NormalAtPoint(point))
+	}
+}
+
+func TestTriangleRayMiss(t *testing.T) {
+	tr := defaultTriangle()
+
+	for _, data := range []struct {
+		origin, direction coordinates.Coordinate
+	}{
+		{coordinates.CreatePoint(0, -1, -2), coordinates.CreateVector(0, 1, 0)},
+		{coordinates.CreatePoint(1, 1, -2), coordinates.CreateVector(0, 0, 1)},
+		{coordinates.CreatePoint(-1, 1, -2), coordinates.CreateVector(0, 0, 1)},
+		{coordinates.CreatePoint(0, -1, -2), coordinates.CreateVector(0, 0, 1)},
+	} {
+		r := NewRay(data.origin, data.direction)
+		xs := tr.IntersectWithRay(r)
+
+		assert.Equal(t, 0, len(xs))
+	}
+}
+
+func TestTriangleRayHit(t *testing.T) {
+	tr := defaultTriangle()
+	r := NewRay(coordinates.CreatePoint(0, 0.5, -2), coordinates.CreateVector(0, 0, 1))
+	xs := tr.IntersectWithRay(r)
+
+	assert.Equal(t, 1, len(xs))
+	helpers.ApproxEqual(t, 2, xs[0].Tvalue, 0.00001)
+	assert.Equal(t, tr.Id(), xs[0].Obj.Id())
+}
+
+func TestSmoothTriangleNormalInterpolation(t *testing.T) {
+	tr := defaultSmoothTriangle()
+
+	for _, data := range []struct {
+		point, normal coordinates.Coordinate
+	}{
+		{coordinates.CreatePoint(0, 1, 0), coordinates.CreateVector(0, 1, 0)},
+		{coordinates.CreatePoint(-1, 0, 0), coordinates.CreateVector(-1, 0, 0)},
+		{coordinates.CreatePoint(-0.2, 0.3, 0), coordinates.CreateVector(-0.5547, 0.83205, 0)},
+	} {
+		n := tr.NormalAtPoint(data.point)
+		helpers.TestApproxEqualCoordinate(t, data.normal, n, 0.0001)
+	}
+}
+
+func TestSmoothTriangleRayHit(t *testing.T) {
+	tr := defaultSmoothTriangle()
+	r := NewRay(coordinates.CreatePoint(-0.2, 0.3, -5), coordinates.CreateVector(0, 0, 1))
+	xs := Intersect(tr, r)
+
+	assert.Equal(t, 1, len(xs))
+	helpers.ApproxEqual(t, 5, xs[0].Tvalue, 0.00001)
+	assert.Equal(t, "SmoothTriangle", xs[0].Obj.Name())
+}
+
+func TestTrianglesInGroup(t *testing.T) {
+	grp := NewGroup()
+	tr := defaultTriangle()
+	st := defaultSmoothTriangle()
+
+	grp.IndoctrinateShapeToGroup(&tr)
+	grp.IndoctrinateShapeToGroup(&st)
+
+	assert.Equal(t, &grp, tr.Parent())
+	assert.Equal(t, &grp, st.Parent())
+	assert.Equal(t, 2, len(grp.containedShapes))
+
+	r := NewRay(coordinates.CreatePoint(0, 0.5, -2), coordinates.CreateVector(0, 0, 1))
+	xs := grp.IntersectWithRay(r)
+	assert.Equal(t, 2, len(xs))
+}
@@ -0,0 +1,703 @@
+package scene
+
+import (
+	"math"
+	"rattata/colour"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/observe"
+	"rattata/rays"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------- Values ----------------------------------
+
+// [r, g, b] with channels from 0 to 1, or a "#rrggbb" string
+func (l *loader) colour(n *yaml.Node, what string) (rays.Colour, bool) {
+	if resolve(n).Kind == yaml.ScalarNode {
+		c, err := colour.ParseHex(resolve(n).Value)
+		if err != nil {
+			l.fail(n, "%s must be [r, g, b] or a hex colour: %v", what, err)
+			return rays.Colour{}, false
+		}
+		return c, true
+	}
+
+	v, isOk := l.floats(n, what, 3)
+	if !isOk {
+		return rays.Colour{}, false
+	}
+	return colour.New(v[0], v[1], v[2]), true
+}
+
+func (l *loader) point(n *yaml.Node, what string) (coordinates.Coordinate, bool) {
+	v, isOk := l.floats(n, what, 3)
+	if !isOk {
+		return coordinates.Coordinate{}, false
+	}
+	return coordinates.CreatePoint(v[0], v[1], v[2]), true
+}
+
+func (l *loader) vector(n *yaml.Node, what string) (coordinates.Coordinate, bool) {
+	v, isOk := l.floats(n, what, 3)
+	if !isOk {
+		return coordinates.Coordinate{}, false
+	}
+	return coordinates.CreateVector(v[0], v[1], v[2]), true
+}
+
+/*
+Angles are radians, unless written in degrees ("90deg") or as a multiple of pi ("pi/2", "-3pi/4", "2*pi")
+*/
+func (l *loader) angle(n *yaml.Node, what string) (float64, bool) {
+	s, isOk := l.scalar(n, what)
+	if !isOk {
+		return 0, false
+	}
+
+	v, isValid := parseAngle(s)
+	if !isValid {
+		l.fail(n, "%s must be an angle in radians, degrees (90deg) or a multiple of pi (pi/2), got %q", what, s)
+		return 0, false
+	}
+	return v, true
+}
+
+func parseAngle(s string) (float64, bool) {
+	s = strings.ReplaceAll(s, " ", "")
+
+	if degrees, isDegrees := strings.CutSuffix(s, "deg"); isDegrees {
+		v, err := strconv.ParseFloat(degrees, 64)
+		return v * math.Pi / 180, err == nil
+	}
+
+	factor, divisor, hasPi := strings.Cut(s, "pi")
+	if !hasPi {
+		v, err := strconv.ParseFloat(s, 64)
+		return v, err == nil
+	}
+
+	k := 1.0
+	switch factor = strings.TrimSuffix(factor, "*"); factor {
+	case "":
+	case "-":
+		k = -1
+	default:
+		v, err := strconv.ParseFloat(factor, 64)
+		if err != nil {
+			return 0, false
+		}
+		k = v
+	}
+
+	if divisor != "" {
+		d, hasSlash := strings.CutPrefix(divisor, "/")
+		v, err := strconv.ParseFloat(d, 64)
+		if !hasSlash || err != nil || v == 0 {
+			return 0, false
+		}
+		k /= v
+	}
+
+	return k * math.Pi, true
+}
+
+// ---------------------------------- Transforms ----------------------------------
+
+/*
+A list of operations applied in order, each either [op, args...] or the name of a transform definition:
+
+	[translate, x, y, z]  [scale, x, y, z]  [scale, s]  [rotate-x, angle]  [rotate-y, angle]  [rotate-z, angle]
+	[shear, xy, xz, yx, yz, zx, zy]  [matrix, 16 values, row by row]
+
+Rotations are the ones of the book: looking from the positive end of the axis towards the origin, they turn clockwise.
+*/
+func (l *loader) transform(n *yaml.Node) (matrices.Matrix, bool) {
+	res := matrices.NewIdentityMatrix(4)
+
+	ops, isOk := l.sequence(n, "transform")
+	for _, op := range ops {
+		mt, isValid := l.transformOp(op)
+		if !isValid {
+			isOk = false
+			continue
+		}
+		res = matrices.PerformOrderedChainingOps(res, mt)
+	}
+
+	if isOk && !isInvertible(res) {
+		l.fail(n, "transform cannot be inverted, does it scale something to zero?")
+		return nil, false
+	}
+	return res, isOk
+}
+
+// Shapes, patterns and cameras need the inverse of their transformation, so ones that squash space flat are rejected
+func isInvertible(mt matrices.Matrix) bool {
+	m4, _ := matrices.Mat4FromMatrix(mt)
+	_, err := m4.Inverse()
+	return err == nil
+}
+
+func (l *loader) buildTransform(n *yaml.Node) (matrices.Matrix, bool) {
+	return l.transform(n)
+}
+
+func (l *loader) transformOp(n *yaml.Node) (matrices.Matrix, bool) {
+	if resolve(n).Kind == yaml.ScalarNode {
+		return l.transforms.get(l, n, resolve(n).Value)
+	}
+
+	items, isOk := l.sequence(n, "transform operation")
+	if !isOk {
+		return nil, false
+	}
+	if len(items) == 0 {
+		l.fail(n, "transform operation is empty")
+		return nil, false
+	}
+
+	op, _ := l.scalar(items[0], "transform operation")
+	args := items[1:]
+
+	numbers := func(counts ...int) ([]float64, bool) {
+		for _, count := range counts {
+			if len(args) == count {
+				res := make([]float64, count)
+				isValid := true
+				for i, arg := range args {
+					v, isNumber := l.float(arg, op)
+					res[i], isValid = v, isValid && isNumber
+				}
+				return res, isValid
+			}
+		}
+		l.fail(n, "%s takes %v values, got %d", op, counts, len(args))
+		return nil, false
+	}
+
+	rotation := func(rotate func(float64) matrices.Matrix) (matrices.Matrix, bool) {
+		if len(args) != 1 {
+			l.fail(n, "%s takes 1 angle, got %d values", op, len(args))
+			return nil, false
+		}
+		a, isAngle := l.angle(args[0], op)
+		return rotate(a), isAngle
+	}
+
+	switch op {
+	case "translate":
+		v, isValid := numbers(3)
+		if !isValid {
+			return nil, false
+		}
+		return matrices.TranslationMatrix(v[0], v[1], v[2]), true
+	case "scale":
+		v, isValid := numbers(3, 1)
+		if !isValid {
+			return nil, false
+		}
+		if len(v) == 1 {
+			return matrices.ScalingMatrix(v[0], v[0], v[0]), true
+		}
+		return matrices.ScalingMatrix(v[0], v[1], v[2]), true
+	case "rotate-x":
+		return rotation(func(a float64) matrices.Matrix { return matrices.GivensRotationMatrix3D(coordinates.X, a) })
+	case "rotate-y":
+		return rotation(func(a float64) matrices.Matrix { return matrices.GivensRotationMatrix3DLeftHanded(coordinates.Y, a) })
+	case "rotate-z":
+		return rotation(func(a float64) matrices.Matrix { return matrices.GivensRotationMatrix3D(coordinates.Z, a) })
+	case "shear":
+		v, isValid := numbers(6)
+		if !isValid {
+			return nil, false
+		}
+		return matrices.ShearMatrix(v[0], v[1], v[2], v[3], v[4], v[5]), true
+	case "matrix":
+		v, isValid := numbers(16)
+		if !isValid {
+			return nil, false
+		}
+		res := matrices.NewMatrix(4, 4)
+		for i := range v {
+			res[i/4][i%4] = v[i]
+		}
+		return res, true
+	}
+
+	l.fail(items[0], "unknown transform operation %q", op)
+	return nil, false
+}
+
+// ---------------------------------- Patterns ----------------------------------
+var patternFields = []string{"type", "color", "colors", "transform", "width", "height", "pattern", "amount"}
+
+// Either the name of a pattern definition or an inline pattern
+func (l *loader) pattern(n *yaml.Node) (rays.Pattern, bool) {
+	if resolve(n).Kind == yaml.ScalarNode {
+		return l.patterns.get(l, n, resolve(n).Value)
+	}
+	return l.buildPattern(n)
+}
+
+/*
+	type: plain | stripe | gradient | ring | checker | radial-gradient | uv-checker | perturbed
+
+plain takes a single color, uv-checker a width and height on top of its two colors,
+perturbed jitters another pattern by amount.
+*/
+func (l *loader) buildPattern(n *yaml.Node) (rays.Pattern, bool) {
+	fields, isOk := l.mapping(n, "pattern", patternFields...)
+	if !isOk {
+		return nil, false
+	}
+
+	type_node, isPresent := l.required(fields, n, "pattern", "type")
+	if !isPresent {
+		return nil, false
+	}
+	pattern_type, _ := l.scalar(type_node, "pattern type")
+
+	mt := matrices.NewIdentityMatrix(4)
+	if transform_node, isPresent := fields["transform"]; isPresent {
+		if mt, isOk = l.transform(transform_node); !isOk {
+			// the pattern is thrown away anyway, it only has to survive being built
+			mt = matrices.NewIdentityMatrix(4)
+		}
+	}
+
+	two_colours := func() (rays.Colour, rays.Colour, bool) {
+		colours_node, isPresent := l.required(fields, n, pattern_type+" pattern", "colors")
+		if !isPresent {
+			return rays.Colour{}, rays.Colour{}, false
+		}
+
+		items, isList := l.sequence(colours_node, "colors")
+		if !isList {
+			return rays.Colour{}, rays.Colour{}, false
+		}
+		if len(items) != 2 {
+			l.fail(colours_node, "%s pattern takes 2 colors, got %d", pattern_type, len(items))
+			return rays.Colour{}, rays.Colour{}, false
+		}
+
+		a, isValidA := l.colour(items[0], "color")
+		b, isValidB := l.colour(items[1], "color")
+		return a, b, isValidA && isValidB
+	}
+
+	switch pattern_type {
+	case "plain":
+		colour_node, isPresent := l.required(fields, n, "plain pattern", "color")
+		if !isPresent {
+			return nil, false
+		}
+		c, isValid := l.colour(colour_node, "color")
+		return rays.NewPlainPattern(c), isValid && isOk
+	case "stripe":
+		a, b, isValid := two_colours()
+		p := rays.NewXStripe(a, b)
+		p.SetPatternTransformation(mt)
+		return p, isValid && isOk
+	case "gradient":
+		a, b, isValid := two_colours()
+		p := rays.NewXGradient(a, b)
+		p.SetPatternTransformation(mt)
+		return p, isValid && isOk
+	case "ring":
+		a, b, isValid := two_colours()
+		p := rays.NewXZRing(a, b)
+		p.SetPatternTransformation(mt)
+		return p, isValid && isOk
+	case "checker":
+		a, b, isValid := two_colours()
+		p := rays.NewChecker3D(a, b)
+		p.SetPatternTransformation(mt)
+		return p, isValid && isOk
+	case "radial-gradient":
+		a, b, isValid := two_colours()
+		p := rays.NewXZRadialGradient(a, b)
+		p.SetPatternTransformation(mt)
+		return p, isValid && isOk
+	case "uv-checker":
+		a, b, isValid := two_colours()
+		width, height := 2.0, 2.0
+		l.optionalFloat(fields, "width", &width)
+		l.optionalFloat(fields, "height", &height)
+		p := rays.NewUnitSphereUVChecker(a, b, width, height)
+		p.SetPatternTransformation(mt)
+		return p, isValid && isOk
+	case "perturbed":
+		base_node, isPresent := l.required(fields, n, "perturbed pattern", "pattern")
+		if !isPresent {
+			return nil, false
+		}
+		base, isValid := l.pattern(base_node)
+		amount := 0.1
+		l.optionalFloat(fields, "amount", &amount)
+		p := rays.NewPerturbedPattern(base, amount)
+		p.SetPatternTransformation(mt)
+		return p, isValid && isOk
+	}
+
+	l.fail(type_node, "unknown pattern type %q", pattern_type)
+	return nil, false
+}
+
+// ---------------------------------- Materials ----------------------------------
+var materialFields = []string{"extends", "color", "pattern", "ambient", "diffuse", "specular", "shininess", "reflective", "transparency", "refractive-index"}
+
+// Either the name of a material definition or an inline material
+func (l *loader) material(n *yaml.Node) (rays.Material, bool) {
+	if resolve(n).Kind == yaml.ScalarNode {
+		return l.materials.get(l, n, resolve(n).Value)
+	}
+	return l.buildMaterial(n)
+}
+
+/*
+Starts from the default material, or the one named by extends, and overrides whatever is given.
+color is a shorthand for a plain pattern.
+*/
+func (l *loader) buildMaterial(n *yaml.Node) (rays.Material, bool) {
+	fields, isOk := l.mapping(n, "material", materialFields...)
+	if !isOk {
+		return rays.Material{}, false
+	}
+
+	m := rays.CreateDefaultMaterial()
+	if base_node, isPresent := fields["extends"]; isPresent {
+		if name, isName := l.scalar(base_node, "extends"); isName {
+			m, isOk = l.materials.get(l, base_node, name)
+		}
+	}
+
+	colour_node, hasColour := fields["color"]
+	pattern_node, hasPattern := fields["pattern"]
+	switch {
+	case hasColour && hasPattern:
+		l.fail(pattern_node, "a material takes either a color or a pattern, not both")
+		isOk = false
+	case hasColour:
+		c, isValid := l.colour(colour_node, "color")
+		m.Pattern, isOk = rays.NewPlainPattern(c), isOk && isValid
+	case hasPattern:
+		p, isValid := l.pattern(pattern_node)
+		m.Pattern, isOk = p, isOk && isValid
+	}
+
+	errs := len(l.errs)
+	l.optionalFloat(fields, "ambient", &m.Ambient)
+	l.optionalFloat(fields, "diffuse", &m.Diffuse)
+	l.optionalFloat(fields, "specular", &m.Specular)
+	l.optionalFloat(fields, "shininess", &m.Shininess)
+	l.optionalFloat(fields, "reflective", &m.Reflective)
+	l.optionalFloat(fields, "transparency", &m.Transparency)
+	l.optionalFloat(fields, "refractive-index", &m.RefractiveIndex)
+
+	return m, isOk && len(l.errs) == errs
+}
+
+// ---------------------------------- Camera ----------------------------------
+var cameraFields = []string{"width", "height", "field-of-view", "from", "to", "up", "transform", "aperture", "focal-distance", "sampling", "projection"}
+
+/*
+The camera is placed either with from / to / up or with a transform, which is its view transform as is
+*/
+func (l *loader) camera(n *yaml.Node) observe.Camera {
+	fields, isOk := l.mapping(n, "camera", cameraFields...)
+	if !isOk {
+		return observe.Camera{}
+	}
+
+	width, height, fov := 0, 0, 0.0
+	if width_node, isPresent := l.required(fields, n, "camera", "width"); isPresent {
+		width, _ = l.int(width_node, "width", 1)
+	}
+	if height_node, isPresent := l.required(fields, n, "camera", "height"); isPresent {
+		height, _ = l.int(height_node, "height", 1)
+	}
+	if fov_node, isPresent := l.required(fields, n, "camera", "field-of-view"); isPresent {
+		var isValid bool
+		if fov, isValid = l.angle(fov_node, "field-of-view"); isValid && !(fov > 0 && fov < math.Pi) {
+			l.fail(fov_node, "field-of-view must lie between 0 and pi, got %v", fov)
+		}
+	}
+
+	cam := observe.CreateNewCamera(uint32(width), uint32(height), fov)
+
+	if transform_node, isPresent := fields["transform"]; isPresent {
+		for _, key := range []string{"from", "to", "up"} {
+			if field_node, isPresent := fields[key]; isPresent {
+				l.fail(field_node, "a camera takes either a transform or from / to / up, not both")
+			}
+		}
+		if mt, isValid := l.transform(transform_node); isValid {
+			cam.SetTransformationMatrix(mt)
+		}
+		return l.cameraOptions(fields, cam)
+	}
+
+	from, to, up := coordinates.CreatePoint(0, 0, 0), coordinates.CreatePoint(0, 0, -1), coordinates.CreateVector(0, 1, 0)
+	if from_node, isPresent := fields["from"]; isPresent {
+		from, _ = l.point(from_node, "from")
+	}
+	if to_node, isPresent := fields["to"]; isPresent {
+		to, _ = l.point(to_node, "to")
+	}
+	if up_node, isPresent := fields["up"]; isPresent {
+		up, _ = l.vector(up_node, "up")
+	}
+	if from == to {
+		l.fail(n, "camera cannot look from and to the same point")
+	} else if view := matrices.View_Transform(from, to, up); !isInvertible(view) {
+		l.fail(n, "camera up must not point along the view direction")
+	} else {
+		cam.SetTransformationMatrix(view)
+	}
+
+	return l.cameraOptions(fields, cam)
+}
+
+func (l *loader) cameraOptions(fields map[string]*yaml.Node, cam observe.Camera) observe.Camera {
+	l.optionalFloat(fields, "aperture", &cam.Aperture)
+	l.optionalFloat(fields, "focal-distance", &cam.FocalDistance)
+
+	if sampling_node, isPresent := fields["sampling"]; isPresent {
+		cam.SetSampling(l.sampling(sampling_node))
+	}
+
+	if projection_node, isPresent := fields["projection"]; isPresent {
+		cam.SetProjection(l.projection(projection_node))
+	}
+
+	return cam
+}
+
+/*
+	mode: single | grid | jittered | adaptive
+*/
+func (l *loader) sampling(n *yaml.Node) observe.Sampling {
+	fields, isOk := l.mapping(n, "sampling", "mode", "samples", "threshold", "seed")
+	if !isOk {
+		return observe.Sampling{}
+	}
+
+	res := observe.Sampling{}
+	if mode_node, isPresent := l.required(fields, n, "sampling", "mode"); isPresent {
+		mode, _ := l.scalar(mode_node, "mode")
+		switch mode {
+		case "single":
+			res.Mode = observe.SingleSample
+		case "grid":
+			res.Mode = observe.GridSampling
+		case "jittered":
+			res.Mode = observe.JitteredSampling
+		case "adaptive":
+			res.Mode = observe.AdaptiveSampling
+		default:
+			l.fail(mode_node, "unknown sampling mode %q", mode)
+		}
+	}
+
+	if samples_node, isPresent := fields["samples"]; isPresent {
+		res.Samples, _ = l.int(samples_node, "samples", 1)
+	}
+	l.optionalFloat(fields, "threshold", &res.Threshold)
+	if seed_node, isPresent := fields["seed"]; isPresent {
+		if s, isOk := l.scalar(seed_node, "seed"); isOk {
+			seed, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				l.fail(seed_node, "seed must be a whole number of at least 0, got %q", s)
+			}
+			res.Seed = seed
+		}
+	}
+
+	return res
+}
+
+/*
+	type: perspective (default) | orthographic | fisheye | equirectangular
+
+orthographic takes the view-width it covers, fisheye its field-of-view.
+*/
+func (l *loader) projection(n *yaml.Node) observe.Projection {
+	fields, isOk := l.mapping(n, "projection", "type", "view-width", "field-of-view")
+	if !isOk {
+		return nil
+	}
+
+	projection_type := "perspective"
+	if type_node, isPresent := fields["type"]; isPresent {
+		projection_type, _ = l.scalar(type_node, "projection type")
+	}
+
+	check_fields := func(keys ...string) {
+		for _, key := range []string{"view-width", "field-of-view"} {
+			if field_node, isPresent := fields[key]; isPresent && !slices.Contains(keys, key) {
+				l.fail(field_node, "%q does not apply to %s projections", key, projection_type)
+			}
+		}
+	}
+
+	switch projection_type {
+	case "perspective":
+		check_fields()
+		return nil
+	case "orthographic":
+		check_fields("view-width")
+		width := 0.0
+		if width_node, isPresent := l.required(fields, n, "orthographic projection", "view-width"); isPresent {
+			width, _ = l.float(width_node, "view-width")
+		}
+		return observe.NewOrthographicProjection(width)
+	case "fisheye":
+		check_fields("field-of-view")
+		fov := 0.0
+		if fov_node, isPresent := l.required(fields, n, "fisheye projection", "field-of-view"); isPresent {
+			fov, _ = l.angle(fov_node, "field-of-view")
+		}
+		return observe.NewFisheyeProjection(fov)
+	case "equirectangular":
+		check_fields()
+		return observe.EquirectangularProjection{}
+	}
+
+	l.fail(fields["type"], "unknown projection type %q", projection_type)
+	return nil
+}
+
+// ---------------------------------- Lights ----------------------------------
+var lightFields = []string{"type", "at", "intensity", "corner", "uvec", "usteps", "vvec", "vsteps", "jitter", "direction", "inner-angle", "outer-angle", "attenuation"}
+
+/*
+	type: point (default) | area | spot | directional
+
+Every light takes an intensity, white when left out; all but directional lights can be attenuated.
+*/
+func (l *loader) light(n *yaml.Node) (rays.LightSource, bool) {
+	fields, isOk := l.mapping(n, "light", lightFields...)
+	if !isOk {
+		return nil, false
+	}
+	errs := len(l.errs)
+
+	light_type := "point"
+	if type_node, isPresent := fields["type"]; isPresent {
+		light_type, _ = l.scalar(type_node, "light type")
+	}
+
+	intensity := colour.White()
+	if intensity_node, isPresent := fields["intensity"]; isPresent {
+		intensity, _ = l.colour(intensity_node, "intensity")
+	}
+
+	attenuation := rays.Attenuation{}
+	if attenuation_node, isPresent := fields["attenuation"]; isPresent {
+		attenuation = l.attenuation(attenuation_node)
+	}
+
+	point_field := func(key, what string) coordinates.Coordinate {
+		if field_node, isPresent := l.required(fields, n, what, key); isPresent {
+			p, _ := l.point(field_node, key)
+			return p
+		}
+		return coordinates.Coordinate{}
+	}
+	vector_field := func(key, what string) coordinates.Coordinate {
+		if field_node, isPresent := l.required(fields, n, what, key); isPresent {
+			v, _ := l.vector(field_node, key)
+			return v
+		}
+		return coordinates.Coordinate{}
+	}
+	steps_field := func(key string) int {
+		if field_node, isPresent := l.required(fields, n, "area light", key); isPresent {
+			steps, _ := l.int(field_node, key, 1)
+			return steps
+		}
+		return 1
+	}
+	// turned into unit length here, a zero length direction has none
+	direction_field := func(what string) coordinates.Coordinate {
+		if field_node, isPresent := l.required(fields, n, what, "direction"); isPresent {
+			if v, isValid := l.vector(field_node, "direction"); isValid {
+				if v.Magnitude() == 0 {
+					l.fail(field_node, "%s direction must not be zero", what)
+					return v
+				}
+				return unitVector(v)
+			}
+		}
+		return coordinates.Coordinate{}
+	}
+	angle_field := func(key string) float64 {
+		if field_node, isPresent := l.required(fields, n, "spot light", key); isPresent {
+			a, _ := l.angle(field_node, key)
+			return a
+		}
+		return 0
+	}
+
+	var res rays.LightSource
+	switch light_type {
+	case "point":
+		at := point_field("at", "point light")
+		light := rays.NewLightSource(at[coordinates.X], at[coordinates.Y], at[coordinates.Z], intensity)
+		light.Attenuation = attenuation
+		res = light
+	case "area":
+		corner := point_field("corner", "area light")
+		uvec, usteps := vector_field("uvec", "area light"), steps_field("usteps")
+		vvec, vsteps := vector_field("vvec", "area light"), steps_field("vsteps")
+
+		light := rays.NewAreaLight(corner, uvec, usteps, vvec, vsteps, intensity)
+		light.Attenuation = attenuation
+		if jitter_node, isPresent := fields["jitter"]; isPresent {
+			light.Jitter, _ = l.bool(jitter_node, "jitter")
+		}
+		res = light
+	case "spot":
+		direction := direction_field("spot light")
+		light := rays.NewSpotLight(point_field("at", "spot light"), direction,
+			angle_field("inner-angle"), angle_field("outer-angle"), intensity)
+		light.Direction = direction
+		light.Attenuation = attenuation
+		res = light
+	case "directional":
+		direction := direction_field("directional light")
+		light := rays.NewDirectionalLight(direction, intensity)
+		light.Direction = direction
+		res = light
+	default:
+		l.fail(fields["type"], "unknown light type %q", light_type)
+	}
+
+	return res, len(l.errs) == errs
+}
+
+// Directions that already are unit length are taken as written, normalising them again could change the last digit
+func unitVector(v coordinates.Coordinate) coordinates.Coordinate {
+	if math.Abs(v.Magnitude()-1) < 1e-12 {
+		return v
+	}
+	return *v.Norm()
+}
+
+func (l *loader) attenuation(n *yaml.Node) rays.Attenuation {
+	fields, isOk := l.mapping(n, "attenuation", "constant", "linear", "quadratic")
+	if !isOk {
+		return rays.Attenuation{}
+	}
+
+	res := rays.NewAttenuation(1, 0, 0)
+	l.optionalFloat(fields, "constant", &res.Constant)
+	l.optionalFloat(fields, "linear", &res.Linear)
+	l.optionalFloat(fields, "quadratic", &res.Quadratic)
+	return res
+}
@@ -0,0 +1,591 @@
+package scene
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"rattata/colour"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/observe"
+	"rattata/rays"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------- Export ----------------------------------
+
+/*
+Writes s as a scene document that Parse reads back into the same scene.
+Numbers are written with as many digits as it takes to read back the exact same float64.
+
+The output is meant to be diffed: fields are always written in the same order, and whatever equals
+the default is left out. Materials are written inline with each object. Transforms are written as
+scale / translate operations when those rebuild the matrix exactly, as a raw matrix otherwise.
+Groups of OBJ models are written as groups of triangles.
+*/
+func Marshal(s Scene) ([]byte, error) {
+	doc, err := sceneNode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+/*
+Writes s to path, see Marshal
+*/
+func Save(path string, s Scene) error {
+	data, err := Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func sceneNode(s Scene) (*yaml.Node, error) {
+	res := newMapping()
+
+	cam, err := cameraNode(s.Camera)
+	if err != nil {
+		return nil, err
+	}
+	setField(res, "camera", cam)
+
+	if lights := s.World.Lights(); len(lights) > 0 {
+		lights_node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, light := range lights {
+			n, err := lightNode(light)
+			if err != nil {
+				return nil, err
+			}
+			lights_node.Content = append(lights_node.Content, n)
+		}
+		setField(res, "lights", lights_node)
+	}
+
+	if objects := s.World.ListObjects(); len(objects) > 0 {
+		objects_node, err := objectsNode(objects)
+		if err != nil {
+			return nil, err
+		}
+		setField(res, "objects", objects_node)
+	}
+
+	return res, nil
+}
+
+// ---------------------------------- Camera ----------------------------------
+var samplingModes = map[observe.SamplingMode]string{
+	observe.SingleSample:     "single",
+	observe.GridSampling:     "grid",
+	observe.JitteredSampling: "jittered",
+	observe.AdaptiveSampling: "adaptive",
+}
+
+func cameraNode(cam observe.Camera) (*yaml.Node, error) {
+	res := newMapping()
+	setField(res, "width", uintNode(uint64(cam.Hsize)))
+	setField(res, "height", uintNode(uint64(cam.Vsize)))
+	setField(res, "field-of-view", numberNode(cam.FOV))
+
+	if mt := transformNode(cam.Transform_Matrix); mt != nil {
+		setField(res, "transform", mt)
+	}
+	if cam.Aperture != 0 {
+		setField(res, "aperture", numberNode(cam.Aperture))
+	}
+	if cam.FocalDistance != 1 {
+		setField(res, "focal-distance", numberNode(cam.FocalDistance))
+	}
+
+	if cam.Sampling != (observe.Sampling{}) {
+		mode, isKnown := samplingModes[cam.Sampling.Mode]
+		if !isKnown {
+			return nil, fmt.Errorf("cannot export sampling mode %d", cam.Sampling.Mode)
+		}
+
+		sampling := newMapping()
+		setField(sampling, "mode", stringNode(mode))
+		if cam.Sampling.Samples > 0 {
+			setField(sampling, "samples", uintNode(uint64(cam.Sampling.Samples)))
+		}
+		if cam.Sampling.Threshold != 0 {
+			setField(sampling, "threshold", numberNode(cam.Sampling.Threshold))
+		}
+		if cam.Sampling.Seed != 0 {
+			setField(sampling, "seed", uintNode(cam.Sampling.Seed))
+		}
+		setField(res, "sampling", sampling)
+	}
+
+	projection, err := projectionNode(cam.Projection)
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		setField(res, "projection", projection)
+	}
+
+	return res, nil
+}
+
+// nil for the default perspective projection
+func projectionNode(p observe.Projection) (*yaml.Node, error) {
+	res := newMapping()
+
+	switch pt := p.(type) {
+	case nil, observe.PerspectiveProjection, *observe.PerspectiveProjection:
+		return nil, nil
+	case *observe.OrthographicProjection:
+		return projectionNode(*pt)
+	case *observe.FisheyeProjection:
+		return projectionNode(*pt)
+	case observe.OrthographicProjection:
+		setField(res, "type", stringNode("orthographic"))
+		setField(res, "view-width", numberNode(pt.ViewWidth))
+	case observe.FisheyeProjection:
+		setField(res, "type", stringNode("fisheye"))
+		setField(res, "field-of-view", numberNode(pt.FOV))
+	case observe.EquirectangularProjection, *observe.EquirectangularProjection:
+		setField(res, "type", stringNode("equirectangular"))
+	default:
+		return nil, fmt.Errorf("cannot export projections of type %T", p)
+	}
+
+	return res, nil
+}
+
+// ---------------------------------- Lights ----------------------------------
+func lightNode(light rays.LightSource) (*yaml.Node, error) {
+	res := newMapping()
+
+	switch lt := light.(type) {
+	case *rays.AreaLight:
+		return lightNode(*lt)
+	case *rays.SpotLight:
+		return lightNode(*lt)
+	case *rays.DirectionalLight:
+		return lightNode(*lt)
+	case rays.AreaLight:
+		zero := coordinates.Coordinate{}
+		// lights without cells, or with a single cell of no extent at Origin, are point lights
+		isPoint := lt.USteps <= 0 || lt.VSteps <= 0 ||
+			(lt.USteps == 1 && lt.VSteps == 1 && lt.UVec == zero && lt.VVec == zero && lt.Corner == lt.Origin)
+
+		if isPoint {
+			setField(res, "at", tupleNode(lt.Origin))
+		} else {
+			setField(res, "type", stringNode("area"))
+			uvec, vvec := areaLightEdges(lt)
+			setField(res, "corner", tupleNode(lt.Corner))
+			setField(res, "uvec", tupleNode(uvec))
+			setField(res, "usteps", uintNode(uint64(lt.USteps)))
+			setField(res, "vvec", tupleNode(vvec))
+			setField(res, "vsteps", uintNode(uint64(lt.VSteps)))
+			if lt.Jitter {
+				setField(res, "jitter", boolNode(true))
+			}
+		}
+		setLightFields(res, lt.Colour, lt.Attenuation)
+	case rays.SpotLight:
+		setField(res, "type", stringNode("spot"))
+		setField(res, "at", tupleNode(lt.Position))
+		setField(res, "direction", tupleNode(lt.Direction))
+		setField(res, "inner-angle", numberNode(lt.InnerAngle))
+		setField(res, "outer-angle", numberNode(lt.OuterAngle))
+		setLightFields(res, lt.Colour, lt.Attenuation)
+	case rays.DirectionalLight:
+		setField(res, "type", stringNode("directional"))
+		setField(res, "direction", tupleNode(lt.Direction))
+		setLightFields(res, lt.Colour, rays.Attenuation{})
+	default:
+		return nil, fmt.Errorf("cannot export lights of type %T", light)
+	}
+
+	return res, nil
+}
+
+/*
+The full edges, derived from the cells as they are now. Loading divides them by the steps again,
+which can land a cell one float away from where it was when the product did not divide back exactly.
+*/
+func areaLightEdges(l rays.AreaLight) (coordinates.Coordinate, coordinates.Coordinate) {
+	return *l.UVec.Mul(float64(l.USteps)), *l.VVec.Mul(float64(l.VSteps))
+}
+
+func setLightFields(res *yaml.Node, intensity rays.Colour, attenuation rays.Attenuation) {
+	if intensity != colour.White() {
+		setField(res, "intensity", colourNode(intensity))
+	}
+
+	if attenuation != (rays.Attenuation{}) {
+		n := newMapping()
+		setField(n, "constant", numberNode(attenuation.Constant))
+		setField(n, "linear", numberNode(attenuation.Linear))
+		setField(n, "quadratic", numberNode(attenuation.Quadratic))
+		setField(res, "attenuation", n)
+	}
+}
+
+// ---------------------------------- Objects ----------------------------------
+func objectsNode(shapes []rays.Shape) (*yaml.Node, error) {
+	res := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, shp := range shapes {
+		n, err := objectNode(shp)
+		if err != nil {
+			return nil, err
+		}
+		res.Content = append(res.Content, n)
+	}
+	return res, nil
+}
+
+func objectNode(shp rays.Shape) (*yaml.Node, error) {
+	object_type, fields, err := shapeFields(shp)
+	if err != nil {
+		return nil, err
+	}
+
+	res := newMapping()
+	setField(res, "type", stringNode(object_type))
+
+	// groups and csg have no material of their own, their content carries it
+	if object_type != "group" && object_type != "csg" {
+		m, err := materialNode(shp.GetMaterial())
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			setField(res, "material", m)
+		}
+	}
+
+	if mt := transformNode(shp.Transformation()); mt != nil {
+		setField(res, "transform", mt)
+	}
+
+	res.Content = append(res.Content, fields.Content...)
+	return res, nil
+}
+
+// The type of shp and the fields only that type of object takes
+func shapeFields(shp rays.Shape) (string, *yaml.Node, error) {
+	res := newMapping()
+
+	switch s := shp.(type) {
+	case *rays.Sphere:
+		return shapeFields(*s)
+	case *rays.XZPlane:
+		return shapeFields(*s)
+	case *rays.Cube:
+		return shapeFields(*s)
+	case *rays.XZCylinder:
+		return shapeFields(*s)
+	case *rays.Cone:
+		return shapeFields(*s)
+	case *rays.Triangle:
+		return shapeFields(*s)
+	case *rays.SmoothTriangle:
+		return shapeFields(*s)
+	case *rays.Group:
+		return shapeFields(*s)
+	case *rays.CSG:
+		return shapeFields(*s)
+	case rays.Sphere:
+		if s.Origin != coordinates.CreatePoint(0, 0, 0) {
+			setField(res, "center", tupleNode(s.Origin))
+		}
+		if s.Radius != 1 {
+			setField(res, "radius", numberNode(s.Radius))
+		}
+		return "sphere", res, nil
+	case rays.XZPlane:
+		return "plane", res, nil
+	case rays.Cube:
+		return "cube", res, nil
+	case rays.XZCylinder:
+		setCutFields(res, s.Minimum, s.Maximum, s.Closed)
+		return "cylinder", res, nil
+	case rays.Cone:
+		setCutFields(res, s.Minimum, s.Maximum, s.Closed)
+		return "cone", res, nil
+	case rays.Triangle:
+		setField(res, "p1", tupleNode(s.P1))
+		setField(res, "p2", tupleNode(s.P2))
+		setField(res, "p3", tupleNode(s.P3))
+		return "triangle", res, nil
+	case rays.SmoothTriangle:
+		setField(res, "p1", tupleNode(s.P1))
+		setField(res, "p2", tupleNode(s.P2))
+		setField(res, "p3", tupleNode(s.P3))
+		setField(res, "n1", tupleNode(s.N1))
+		setField(res, "n2", tupleNode(s.N2))
+		setField(res, "n3", tupleNode(s.N3))
+		return "smooth-triangle", res, nil
+	case rays.Group:
+		children, err := objectsNode(s.ContainedShapes())
+		if err != nil {
+			return "", nil, err
+		}
+		setField(res, "children", children)
+		return "group", res, nil
+	case rays.CSG:
+		left, err := objectNode(s.Left())
+		if err != nil {
+			return "", nil, err
+		}
+		right, err := objectNode(s.Right())
+		if err != nil {
+			return "", nil, err
+		}
+		setField(res, "operation", stringNode(s.Operation.String()))
+		setField(res, "left", left)
+		setField(res, "right", right)
+		return "csg", res, nil
+	}
+
+	return "", nil, fmt.Errorf("cannot export shapes of type %T", shp)
+}
+
+func setCutFields(res *yaml.Node, minimum, maximum float64, closed bool) {
+	if !math.IsInf(minimum, -1) {
+		setField(res, "minimum", numberNode(minimum))
+	}
+	if !math.IsInf(maximum, 1) {
+		setField(res, "maximum", numberNode(maximum))
+	}
+	if closed {
+		setField(res, "closed", boolNode(true))
+	}
+}
+
+// ---------------------------------- Materials ----------------------------------
+
+// Only what differs from the default material is written, nil when nothing does
+func materialNode(m rays.Material) (*yaml.Node, error) {
+	res := newMapping()
+	def := rays.CreateDefaultMaterial()
+
+	switch p := m.Pattern.(type) {
+	case nil:
+		return nil, fmt.Errorf("cannot export a material without a pattern")
+	case rays.PlainPattern:
+		if p != def.Pattern {
+			setField(res, "color", colourNode(p.Colour()))
+		}
+	case *rays.PlainPattern:
+		setField(res, "color", colourNode(p.Colour()))
+	default:
+		n, err := patternNode(p)
+		if err != nil {
+			return nil, err
+		}
+		setField(res, "pattern", n)
+	}
+
+	for _, f := range []struct {
+		key      string
+		val, def float64
+	}{
+		{"ambient", m.Ambient, def.Ambient},
+		{"diffuse", m.Diffuse, def.Diffuse},
+		{"specular", m.Specular, def.Specular},
+		{"shininess", m.Shininess, def.Shininess},
+		{"reflective", m.Reflective, def.Reflective},
+		{"transparency", m.Transparency, def.Transparency},
+		{"refractive-index", m.RefractiveIndex, def.RefractiveIndex},
+	} {
+		if f.val != f.def {
+			setField(res, f.key, numberNode(f.val))
+		}
+	}
+
+	if len(res.Content) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// ---------------------------------- Patterns ----------------------------------
+
+// Accessors the exported pattern types have in common, so values and pointers are handled alike
+type plainPattern interface{ Colour() rays.Colour }
+type twoColourPattern interface {
+	Colours() (rays.Colour, rays.Colour)
+}
+type sizedPattern interface{ Size() (float64, float64) }
+type perturbedPattern interface {
+	Base() rays.Pattern
+	Amount() float64
+}
+
+func patternType(p rays.Pattern) string {
+	switch p.(type) {
+	case rays.PlainPattern, *rays.PlainPattern:
+		return "plain"
+	case rays.XStripe, *rays.XStripe:
+		return "stripe"
+	case rays.XGradient, *rays.XGradient:
+		return "gradient"
+	case rays.XZRing, *rays.XZRing:
+		return "ring"
+	case rays.Checker3D, *rays.Checker3D:
+		return "checker"
+	case rays.XZRadialGradient, *rays.XZRadialGradient:
+		return "radial-gradient"
+	case rays.UnitSphereUVChecker, *rays.UnitSphereUVChecker:
+		return "uv-checker"
+	case rays.Perturbed, *rays.Perturbed:
+		return "perturbed"
+	}
+	return ""
+}
+
+func patternNode(p rays.Pattern) (*yaml.Node, error) {
+	pattern_type := patternType(p)
+	if pattern_type == "" {
+		return nil, fmt.Errorf("cannot export patterns of type %T", p)
+	}
+
+	res := newMapping()
+	setField(res, "type", stringNode(pattern_type))
+
+	if pt, isPlain := p.(plainPattern); isPlain {
+		setField(res, "color", colourNode(pt.Colour()))
+	}
+	if pt, hasColours := p.(twoColourPattern); hasColours {
+		a, b := pt.Colours()
+		setField(res, "colors", flowNode(colourNode(a), colourNode(b)))
+	}
+	if pt, isSized := p.(sizedPattern); isSized {
+		width, height := pt.Size()
+		setField(res, "width", numberNode(width))
+		setField(res, "height", numberNode(height))
+	}
+	if pt, isPerturbed := p.(perturbedPattern); isPerturbed {
+		base, err := patternNode(pt.Base())
+		if err != nil {
+			return nil, err
+		}
+		setField(res, "pattern", base)
+		setField(res, "amount", numberNode(pt.Amount()))
+	}
+
+	if mt := transformNode(p.PatternTransformation()); mt != nil {
+		setField(res, "transform", mt)
+	}
+
+	return res, nil
+}
+
+// ---------------------------------- Transforms ----------------------------------
+
+// nil for the identity
+func transformNode(mt matrices.Matrix) *yaml.Node {
+	if mt == nil || mt.IsEqual(matrices.NewIdentityMatrix(4)) {
+		return nil
+	}
+
+	if ops, isExact := scaleTranslateOps(mt); isExact {
+		return flowNode(ops...)
+	}
+
+	values := make([]*yaml.Node, 0, 17)
+	values = append(values, stringNode("matrix"))
+	for _, row := range mt {
+		for _, v := range row {
+			values = append(values, numberNode(v))
+		}
+	}
+	return flowNode(flowNode(values...))
+}
+
+/*
+Splits mt into a scale followed by a translation. Only exact when rebuilding the matrix
+the way the loader does gives back mt bit for bit
+*/
+func scaleTranslateOps(mt matrices.Matrix) ([]*yaml.Node, bool) {
+	for i := range 4 {
+		for j := range 3 {
+			if i != j && mt[i][j] != 0 {
+				return nil, false
+			}
+		}
+	}
+	if mt[3][3] != 1 {
+		return nil, false
+	}
+
+	ops := make([]*yaml.Node, 0, 2)
+	res := matrices.NewIdentityMatrix(4)
+
+	sx, sy, sz := mt[0][0], mt[1][1], mt[2][2]
+	if sx != 1 || sy != 1 || sz != 1 {
+		if sx == sy && sy == sz {
+			ops = append(ops, flowNode(stringNode("scale"), numberNode(sx)))
+		} else {
+			ops = append(ops, flowNode(stringNode("scale"), numberNode(sx), numberNode(sy), numberNode(sz)))
+		}
+		res = matrices.PerformOrderedChainingOps(res, matrices.ScalingMatrix(sx, sy, sz))
+	}
+
+	tx, ty, tz := mt[0][3], mt[1][3], mt[2][3]
+	if tx != 0 || ty != 0 || tz != 0 {
+		ops = append(ops, flowNode(stringNode("translate"), numberNode(tx), numberNode(ty), numberNode(tz)))
+		res = matrices.PerformOrderedChainingOps(res, matrices.TranslationMatrix(tx, ty, tz))
+	}
+
+	return ops, res.IsEqual(mt)
+}
+
+// ---------------------------------- Node builders ----------------------------------
+func newMapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode}
+}
+
+func setField(mapping *yaml.Node, key string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, stringNode(key), value)
+}
+
+func stringNode(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// Shortest representation that parses back to exactly v
+func numberNode(v float64) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: strconv.FormatFloat(v, 'g', -1, 64)}
+}
+
+func uintNode(v uint64) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: strconv.FormatUint(v, 10)}
+}
+
+func boolNode(v bool) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: strconv.FormatBool(v)}
+}
+
+func flowNode(items ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Content: items}
+}
+
+func tupleNode(c coordinates.Coordinate) *yaml.Node {
+	return flowNode(numberNode(c[0]), numberNode(c[1]), numberNode(c[2]))
+}
+
+func colourNode(c rays.Colour) *yaml.Node {
+	return flowNode(numberNode(c[0]), numberNode(c[1]), numberNode(c[2]))
+}
@@ -0,0 +1,180 @@
+package scene
+
+import (
+	"math"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/observe"
+	"rattata/rays"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func roundTrip(t *testing.T, s Scene) ([]byte, Scene) {
+	data, err := Marshal(s)
+	assert.Nil(t, err)
+
+	res, err := Parse(data, "testdata")
+	assert.Nil(t, err, string(data))
+
+	again, err := Marshal(res)
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), string(again))
+
+	return data, res
+}
+
+func TestMarshalShowcaseRoundTrips(t *testing.T) {
+	s, err := Load("testdata/showcase.yaml")
+	assert.Nil(t, err)
+	s.Camera.SetSampling(observe.Sampling{})
+
+	_, res := roundTrip(t, s)
+
+	assert.Equal(t, s.World.Lights(), res.World.Lights())
+	assert.True(t, s.Camera.Transform_Matrix.IsEqual(res.Camera.Transform_Matrix))
+	assert.Equal(t, observe.Render(s.Camera, s.World), observe.Render(res.Camera, res.World))
+}
+
+func TestMarshalProgrammaticWorld(t *testing.T) {
+	world := observe.NewEmptyWorld()
+
+	area := rays.NewAreaLight(coordinates.CreatePoint(-1.1, 3.7, 0.3), coordinates.CreateVector(0.7, 0, 0.1), 3, coordinates.CreateVector(0, 0.3, 0.9), 5, rays.Colour{0.9, 0.8, 0.7})
+	area.Attenuation = rays.NewAttenuation(1, 0.05, 0.01)
+	world.AddLight(area)
+	world.AddLight(rays.NewSpotLight(coordinates.CreatePoint(1, 5, -2), coordinates.CreateVector(0.3, -1, 0.7), 0.1, 0.4, rays.Colour{1, 1, 1}))
+	sun := rays.NewDirectionalLight(coordinates.CreateVector(1, -3, 2), rays.Colour{0.1, 0.2, 0.3})
+	world.AddLight(&sun)
+
+	uv := rays.NewUnitSphereUVChecker(rays.Colour{1, 0, 0}, rays.Colour{0, 0, 1}, 16, 8)
+	uv.SetPatternTransformation(matrices.GivensRotationMatrix3D(coordinates.Z, math.Pi/7))
+	sphere := rays.NewSphere(coordinates.CreatePoint(0.5, 0, 0), 2)
+	sphere.Material.Pattern = &uv
+	sphere.Material.Shininess = 50
+	sphere.SetTransformation(matrices.PerformOrderedChainingOps(matrices.NewIdentityMatrix(4),
+		matrices.GivensRotationMatrix3DLeftHanded(coordinates.Y, 0.3), matrices.TranslationMatrix(0.1, 0.2, 0.3)))
+	world.AddObject(&sphere)
+
+	gradient := rays.NewXZRadialGradient(rays.Colour{0.3, 0.3, 0.3}, rays.Colour{1, 1, 1})
+	perturbed := rays.NewPerturbedPattern(gradient, 0.15)
+	perturbed.SetPatternTransformation(matrices.ScalingMatrix(0.2, 1, 0.2))
+	plane := rays.NewPlane(coordinates.CreatePoint(0, 0, 0))
+	plane.Material.Pattern = perturbed
+	world.AddObject(&plane)
+
+	grp := rays.NewGroup()
+	tri := rays.NewSmoothTriangle(coordinates.CreatePoint(0, 1, 0), coordinates.CreatePoint(-1, 0, 0), coordinates.CreatePoint(1, 0, 0),
+		coordinates.CreateVector(0, 1, 0), coordinates.CreateVector(-1, 0, 0), coordinates.CreateVector(1, 0, 0))
+	cyl := rays.NewXZCylinder()
+	cyl.Maximum = 2
+	grp.IndoctrinateShapeToGroup(&tri)
+	grp.IndoctrinateShapeToGroup(&cyl)
+	grp.SetTransformation(matrices.ShearMatrix(0.1, 0, 0, 0, 0, 0))
+	world.AddObject(&grp)
+
+	cam := observe.CreateNewCamera(12, 9, 1.1)
+	cam.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(1, 2, -6), coordinates.CreatePoint(0, 0.5, 0), coordinates.CreateVector(0, 1, 0)))
+	cam.Aperture, cam.FocalDistance = 0.05, 6.2
+	cam.SetSampling(observe.NewJitteredSampling(2, math.MaxUint64))
+
+	s := Scene{World: world, Camera: cam}
+	_, res := roundTrip(t, s)
+
+	assert.Equal(t, area, res.World.Lights()[0])
+	assert.Equal(t, cam.Sampling, res.Camera.Sampling)
+	assert.Equal(t, cam.FocalDistance, res.Camera.FocalDistance)
+
+	objects := res.World.ListObjects()
+	assert.Equal(t, 3, len(objects))
+	loaded_sphere := objects[0].(*rays.Sphere)
+	assert.Equal(t, sphere.Origin, loaded_sphere.Origin)
+	assert.Equal(t, sphere.Radius, loaded_sphere.Radius)
+	assert.True(t, sphere.Transformation().IsEqual(loaded_sphere.Transformation()))
+	assert.True(t, uv.PatternTransformation().IsEqual(loaded_sphere.Material.Pattern.PatternTransformation()))
+	assert.IsType(t, &rays.SmoothTriangle{}, objects[2].(*rays.Group).ContainedShapes()[0])
+
+	assert.Equal(t, observe.Render(cam, world), observe.Render(res.Camera, res.World))
+}
+
+func TestMarshalWritesEditedAreaLightCells(t *testing.T) {
+	world := observe.NewEmptyWorld()
+	light := rays.NewAreaLight(coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(2, 0, 0), 4, coordinates.CreateVector(0, 0, 1), 2, rays.NewWhiteLightColour())
+	// cells edited after construction are what gets written, not the edges the light was built from
+	light.UVec, light.USteps = coordinates.CreateVector(0.25, 0, 0), 2
+	world.AddLight(light)
+
+	data, err := Marshal(Scene{World: world, Camera: observe.CreateNewCamera(10, 10, math.Pi/2)})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "uvec: [0.5, 0, 0]")
+	assert.Contains(t, string(data), "usteps: 2")
+
+	res, err := Parse(data, "")
+	assert.Nil(t, err)
+	loaded := res.World.Lights()[0].(rays.AreaLight)
+	assert.Equal(t, light.UVec, loaded.UVec)
+	assert.Equal(t, light.VVec, loaded.VVec)
+	assert.Equal(t, coordinates.CreatePoint(0.25, 0, 0.5), loaded.Origin)
+}
+
+func TestMarshalWritesReadableTransforms(t *testing.T) {
+	world := observe.NewEmptyWorld()
+
+	moved := rays.NewCube()
+	moved.SetTransformation(matrices.TranslationMatrix(1, 2.5, -3))
+	world.AddObject(&moved)
+
+	scaled := rays.NewCube()
+	scaled.SetTransformation(matrices.PerformOrderedChainingOps(matrices.NewIdentityMatrix(4), matrices.ScalingMatrix(2, 2, 2), matrices.TranslationMatrix(0, 1, 0)))
+	world.AddObject(&scaled)
+
+	rotated := rays.NewCube()
+	rotated.SetTransformation(matrices.GivensRotationMatrix3D(coordinates.X, math.Pi/2))
+	world.AddObject(&rotated)
+
+	data, err := Marshal(Scene{World: world, Camera: observe.CreateNewCamera(10, 10, math.Pi/2)})
+	assert.Nil(t, err)
+
+	doc := string(data)
+	assert.Contains(t, doc, "transform: [[translate, 1, 2.5, -3]]")
+	assert.Contains(t, doc, "transform: [[scale, 2], [translate, 0, 1, 0]]")
+	assert.Contains(t, doc, "transform: [[matrix, 1, 0, 0, 0, 0, 6.123233995736757e-17, -1, 0")
+	// defaults are left out
+	assert.NotContains(t, doc, "material")
+	assert.NotContains(t, doc, "sampling")
+}
+
+type unknownPattern struct {
+	rays.PlainPattern
+}
+
+func TestMarshalRejectsWhatItCannotWrite(t *testing.T) {
+	world := observe.NewEmptyWorld()
+	cube := rays.NewCube()
+	cube.Material.Pattern = unknownPattern{}
+	world.AddObject(&cube)
+
+	_, err := Marshal(Scene{World: world, Camera: observe.CreateNewCamera(10, 10, math.Pi/2)})
+	assert.ErrorContains(t, err, "cannot export patterns of type scene.unknownPattern")
+}
+
+func TestParseCameraTransformAndProjection(t *testing.T) {
+	doc := `
+camera:
+  width: 10
+  height: 5
+  field-of-view: pi/2
+  transform: [[matrix, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, -5, 0, 0, 0, 1]]
+  projection:
+    type: orthographic
+    view-width: 4
+`
+	s, err := Parse([]byte(doc), "")
+	assert.Nil(t, err)
+	assert.Equal(t, matrices.TranslationMatrix(0, 0, -5), s.Camera.Transform_Matrix)
+	assert.Equal(t, observe.NewOrthographicProjection(4), s.Camera.Projection)
+
+	conflicting := strings.Replace(doc, "  projection:", "  from: [0, 0, 1]\n  projection:", 1)
+	assertSceneErrors(t, conflicting, SceneError{Line: 7, Reason: "a camera takes either a transform or from / to / up, not both"})
+}
@@ -0,0 +1,280 @@
+package scene
+
+import (
+	"path/filepath"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/rays"
+	"rattata/wavefront"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ---------------------------------- Objects ----------------------------------
+var objectFields = []string{"type", "material", "transform", "center", "radius", "minimum", "maximum", "closed", "p1", "p2", "p3", "n1", "n2", "n3", "children", "operation", "left", "right", "file"}
+
+// Shapes as built by the loader: pointers, so that they can be put in groups
+type groupableShape interface {
+	rays.Shape
+	rays.IsGroupable
+	SetTransformation(mt matrices.Matrix)
+}
+
+/*
+	type: sphere | plane | cube | cylinder | cone | triangle | smooth-triangle | group | csg | obj
+
+Every object takes a material and a transform. Groups, CSG operands and OBJ models pass their material down
+to whatever inside of them has none of its own. Cylinders and cones can be cut with minimum / maximum and closed.
+Spheres default to the unit sphere, center and radius are only needed to reproduce spheres built otherwise.
+*/
+func (l *loader) object(n *yaml.Node, inherited *rays.Material) (groupableShape, bool) {
+	fields, isOk := l.mapping(n, "object", objectFields...)
+	if !isOk {
+		return nil, false
+	}
+	errs := len(l.errs)
+
+	type_node, isPresent := l.required(fields, n, "object", "type")
+	if !isPresent {
+		return nil, false
+	}
+	object_type, _ := l.scalar(type_node, "object type")
+
+	m := inherited
+	if material_node, isPresent := fields["material"]; isPresent {
+		if own, isValid := l.material(material_node); isValid {
+			m = &own
+		}
+	}
+
+	var res groupableShape
+	switch object_type {
+	case "sphere":
+		sphere := rays.NewCenteredSphere()
+		if center_node, isPresent := fields["center"]; isPresent {
+			sphere.Origin, _ = l.point(center_node, "center")
+		}
+		l.optionalFloat(fields, "radius", &sphere.Radius)
+		res = &sphere
+	case "plane":
+		plane := rays.NewPlane(coordinates.CreatePoint(0, 0, 0))
+		res = &plane
+	case "cube":
+		cube := rays.NewCube()
+		res = &cube
+	case "cylinder":
+		cyl := rays.NewXZCylinder()
+		l.optionalFloat(fields, "minimum", &cyl.Minimum)
+		l.optionalFloat(fields, "maximum", &cyl.Maximum)
+		l.optionalBool(fields, "closed", &cyl.Closed)
+		res = &cyl
+	case "cone":
+		cone := rays.NewDoubleNappedCone()
+		l.optionalFloat(fields, "minimum", &cone.Minimum)
+		l.optionalFloat(fields, "maximum", &cone.Maximum)
+		l.optionalBool(fields, "closed", &cone.Closed)
+		res = &cone
+	case "triangle", "smooth-triangle":
+		var points [3]coordinates.Coordinate
+		for i, key := range []string{"p1", "p2", "p3"} {
+			if point_node, isPresent := l.required(fields, n, object_type, key); isPresent {
+				points[i], _ = l.point(point_node, key)
+			}
+		}
+		var normals [3]coordinates.Coordinate
+		if object_type == "smooth-triangle" {
+			for i, key := range []string{"n1", "n2", "n3"} {
+				if normal_node, isPresent := l.required(fields, n, object_type, key); isPresent {
+					normals[i], _ = l.vector(normal_node, key)
+				}
+			}
+		}
+		if len(l.errs) > errs {
+			return nil, false
+		}
+		// points on one line span no plane, the triangle would have no normal
+		if e1, e2 := points[1].Sub(&points[0]), points[2].Sub(&points[0]); e1.CrossP(e2).Magnitude() == 0 {
+			l.fail(n, "%s points must not lie on one line", object_type)
+			return nil, false
+		}
+		if object_type == "smooth-triangle" {
+			tri := rays.NewSmoothTriangle(points[0], points[1], points[2], normals[0], normals[1], normals[2])
+			res = &tri
+		} else {
+			tri := rays.NewTriangle(points[0], points[1], points[2])
+			res = &tri
+		}
+	case "group":
+		res = l.group(n, fields, m)
+	case "csg":
+		res = l.csg(n, fields, m)
+	case "obj":
+		res = l.obj(n, fields, m)
+	default:
+		l.fail(type_node, "unknown object type %q", object_type)
+		return nil, false
+	}
+
+	l.checkFieldsApply(fields, object_type)
+
+	mt := matrices.NewIdentityMatrix(4)
+	if transform_node, isPresent := fields["transform"]; isPresent {
+		mt, _ = l.transform(transform_node)
+	}
+
+	if res == nil || len(l.errs) > errs {
+		return nil, false
+	}
+
+	// groups and csg handed the material down while building their content
+	if m != nil && object_type != "group" && object_type != "csg" {
+		applyMaterial(res, *m)
+	}
+	res.SetTransformation(mt)
+
+	return res, true
+}
+
+// Fields that only make sense for some object types are rejected elsewhere, so typos do not go unnoticed
+func (l *loader) checkFieldsApply(fields map[string]*yaml.Node, object_type string) {
+	allowed := map[string][]string{
+		"center":    {"sphere"},
+		"radius":    {"sphere"},
+		"minimum":   {"cylinder", "cone"},
+		"maximum":   {"cylinder", "cone"},
+		"closed":    {"cylinder", "cone"},
+		"p1":        {"triangle", "smooth-triangle"},
+		"p2":        {"triangle", "smooth-triangle"},
+		"p3":        {"triangle", "smooth-triangle"},
+		"n1":        {"smooth-triangle"},
+		"n2":        {"smooth-triangle"},
+		"n3":        {"smooth-triangle"},
+		"children":  {"group"},
+		"operation": {"csg"},
+		"left":      {"csg"},
+		"right":     {"csg"},
+		"file":      {"obj"},
+	}
+
+	for _, key := range objectFields {
+		types, isRestricted := allowed[key]
+		field_node, isPresent := fields[key]
+		if !isRestricted || !isPresent {
+			continue
+		}
+
+		applies := false
+		for _, t := range types {
+			applies = applies || t == object_type
+		}
+		if !applies {
+			l.fail(field_node, "%q does not apply to %s objects", key, object_type)
+		}
+	}
+}
+
+func (l *loader) group(n *yaml.Node, fields map[string]*yaml.Node, m *rays.Material) groupableShape {
+	grp := rays.NewGroup()
+
+	children_node, isPresent := l.required(fields, n, "group", "children")
+	if !isPresent {
+		return nil
+	}
+
+	children, _ := l.sequence(children_node, "children")
+	for _, child_node := range children {
+		if child, isOk := l.object(child_node, m); isOk {
+			grp.IndoctrinateShapeToGroup(child)
+		}
+	}
+
+	return &grp
+}
+
+func (l *loader) csg(n *yaml.Node, fields map[string]*yaml.Node, m *rays.Material) groupableShape {
+	op := rays.CSGUnion
+	if op_node, isPresent := l.required(fields, n, "csg", "operation"); isPresent {
+		op_name, _ := l.scalar(op_node, "operation")
+		switch op_name {
+		case "union":
+		case "intersection":
+			op = rays.CSGIntersection
+		case "difference":
+			op = rays.CSGDifference
+		default:
+			l.fail(op_node, "unknown csg operation %q, expected union, intersection or difference", op_name)
+		}
+	}
+
+	var operands [2]groupableShape
+	for i, key := range []string{"left", "right"} {
+		if operand_node, isPresent := l.required(fields, n, "csg", key); isPresent {
+			operands[i], _ = l.object(operand_node, m)
+		}
+	}
+	if operands[0] == nil || operands[1] == nil {
+		return nil
+	}
+
+	res := rays.NewCSG(op, operands[0], operands[1])
+	return &res
+}
+
+func (l *loader) obj(n *yaml.Node, fields map[string]*yaml.Node, m *rays.Material) groupableShape {
+	file_node, isPresent := l.required(fields, n, "obj", "file")
+	if !isPresent {
+		return nil
+	}
+
+	path, isOk := l.scalar(file_node, "file")
+	if !isOk {
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.dir, path)
+	}
+
+	data, err := wavefront.ParseObjFile(path)
+	if err != nil {
+		l.fail(file_node, "cannot load %s: %v", path, err)
+		return nil
+	}
+
+	for _, warning := range data.Warnings {
+		l.warn(file_node, "%s: %s", path, warning)
+	}
+
+	return data.ToGroup()
+}
+
+func (l *loader) optionalBool(fields map[string]*yaml.Node, key string, dst *bool) {
+	if n, isPresent := fields[key]; isPresent {
+		if v, isOk := l.bool(n, key); isOk {
+			*dst = v
+		}
+	}
+}
+
+// Sets m on shp, or on every shape inside it for groups such as the ones of OBJ models
+func applyMaterial(shp rays.Shape, m rays.Material) {
+	switch s := shp.(type) {
+	case *rays.Sphere:
+		s.Material = m
+	case *rays.XZPlane:
+		s.Material = m
+	case *rays.Cube:
+		s.Material = m
+	case *rays.XZCylinder:
+		s.Material = m
+	case *rays.Cone:
+		s.Material = m
+	case *rays.Triangle:
+		s.Material = m
+	case *rays.SmoothTriangle:
+		s.Material = m
+	case *rays.Group:
+		for _, child := range s.ContainedShapes() {
+			applyMaterial(child, m)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package scene
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"rattata/matrices"
+	"rattata/observe"
+	"rattata/rays"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+/*
+A scene read from a YAML document, ready to render.
+
+	camera:        # required: width, height, field-of-view; optional: from, to, up or transform, aperture, focal-distance, sampling, projection
+	lights:        # list of point, area, spot and directional lights
+	transforms:    # named transform lists
+	patterns:      # named patterns
+	materials:     # named materials, a material may extend another one
+	objects:       # list of shapes, groups, csg and obj files
+
+Named definitions can be used anywhere their kind is expected, and may refer to each other in any order.
+See the files in testdata for complete examples. Marshal writes a scene back out in the same format.
+*/
+type Scene struct {
+	World  observe.World
+	Camera observe.Camera
+
+	// Problems that did not stop the scene from loading, e.g. lines of an OBJ file that were skipped
+	Warnings []SceneError
+}
+
+type SceneError struct {
+	Line   int
+	Column int
+	Reason string
+}
+
+func (se SceneError) Error() string {
+	return fmt.Sprintf("line %d: %s", se.Line, se.Reason)
+}
+
+/*
+Loads the scene at path. Files referenced by the scene (OBJ models) are looked up relative to it.
+*/
+func Load(path string) (Scene, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scene{}, err
+	}
+
+	return Parse(data, filepath.Dir(path))
+}
+
+/*
+Parses a scene document; dir is where relative file references are resolved from.
+
+Every problem found is reported, each as a SceneError carrying the line it was found on, joined into the returned error.
+*/
+func Parse(data []byte, dir string) (Scene, error) {
+	var doc yaml.Node
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&doc); err != nil {
+		return Scene{}, err
+	}
+
+	l := newLoader(dir)
+	res := l.loadScene(&doc)
+
+	if len(l.errs) > 0 {
+		errs := make([]error, len(l.errs))
+		for i := range l.errs {
+			errs[i] = l.errs[i]
+		}
+		return Scene{}, errors.Join(errs...)
+	}
+
+	res.Warnings = l.warnings
+	return res, nil
+}
+
+// ---------------------------------- Loader ----------------------------------
+type loader struct {
+	dir      string
+	errs     []SceneError
+	warnings []SceneError
+
+	transforms *definitions[matrices.Matrix]
+	patterns   *definitions[rays.Pattern]
+	materials  *definitions[rays.Material]
+}
+
+func newLoader(dir string) *loader {
+	l := &loader{dir: dir}
+	l.transforms = newDefinitions("transform", l.buildTransform)
+	l.patterns = newDefinitions("pattern", l.buildPattern)
+	l.materials = newDefinitions("material", l.buildMaterial)
+	return l
+}
+
+func (l *loader) loadScene(doc *yaml.Node) Scene {
+	if len(doc.Content) == 0 {
+		l.fail(doc, "the scene is empty")
+		return Scene{}
+	}
+
+	top, isOk := l.mapping(doc.Content[0], "scene", "camera", "lights", "transforms", "patterns", "materials", "objects")
+	if !isOk {
+		return Scene{}
+	}
+
+	l.transforms.collect(l, top["transforms"])
+	l.patterns.collect(l, top["patterns"])
+	l.materials.collect(l, top["materials"])
+
+	// build every definition, so that mistakes in unused ones get reported as well
+	l.transforms.buildAll(l)
+	l.patterns.buildAll(l)
+	l.materials.buildAll(l)
+
+	res := Scene{World: observe.NewEmptyWorld()}
+
+	if cam_node, isPresent := top["camera"]; isPresent {
+		res.Camera = l.camera(cam_node)
+	} else {
+		l.fail(doc.Content[0], "missing camera")
+	}
+
+	if lights_node, isPresent := top["lights"]; isPresent {
+		lights, _ := l.sequence(lights_node, "lights")
+		for _, n := range lights {
+			if light, isOk := l.light(n); isOk {
+				res.World.AddLight(light)
+			}
+		}
+	}
+
+	if objects_node, isPresent := top["objects"]; isPresent {
+		objects, _ := l.sequence(objects_node, "objects")
+		for _, n := range objects {
+			if obj, isOk := l.object(n, nil); isOk {
+				res.World.AddObject(obj)
+			}
+		}
+	}
+
+	return res
+}
+
+func (l *loader) fail(n *yaml.Node, format string, args ...any) {
+	l.errs = append(l.errs, SceneError{Line: n.Line, Column: n.Column, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (l *loader) warn(n *yaml.Node, format string, args ...any) {
+	l.warnings = append(l.warnings, SceneError{Line: n.Line, Column: n.Column, Reason: fmt.Sprintf(format, args...)})
+}
+
+// ---------------------------------- Definitions ----------------------------------
+
+/*
+Named definitions of one kind. They are built on first use, so they can refer to each other regardless of their order,
+and reference cycles are reported instead of recursing forever.
+*/
+type definitions[T any] struct {
+	kind     string
+	nodes    map[string]*yaml.Node
+	order    []string
+	built    map[string]T
+	failed   map[string]bool
+	building map[string]bool
+	build    func(n *yaml.Node) (T, bool)
+}
+
+func newDefinitions[T any](kind string, build func(n *yaml.Node) (T, bool)) *definitions[T] {
+	return &definitions[T]{kind: kind, nodes: make(map[string]*yaml.Node), built: make(map[string]T),
+		failed: make(map[string]bool), building: make(map[string]bool), build: build}
+}
+
+func (d *definitions[T]) collect(l *loader, n *yaml.Node) {
+	if n == nil {
+		return
+	}
+	n = resolve(n)
+	if n.Kind != yaml.MappingNode {
+		l.fail(n, "%ss must be a mapping of names to definitions", d.kind)
+		return
+	}
+
+	for i := 0; i < len(n.Content); i += 2 {
+		name := n.Content[i].Value
+		if _, isPresent := d.nodes[name]; isPresent {
+			l.fail(n.Content[i], "%s %q is defined twice", d.kind, name)
+			continue
+		}
+		d.nodes[name] = n.Content[i+1]
+		d.order = append(d.order, name)
+	}
+}
+
+func (d *definitions[T]) buildAll(l *loader) {
+	for _, name := range d.order {
+		d.get(l, d.nodes[name], name)
+	}
+}
+
+// ref is the node the name was read from, errors about the reference itself point there
+func (d *definitions[T]) get(l *loader, ref *yaml.Node, name string) (T, bool) {
+	var zero T
+
+	if v, isBuilt := d.built[name]; isBuilt {
+		return v, true
+	}
+	if d.failed[name] {
+		return zero, false
+	}
+
+	n, isPresent := d.nodes[name]
+	if !isPresent {
+		l.fail(ref, "unknown %s %q", d.kind, name)
+		return zero, false
+	}
+	if d.building[name] {
+		l.fail(ref, "%s %q is part of a reference cycle", d.kind, name)
+		return zero, false
+	}
+
+	d.building[name] = true
+	v, isOk := d.build(n)
+	delete(d.building, name)
+
+	if !isOk {
+		d.failed[name] = true
+		return zero, false
+	}
+	d.built[name] = v
+	return v, true
+}
+
+// ---------------------------------- Node helpers ----------------------------------
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+/*
+Returns the fields of a mapping node by key, reporting keys outside allowed as well as duplicates
+*/
+func (l *loader) mapping(n *yaml.Node, what string, allowed ...string) (map[string]*yaml.Node, bool) {
+	n = resolve(n)
+	if n.Kind != yaml.MappingNode {
+		l.fail(n, "%s must be a mapping", what)
+		return nil, false
+	}
+
+	res := make(map[string]*yaml.Node)
+	for i := 0; i < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+
+		if !slices.Contains(allowed, k.Value) {
+			l.fail(k, "unknown %s field %q", what, k.Value)
+			continue
+		}
+		if _, isPresent := res[k.Value]; isPresent {
+			l.fail(k, "duplicate %s field %q", what, k.Value)
+			continue
+		}
+		res[k.Value] = v
+	}
+
+	return res, true
+}
+
+func (l *loader) sequence(n *yaml.Node, what string) ([]*yaml.Node, bool) {
+	n = resolve(n)
+	if n.Kind != yaml.SequenceNode {
+		l.fail(n, "%s must be a list", what)
+		return nil, false
+	}
+	return n.Content, true
+}
+
+func (l *loader) required(fields map[string]*yaml.Node, parent *yaml.Node, what, key string) (*yaml.Node, bool) {
+	n, isPresent := fields[key]
+	if !isPresent {
+		l.fail(parent, "%s is missing %q", what, key)
+	}
+	return n, isPresent
+}
+
+func (l *loader) scalar(n *yaml.Node, what string) (string, bool) {
+	n = resolve(n)
+	if n.Kind != yaml.ScalarNode {
+		l.fail(n, "%s must be a single value", what)
+		return "", false
+	}
+	return n.Value, true
+}
+
+func (l *loader) float(n *yaml.Node, what string) (float64, bool) {
+	s, isOk := l.scalar(n, what)
+	if !isOk {
+		return 0, false
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		l.fail(n, "%s must be a number, got %q", what, s)
+		return 0, false
+	}
+	return v, true
+}
+
+func (l *loader) int(n *yaml.Node, what string, lowest int) (int, bool) {
+	s, isOk := l.scalar(n, what)
+	if !isOk {
+		return 0, false
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lowest {
+		l.fail(n, "%s must be a whole number of at least %d, got %q", what, lowest, s)
+		return 0, false
+	}
+	return v, true
+}
+
+func (l *loader) bool(n *yaml.Node, what string) (bool, bool) {
+	s, isOk := l.scalar(n, what)
+	if !isOk {
+		return false, false
+	}
+
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		l.fail(n, "%s must be true or false, got %q", what, s)
+		return false, false
+	}
+	return v, true
+}
+
+func (l *loader) floats(n *yaml.Node, what string, count int) ([]float64, bool) {
+	items, isOk := l.sequence(n, what)
+	if !isOk {
+		return nil, false
+	}
+	if len(items) != count {
+		l.fail(n, "%s must have %d values, got %d", what, count, len(items))
+		return nil, false
+	}
+
+	res := make([]float64, count)
+	for i, item := range items {
+		v, isValid := l.float(item, what)
+		res[i], isOk = v, isOk && isValid
+	}
+	return res, isOk
+}
+
+// Optional fields only overwrite dst when they are present and valid
+func (l *loader) optionalFloat(fields map[string]*yaml.Node, key string, dst *float64) {
+	if n, isPresent := fields[key]; isPresent {
+		if v, isOk := l.float(n, key); isOk {
+			*dst = v
+		}
+	}
+}
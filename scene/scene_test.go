@@ -0,0 +1,213 @@
+package scene
+
+import (
+	"errors"
+	"math"
+	"rattata/coordinates"
+	"rattata/matrices"
+	"rattata/observe"
+	"rattata/rays"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDefaultWorldRendersLikeObserve(t *testing.T) {
+	s, err := Load("testdata/default_world.yaml")
+	assert.Nil(t, err)
+
+	cam := observe.CreateNewCamera(11, 11, math.Pi/2)
+	cam.SetTransformationMatrix(matrices.View_Transform(coordinates.CreatePoint(0, 0, -5), coordinates.CreatePoint(0, 0, 0), coordinates.CreateVector(0, 1, 0)))
+
+	assert.Equal(t, cam.Hsize, s.Camera.Hsize)
+	assert.Equal(t, cam.FOV, s.Camera.FOV)
+	assert.Equal(t, observe.Render(cam, observe.NewDefaultWorld()), observe.Render(s.Camera, s.World))
+}
+
+func TestLoadShowcase(t *testing.T) {
+	s, err := Load("testdata/showcase.yaml")
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint32(40), s.Camera.Hsize)
+	assert.Equal(t, uint32(30), s.Camera.Vsize)
+	assert.InDelta(t, math.Pi/3, s.Camera.FOV, 1e-12)
+	assert.Equal(t, observe.NewJitteredSampling(2, 7), s.Camera.Sampling)
+
+	lights := s.World.Lights()
+	assert.Equal(t, 4, len(lights))
+	assert.IsType(t, rays.Light{}, lights[0])
+	area := lights[1].(rays.AreaLight)
+	assert.Equal(t, 4, area.Samples())
+	assert.True(t, area.Jitter)
+	assert.InDelta(t, 128.0/255, area.Colour[0], 1e-12)
+	spot := lights[2].(rays.SpotLight)
+	assert.InDelta(t, math.Pi/6, spot.OuterAngle, 1e-12)
+	assert.Equal(t, rays.NewAttenuation(1, 0.1, 0), spot.Attenuation)
+	assert.IsType(t, rays.DirectionalLight{}, lights[3])
+
+	objects := s.World.ListObjects()
+	assert.Equal(t, 7, len(objects))
+
+	// materials extend definitions that come later in the file
+	sphere := objects[1].(*rays.Sphere)
+	assert.Equal(t, 0.5, sphere.Material.Reflective)
+	assert.Equal(t, 0.7, sphere.Material.Diffuse)
+	assert.Equal(t, rays.Colour{1, 0.2, 1}, sphere.Material.Pattern.PatternAt(coordinates.CreatePoint(0, 0, 0)))
+	assert.Equal(t, matrices.TranslationMatrix(-0.5, 1, 0.5), sphere.Transformation())
+
+	// named transforms nest
+	cube := objects[2].(*rays.Cube)
+	expected := matrices.PerformOrderedChainingOps(matrices.NewIdentityMatrix(4), matrices.ScalingMatrix(0.33, 0.33, 0.33), matrices.TranslationMatrix(-1.5, 0.33, -0.75))
+	assert.Equal(t, expected, cube.Transformation())
+	assert.IsType(t, rays.Perturbed{}, cube.Material.Pattern)
+
+	// group members inherit the group material unless they have their own
+	grp := objects[3].(*rays.Group)
+	children := grp.ContainedShapes()
+	assert.Equal(t, 1.0, children[0].GetMaterial().Transparency)
+	assert.True(t, children[0].(*rays.XZCylinder).Closed)
+	assert.Equal(t, 0.0, children[1].GetMaterial().Transparency)
+	assert.Equal(t, 0.7, children[1].GetMaterial().Diffuse)
+
+	csg := objects[4].(*rays.CSG)
+	assert.Equal(t, rays.CSGDifference, csg.Operation)
+	assert.Equal(t, 0.5, csg.Left().GetMaterial().Reflective)
+	assert.Equal(t, 0.5, csg.Right().GetMaterial().Reflective)
+
+	assert.IsType(t, &rays.Triangle{}, objects[5])
+
+	model := objects[6].(*rays.Group)
+	assert.Equal(t, matrices.TranslationMatrix(0, 0, 3), model.Transformation())
+	triangles := 0
+	for _, sub := range model.ContainedShapes() {
+		for _, tri := range sub.(*rays.Group).ContainedShapes() {
+			assert.Equal(t, 0.7, tri.GetMaterial().Diffuse)
+			triangles++
+		}
+	}
+	assert.Equal(t, 2, triangles)
+	assert.Equal(t, 1, len(s.Warnings))
+
+	// and the whole thing renders
+	small := s.Camera
+	small.Hsize, small.Vsize = 8, 6
+	observe.Render(small, s.World)
+}
+
+func TestParseAngle(t *testing.T) {
+	cases := map[string]float64{
+		"1.5":    1.5,
+		"-2":     -2,
+		"90deg":  math.Pi / 2,
+		"-45deg": -math.Pi / 4,
+		"pi":     math.Pi,
+		"pi/2":   math.Pi / 2,
+		"-pi/4":  -math.Pi / 4,
+		"3pi/4":  3 * math.Pi / 4,
+		"2*pi":   2 * math.Pi,
+		"2 pi":   2 * math.Pi,
+	}
+	for s, expected := range cases {
+		v, isOk := parseAngle(s)
+		assert.True(t, isOk, s)
+		assert.InDelta(t, expected, v, 1e-12, s)
+	}
+
+	for _, s := range []string{"", "abc", "pi/0", "pi2", "xpi", "90degrees"} {
+		_, isOk := parseAngle(s)
+		assert.False(t, isOk, s)
+	}
+}
+
+func assertSceneErrors(t *testing.T, doc string, expected ...SceneError) {
+	_, err := Parse([]byte(doc), ".")
+	if !assert.Error(t, err) {
+		return
+	}
+
+	for _, e := range expected {
+		found := false
+		var scene_err interface{ Unwrap() []error }
+		if errors.As(err, &scene_err) {
+			for _, inner := range scene_err.Unwrap() {
+				se, isSceneError := inner.(SceneError)
+				found = found || (isSceneError && se.Line == e.Line && se.Reason == e.Reason)
+			}
+		}
+		assert.True(t, found, "expected %q in %v", e.Error(), err)
+	}
+}
+
+const validCamera = `camera:
+  width: 10
+  height: 10
+  field-of-view: 1
+`
+
+func TestParseReportsErrorsWithLines(t *testing.T) {
+	assertSceneErrors(t, "lights: []\n",
+		SceneError{Line: 1, Reason: "missing camera"})
+
+	assertSceneErrors(t, validCamera+"objects:\n  - type: sphere\n    colour: [1, 0, 0]\n  - type: teapot\n",
+		SceneError{Line: 7, Reason: `unknown object field "colour"`},
+		SceneError{Line: 8, Reason: `unknown object type "teapot"`})
+
+	assertSceneErrors(t, validCamera+"objects:\n  - type: sphere\n    material: chrome\n    transform: [[translate, 1, 2], [spin, 1]]\n",
+		SceneError{Line: 7, Reason: `unknown material "chrome"`},
+		SceneError{Line: 8, Reason: "translate takes [3] values, got 2"},
+		SceneError{Line: 8, Reason: `unknown transform operation "spin"`})
+
+	assertSceneErrors(t, validCamera+"materials:\n  a:\n    extends: b\n  b:\n    extends: a\n",
+		SceneError{Line: 9, Reason: `material "a" is part of a reference cycle`})
+
+	assertSceneErrors(t, validCamera+"lights:\n  - type: spot\n    at: [0, 1, 0]\n    inner-angle: wide\n",
+		SceneError{Line: 6, Reason: `spot light is missing "direction"`},
+		SceneError{Line: 8, Reason: `inner-angle must be an angle in radians, degrees (90deg) or a multiple of pi (pi/2), got "wide"`},
+		SceneError{Line: 6, Reason: `spot light is missing "outer-angle"`})
+
+	assertSceneErrors(t, "camera:\n  width: -3\n  height: 10\n  from: [0, 0]\n",
+		SceneError{Line: 2, Reason: `width must be a whole number of at least 1, got "-3"`},
+		SceneError{Line: 2, Reason: `camera is missing "field-of-view"`},
+		SceneError{Line: 4, Reason: "from must have 3 values, got 2"})
+
+	assertSceneErrors(t, validCamera+"patterns:\n  p:\n    type: stripe\n    colors: [[1, 0, 0]]\n  q:\n    type: plain\n    color: '#12345'\n",
+		SceneError{Line: 8, Reason: "stripe pattern takes 2 colors, got 1"},
+		SceneError{Line: 11, Reason: `color must be [r, g, b] or a hex colour: invalid hex colour "#12345": expected 3 or 6 hex digits`})
+
+	assertSceneErrors(t, validCamera+"objects:\n  - type: sphere\n    minimum: 0\n  - type: obj\n    file: missing.obj\n",
+		SceneError{Line: 7, Reason: `"minimum" does not apply to sphere objects`})
+
+	assertSceneErrors(t, validCamera+"objects:\n  - type: sphere\n    transform: [[scale, 1, 0, 1]]\npatterns:\n  p:\n    type: stripe\n    colors: [[1, 0, 0], [0, 0, 1]]\n    transform: [[scale, 0]]\n",
+		SceneError{Line: 7, Reason: "transform cannot be inverted, does it scale something to zero?"},
+		SceneError{Line: 12, Reason: "transform cannot be inverted, does it scale something to zero?"})
+
+	assertSceneErrors(t, "camera:\n  width: 10\n  height: 10\n  field-of-view: 1\n  from: [0, 5, 0]\n  to: [0, 0, 0]\n  up: [0, 1, 0]\n",
+		SceneError{Line: 2, Reason: "camera up must not point along the view direction"})
+
+	for fov, reason := range map[string]string{"0": "got 0", "-1": "got -1", "pi": "got 3.141592653589793", "4": "got 4"} {
+		assertSceneErrors(t, "camera:\n  width: 10\n  height: 10\n  field-of-view: "+fov+"\n",
+			SceneError{Line: 4, Reason: "field-of-view must lie between 0 and pi, " + reason})
+	}
+
+	assertSceneErrors(t, validCamera+"lights:\n  - type: spot\n    at: [0, 1, 0]\n    direction: [0, 0, 0]\n    inner-angle: 0.1\n    outer-angle: 0.2\n  - type: directional\n    direction: [0, 0, 0]\n",
+		SceneError{Line: 8, Reason: "spot light direction must not be zero"},
+		SceneError{Line: 12, Reason: "directional light direction must not be zero"})
+
+	assertSceneErrors(t, validCamera+"objects:\n  - type: triangle\n    p1: [0, 0, 0]\n    p2: [1, 1, 1]\n    p3: [3, 3, 3]\n  - type: smooth-triangle\n    p1: [0, 0, 0]\n    p2: [0, 0, 0]\n    p3: [0, 1, 0]\n    n1: [0, 0, 1]\n    n2: [0, 0, 1]\n    n3: [0, 0, 1]\n",
+		SceneError{Line: 6, Reason: "triangle points must not lie on one line"},
+		SceneError{Line: 10, Reason: "smooth-triangle points must not lie on one line"})
+}
+
+func TestParseRejectsInvalidYAML(t *testing.T) {
+	_, err := Parse([]byte("camera: [\n"), ".")
+	assert.ErrorContains(t, err, "yaml")
+}
+
+func TestParseSupportsAnchors(t *testing.T) {
+	doc := validCamera + "objects:\n  - &ball\n    type: sphere\n    transform: [[translate, 1, 0, 0]]\n  - *ball\n"
+
+	s, err := Parse([]byte(doc), ".")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(s.World.ListObjects()))
+	assert.NotSame(t, s.World.ListObjects()[0], s.World.ListObjects()[1])
+}
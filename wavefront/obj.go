@@ -0,0 +1,241 @@
+package wavefront
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"rattata/coordinates"
+	"rattata/rays"
+	"strconv"
+	"strings"
+)
+
+const DEFAULT_GROUP_NAME = ""
+
+type ParseWarning struct {
+	Line    int
+	Content string
+	Reason  string
+}
+
+func (pw ParseWarning) String() string {
+	return fmt.Sprintf("line %d: %s (%q)", pw.Line, pw.Reason, pw.Content)
+}
+
+type TextureCoord [3]float64
+
+/*
+Holds the tables of a parsed OBJ file.
+
+The file refers to vertices, normals and texture coordinates with 1 based indices,
+whereas the tables here are plain 0 based slices; i.e. `v 1` of a face lives in Vertices[0].
+*/
+type ObjData struct {
+	Vertices      []coordinates.Coordinate
+	Normals       []coordinates.Coordinate
+	TextureCoords []TextureCoord
+	DefaultGroup  *rays.Group
+	NamedGroups   map[string]*rays.Group
+	Warnings      []ParseWarning
+	groupOrder    []string
+}
+
+func ParseObjFile(path string) (ObjData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ObjData{}, err
+	}
+	defer file.Close()
+
+	return ParseObj(file)
+}
+
+/*
+Parses the vertex (v), normal (vn), texture (vt), face (f) and group (g / o) statements of an OBJ document.
+
+Faces with more than 3 vertices are fan triangulated; faces that carry a normal for every vertex become
+smooth triangles. Anything that cannot be understood is skipped and recorded in Warnings along with its line number,
+only a failure to read from r is returned as an error.
+*/
+func ParseObj(r io.Reader) (ObjData, error) {
+	default_group := rays.NewGroup()
+	data := ObjData{
+		Vertices:      make([]coordinates.Coordinate, 0),
+		Normals:       make([]coordinates.Coordinate, 0),
+		TextureCoords: make([]TextureCoord, 0),
+		DefaultGroup:  &default_group,
+		NamedGroups:   make(map[string]*rays.Group),
+		Warnings:      make([]ParseWarning, 0),
+		groupOrder:    make([]string, 0),
+	}
+
+	current_group := data.DefaultGroup
+	scanner := bufio.NewScanner(r)
+	line_no := 0
+
+	for scanner.Scan() {
+		line_no++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		var reason string
+
+		switch fields[0] {
+		case "v":
+			var vals []float64
+			if vals, reason = parseFloats(fields[1:], 3, 4); reason == "" {
+				data.Vertices = append(data.Vertices, coordinates.CreatePoint(vals[0], vals[1], vals[2]))
+			}
+		case "vn":
+			var vals []float64
+			if vals, reason = parseFloats(fields[1:], 3, 3); reason == "" {
+				data.Normals = append(data.Normals, coordinates.CreateVector(vals[0], vals[1], vals[2]))
+			}
+		case "vt":
+			var vals []float64
+			if vals, reason = parseFloats(fields[1:], 1, 3); reason == "" {
+				tc := TextureCoord{}
+				copy(tc[:], vals)
+				data.TextureCoords = append(data.TextureCoords, tc)
+			}
+		case "f":
+			reason = data.addFace(current_group, fields[1:])
+		case "g", "o":
+			if len(fields) < 2 {
+				reason = "group statement without a name"
+				break
+			}
+			current_group = data.namedGroup(strings.Join(fields[1:], " "))
+		default:
+			reason = fmt.Sprintf("unsupported statement %q", fields[0])
+		}
+
+		if reason != "" {
+			data.Warnings = append(data.Warnings, ParseWarning{Line: line_no, Content: line, Reason: reason})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+/*
+Returns a group holding the default group and every named group (in order of first appearance),
+ready to be added to a world.
+*/
+func (data ObjData) ToGroup() *rays.Group {
+	root := rays.NewGroup()
+	root.IndoctrinateShapeToGroup(data.DefaultGroup)
+
+	for _, name := range data.groupOrder {
+		root.IndoctrinateShapeToGroup(data.NamedGroups[name])
+	}
+
+	return &root
+}
+
+func (data *ObjData) namedGroup(name string) *rays.Group {
+	if grp, isPresent := data.NamedGroups[name]; isPresent {
+		return grp
+	}
+
+	grp := rays.NewGroup()
+	data.NamedGroups[name] = &grp
+	data.groupOrder = append(data.groupOrder, name)
+	return &grp
+}
+
+func (data *ObjData) addFace(grp *rays.Group, face_fields []string) string {
+	if len(face_fields) < 3 {
+		return "face needs at least 3 vertices"
+	}
+
+	vertices := make([]coordinates.Coordinate, len(face_fields))
+	normals := make([]coordinates.Coordinate, len(face_fields))
+	has_all_normals := true
+
+	for i, face_field := range face_fields {
+		// v, v/vt, v//vn or v/vt/vn
+		refs := strings.Split(face_field, "/")
+		if len(refs) > 3 {
+			return fmt.Sprintf("malformed face vertex %q", face_field)
+		}
+
+		v_idx, reason := resolveIndex(refs[0], len(data.Vertices))
+		if reason != "" {
+			return reason
+		}
+		vertices[i] = data.Vertices[v_idx]
+
+		if len(refs) > 1 && refs[1] != "" {
+			if _, reason = resolveIndex(refs[1], len(data.TextureCoords)); reason != "" {
+				return reason
+			}
+		}
+
+		if len(refs) == 3 && refs[2] != "" {
+			n_idx, reason := resolveIndex(refs[2], len(data.Normals))
+			if reason != "" {
+				return reason
+			}
+			normals[i] = data.Normals[n_idx]
+		} else {
+			has_all_normals = false
+		}
+	}
+
+	for i := 1; i < len(vertices)-1; i++ {
+		if has_all_normals {
+			tri := rays.NewSmoothTriangle(vertices[0], vertices[i], vertices[i+1], normals[0], normals[i], normals[i+1])
+			grp.IndoctrinateShapeToGroup(&tri)
+		} else {
+			tri := rays.NewTriangle(vertices[0], vertices[i], vertices[i+1])
+			grp.IndoctrinateShapeToGroup(&tri)
+		}
+	}
+
+	return ""
+}
+
+// Converts a 1 based (or negative, relative to the end) OBJ index into a slice index
+func resolveIndex(ref string, table_len int) (int, string) {
+	idx, err := strconv.Atoi(ref)
+	if err != nil {
+		return 0, fmt.Sprintf("malformed index %q", ref)
+	}
+
+	if idx < 0 {
+		idx = table_len + idx + 1
+	}
+
+	if idx < 1 || idx > table_len {
+		return 0, fmt.Sprintf("index %s out of range (%d entries)", ref, table_len)
+	}
+
+	return idx - 1, ""
+}
+
+func parseFloats(fields []string, min_count, max_count int) ([]float64, string) {
+	if len(fields) < min_count || len(fields) > max_count {
+		return nil, fmt.Sprintf("expected %d to %d values, found %d", min_count, max_count, len(fields))
+	}
+
+	vals := make([]float64, len(fields))
+	for i, field := range fields {
+		val, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Sprintf("malformed number %q", field)
+		}
+		vals[i] = val
+	}
+
+	return vals, ""
+}
@@ -0,0 +1,146 @@
+package wavefront
+
+import (
+	"rattata/coordinates"
+	"rattata/observe"
+	"rattata/rays"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoredLines(t *testing.T) {
+	data, err := ParseObj(strings.NewReader(`There was a young lady named Bright
+who traveled much faster than light.
+v 1 2
+
+v 1 x 0
+f 1 2 3`))
+
+	assert.Nil(t, err)
+	assert.Empty(t, data.Vertices)
+	assert.Equal(t, 5, len(data.Warnings))
+	assert.Equal(t, []int{1, 2, 3, 5, 6}, []int{data.Warnings[0].Line, data.Warnings[1].Line, data.Warnings[2].Line,
+		data.Warnings[3].Line, data.Warnings[4].Line})
+	assert.Contains(t, data.Warnings[4].Reason, "out of range")
+}
+
+func TestVertexRecords(t *testing.T) {
+	data, err := ParseObj(strings.NewReader(`v -1 1 0
+v -1.0000 0.5000 0.0000
+v 1 0 0
+v 1 1 0
+# a comment
+vn 0 0 1
+vn 0.707 0 -0.707
+vt 0.5 0.25`))
+
+	assert.Nil(t, err)
+	assert.Empty(t, data.Warnings)
+	assert.Equal(t, []coordinates.Coordinate{
+		coordinates.CreatePoint(-1, 1, 0),
+		coordinates.CreatePoint(-1, 0.5, 0),
+		coordinates.CreatePoint(1, 0, 0),
+		coordinates.CreatePoint(1, 1, 0),
+	}, data.Vertices)
+	assert.Equal(t, []coordinates.Coordinate{
+		coordinates.CreateVector(0, 0, 1),
+		coordinates.CreateVector(0.707, 0, -0.707),
+	}, data.Normals)
+	assert.Equal(t, []TextureCoord{{0.5, 0.25, 0}}, data.TextureCoords)
+}
+
+func TestTriangleFaces(t *testing.T) {
+	data, _ := ParseObj(strings.NewReader(`v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+
+f 1 2 3
+f 1 3 4`))
+
+	children := data.DefaultGroup.ContainedShapes()
+	assert.Equal(t, 2, len(children))
+
+	t1 := children[0].(*rays.Triangle)
+	t2 := children[1].(*rays.Triangle)
+	assert.Equal(t, data.Vertices[0], t1.P1)
+	assert.Equal(t, data.Vertices[1], t1.P2)
+	assert.Equal(t, data.Vertices[2], t1.P3)
+	assert.Equal(t, data.Vertices[0], t2.P1)
+	assert.Equal(t, data.Vertices[2], t2.P2)
+	assert.Equal(t, data.Vertices[3], t2.P3)
+	assert.Equal(t, data.DefaultGroup, t1.Parent())
+}
+
+func TestPolygonFanTriangulation(t *testing.T) {
+	data, _ := ParseObj(strings.NewReader(`v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+v 0 2 0
+
+f 1 2 3 4 5`))
+
+	children := data.DefaultGroup.ContainedShapes()
+	assert.Equal(t, 3, len(children))
+
+	t3 := children[2].(*rays.Triangle)
+	assert.Equal(t, data.Vertices[0], t3.P1)
+	assert.Equal(t, data.Vertices[3], t3.P2)
+	assert.Equal(t, data.Vertices[4], t3.P3)
+}
+
+func TestNamedGroups(t *testing.T) {
+	data, _ := ParseObj(strings.NewReader(`v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+g FirstGroup
+f 1 2 3
+o SecondGroup
+f 1 3 4
+g FirstGroup
+f -1 -2 -3`))
+
+	assert.Empty(t, data.Warnings)
+	assert.Empty(t, data.DefaultGroup.ContainedShapes())
+	assert.Equal(t, 2, len(data.NamedGroups["FirstGroup"].ContainedShapes()))
+	assert.Equal(t, 1, len(data.NamedGroups["SecondGroup"].ContainedShapes()))
+
+	root := data.ToGroup()
+	assert.Equal(t, 3, len(root.ContainedShapes()))
+	assert.Equal(t, root, data.NamedGroups["FirstGroup"].Parent())
+
+	w := observe.NewEmptyWorld()
+	w.AddObject(root)
+	xs := w.IntersectWithRay(rays.NewRay(coordinates.CreatePoint(0.5, 0.5, -5), coordinates.CreateVector(0, 0, 1)))
+	assert.Equal(t, 2, len(xs))
+}
+
+func TestFacesWithNormals(t *testing.T) {
+	data, _ := ParseObj(strings.NewReader(`v 0 1 0
+v -1 0 0
+v 1 0 0
+vt 0 0
+vn -1 0 0
+vn 1 0 0
+vn 0 1 0
+f 1//3 2//1 3//2
+f 1/1/3 2/1/1 3/1/2
+f 1/1 2/1 3/1`))
+
+	assert.Empty(t, data.Warnings)
+	children := data.DefaultGroup.ContainedShapes()
+	assert.Equal(t, 3, len(children))
+
+	for _, child := range children[0:2] {
+		st := child.(*rays.SmoothTriangle)
+		assert.Equal(t, data.Vertices[0], st.P1)
+		assert.Equal(t, data.Normals[2], st.N1)
+		assert.Equal(t, data.Normals[0], st.N2)
+		assert.Equal(t, data.Normals[1], st.N3)
+	}
+	assert.Equal(t, "Triangle", children[2].Name())
+}